@@ -3,16 +3,98 @@ package adstxt
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Get crawl and parse Ads.txt file from remote host based on Ads.txt Specification Version 1.0.1
 // https://iabtechlab.com/wp-content/uploads/2017/09/IABOpenRTB_Ads.txt_Public_Spec_V1-0-1.pdf
 func Get(req *Request) (*Response, error) {
-	c := newCrawler()
+	return NewCrawler().Get(req)
+}
+
+// GetMultiple crawl and parse multiple Ads.txt files from remote hosts based on Ads.txt Specification Version 1.0.1
+// https://iabtechlab.com/wp-content/uploads/2017/09/IABOpenRTB_Ads.txt_Public_Spec_V1-0-1.pdf
+// Requests share single crawler using the bulk connection budget
+func GetMultiple(req []*Request, h Handler) {
+	c := NewCrawler(WithConnectionBudget(BulkBudget))
+	defer c.CloseIdleConnections()
+
+	c.GetMultiple(req, h)
+}
+
+// Get crawl and parse Ads.txt file from remote host using crawler. Origin errors (5xx) are retried with
+// backoff when crawler retries are set (see WithRetry). Crawler transformers are applied to the final response
+func (c *Crawler) Get(req *Request) (res *Response, err error) {
+	atomic.AddInt64(&c.metrics.inFlight, 1)
+	defer atomic.AddInt64(&c.metrics.inFlight, -1)
+
+	if len(c.path) > 0 {
+		req.defaultPath(c.path)
+	}
+
+	// health ledger record the final result of all attempts
+	if c.health != nil {
+		start := time.Now()
+		defer func() { c.observeHealth(req, err, time.Since(start)) }()
+	}
+
+	// differential crawl baseline, shared by all attempts (and raced fetches)
+	if c.differential != nil {
+		req.baseline = c.baselineSnapshot(req)
+		defer func() { req.baseline = nil }()
+	}
+
+	// failed attempts history of dead-lettered requests, request URL may change on redirects
+	attempts := []*FailedAttempt{}
+	rawurl := req.URL
+
+	for attempt := 0; ; attempt++ {
+		if c.race {
+			res, err = c.raceGet(req)
+		} else {
+			res, err = c.get(req)
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			statusErr.Attempts = attempt + 1
+		}
+		if err != nil && c.deadLetters != nil {
+			attempts = append(attempts, newFailedAttempt(err))
+		}
+
+		if !Retryable(err) || attempt >= c.retries || !wait(req, c.retryDelay(err, attempt)) {
+			if err != nil && c.deadLetters != nil {
+				c.deadLetter(req, rawurl, attempts, err)
+			}
+			if err != nil || len(c.transformers) == 0 {
+				return res, err
+			}
+			return Transform(res, c.transformers...)
+		}
+	}
+}
+
+// get crawl and parse Ads.txt file from request URL, following redirects
+func (c *Crawler) get(req *Request) (*Response, error) {
+	// warnings about remote host HTTP response headers, added to the parsed Ads.txt records
+	warnings := []*Warning{}
+	// redirects handled while fetching Ads.txt file
+	redirects := []*RedirectEvent{}
+	// number of redirects followed by the fetch
+	hops := 0
+	// two-step cookie session was started (see WithCookieJar)
+	session := false
+	// timing breakdown of HTTP requests sent while fetching Ads.txt file
+	timings := []*Timing{}
 
 	// send Ads.txt request to remote server and parse response
 	for {
@@ -20,102 +102,387 @@ func Get(req *Request) (*Response, error) {
 		if err != nil {
 			return nil, err
 		}
-		defer res.Body.Close()
+
+		timer := responseTimer(res)
+		if timer != nil {
+			timings = append(timings, timer.result)
+		}
+
+		// origin set cookies before serving content, request the same URL again with the session cookies
+		if c.startSession(req, res, session) {
+			session = true
+			drainBody(res)
+			continue
+		}
 
 		// handle Ads.txt response
 		switch {
+		// the file was not modified since the differential crawl baseline snapshot
+		case res.StatusCode == http.StatusNotModified && req.baseline != nil:
+			c.emit(req, &Event{Type: EventUnchanged})
+			drainBody(res)
+			return c.unchangedResponse(req, res, redirects, timings), nil
 		// the server response indicates redirect (301, 302, 307 status codes), follow redirect and read Ads.txt
 		// file from the source of the redirect
 		case 300 <= res.StatusCode && res.StatusCode < 400:
-			redirect, err := c.handleRedirect(req, res)
+			// redirect body is discarded before following the redirect, so the connection can be reused
+			drainBody(res)
+			redirect, w, err := c.handleRedirect(req, res)
+			// Return error when the number of redirects of the fetch reach a max (scheme upgrades are not counted)
+			if err == nil && !isSchemeUpgrade(req.URL, redirect) {
+				if hops++; hops > maxNumRedirects {
+					err = fmt.Errorf(errInfiniteRedirect, req.URL, redirect)
+				}
+			}
+			redirects = append(redirects, newRedirectEvent(req, res, redirect, w, err))
+			c.emit(req, &Event{Type: EventRedirect, Redirect: redirects[len(redirects)-1]})
 			if err != nil {
-				return nil, err
+				return nil, &RedirectError{Redirects: redirects, Err: err}
 			}
+			warnings = append(warnings, w...)
 			req.URL = redirect
 		// client error in remote server response
 		case 400 <= res.StatusCode && res.StatusCode < 500:
-			return nil, fmt.Errorf(errHTTPClientError, res.Status, req.Domain, req.URL)
+			drainBody(res)
+			return nil, newStatusError(req, res)
 		// the server response indicates Success (HTTP Status Code 200): read and parse the content of the Ads.txt file
 		case res.StatusCode == 200:
-			body, err := c.readBody(req, res)
+			defer res.Body.Close()
+			lenient, w, err := c.checkContentType(req, res)
 			if err != nil {
 				return nil, err
 			}
-
-			// return new resposne
-			records, err := ParseBody(body)
-			if err != nil {
-				return nil, err
+			if w != nil {
+				warnings = append(warnings, w)
 			}
 
-			// Ads.txt response
-			r := &Response{
-				Request: req,
-				Records: records,
-				// Ads.txt file default expiration date is set to 7 days (secion 3.6 EXPIRATION of IAB Ads.txt specification)
-				Expires: time.Now().UTC().AddDate(0, 0, 7),
+			// read and parse Ads.txt file, when early abort is set parsing is done while the file is downloaded
+			var records *Records
+			if c.abortAfter > 0 && len(c.preprocessors) == 0 && req.baseline == nil {
+				body := limitBody(res.Body)
+				records, err = parseReader(body, c.abortAfter)
+				if err != nil {
+					return nil, fmt.Errorf(errHTTPFetchAborted, req.URL, err.Error())
+				}
+				if body.N == 0 {
+					return nil, fmt.Errorf(errHTTPFileTooLarge, req.URL, MaxFileSize)
+				}
+			} else {
+				body, err := c.readBody(req, res)
+				if err != nil {
+					return nil, err
+				}
+
+				// transform body before parsing
+				body, applied := Preprocess(body, c.preprocessors...)
+
+				// short-circuit parsing of file which didn't change since the differential crawl baseline
+				if req.baseline != nil && req.baseline.unchanged(body) {
+					c.emit(req, &Event{Type: EventUnchanged})
+					timer.finish()
+					return c.unchangedResponse(req, res, redirects, timings), nil
+				}
+
+				// return new resposne
+				records, err = parseReader(bytes.NewReader(body), c.abortAfter)
+				if err != nil && c.abortAfter > 0 {
+					return nil, fmt.Errorf(errHTTPFetchAborted, req.URL, err.Error())
+				}
+				if err != nil {
+					return nil, err
+				}
+				if len(applied) > 0 {
+					records.Preprocessed = applied
+				}
 			}
 
+			timer.finish()
+
 			// parse Ads.txt expiration date from response (else default expiration time is used)
-			expires, err := c.parseExpires(res)
-			if err == nil {
-				r.Expires = expires
+			expires, w := c.expiration(res, time.Now())
+			if w != nil {
+				warnings = append(warnings, w)
 			}
 
-			return r, nil
-		// un known HTTP status
+			// HTTP response warnings are not related to any Ads.txt line, set them before Ads.txt lines warnings
+			records.Warnings = append(warnings, records.Warnings...)
+
+			// flag records with fetch related quality issues, so consumers can weight them by trust
+			if d, _ := RootDomain(req.URL); Domain(d) != req.Domain && !req.local {
+				records.addFlag(FlagCrossDomainRedirect)
+			}
+			if lenient {
+				records.addFlag(FlagLenientContentType)
+			}
+			for _, r := range redirects {
+				if r.Decision == RedirectFollowedLenient {
+					records.addFlag(FlagLenientRedirectPath)
+					break
+				}
+			}
+
+			// invisible characters in account IDs break exact match joins, trim them in lenient mode
+			if req.Lenient {
+				records.trimAccountIDs()
+			}
+			if c.profile != nil {
+				records.validate(*c.profile)
+			}
+			c.emitParsed(req, records)
+
+			// Ads.txt response
+			response := &Response{Request: req, Records: records, Expires: expires, Headers: c.captureHeaders(res), Redirects: redirects, Timings: timings}
+			response.setValidators(res)
+			if c.security && !req.local {
+				response.Security = c.securityReport(req, res)
+			}
+			if c.scoring != nil {
+				response.Score = NewScore(records, *c.scoring)
+			}
+			return response, nil
+		// origin error (5xx) or un known HTTP status
 		default:
-			return nil, fmt.Errorf(errHTTPGeneralError, res.Status, req.Domain, req.URL)
+			drainBody(res)
+			return nil, newStatusError(req, res)
 		}
 	}
 }
 
-// GetMultiple crawl and parse multiple Ads.txt files from remote hosts based on Ads.txt Specification Version 1.0.1
-// https://iabtechlab.com/wp-content/uploads/2017/09/IABOpenRTB_Ads.txt_Public_Spec_V1-0-1.pdf
-func GetMultiple(req []*Request, h Handler) {
+// GetMultiple crawl and parse multiple Ads.txt files from remote hosts using crawler
+func (c *Crawler) GetMultiple(req []*Request, h Handler) {
+	c.GetMultipleContext(context.Background(), req, h)
+}
+
+// CrawlSummary accounting of GetMultipleContext crawl. When context is done before all requests are crawled,
+// summary lists the requests that were interrupted and those which were not attempted at all
+type CrawlSummary struct {
+	Completed    int        // Completed number of requests crawled and reported to handler (successfully or not)
+	InFlight     []*Request // InFlight requests interrupted by context while crawled (reported to handler with context error)
+	NotAttempted []*Request // NotAttempted requests not started before context was done (not reported to handler)
+	Err          error      // Err context error, nil when all requests were crawled
+}
+
+// GetMultipleContext crawl and parse multiple Ads.txt files using crawler until context is done, and return summary
+// of the crawl. Results collected before context is done are reported to handler as usual. Each request is crawled
+// using its copy, which is reported to handler (e.g. with the URL updated on redirects), so caller requests are not
+// modified and may be listed more than once. Summary lists the caller requests
+func (c *Crawler) GetMultipleContext(ctx context.Context, req []*Request, h Handler) *CrawlSummary {
+	return c.getMultiple(ctx, req, func(_ *Request, crawled *Request, res *Response, err error) {
+		h.Handle(crawled, res, err)
+	})
+}
+
+// getMultiple crawl multiple Ads.txt files until context is done (see GetMultipleContext), handle is called with
+// both the caller request and its crawled copy
+func (c *Crawler) getMultiple(ctx context.Context, req []*Request, handle func(r *Request, crawled *Request, res *Response, err error)) *CrawlSummary {
+	summary := &CrawlSummary{InFlight: []*Request{}, NotAttempted: []*Request{}}
+	var lock sync.Mutex
+
+	// drop requests of unresolvable hosts before crawling starts
+	if c.resolveConcurrency > 0 {
+		resolved, notAttempted := c.preResolve(ctx, req, HandlerFunc(func(r *Request, res *Response, err error) {
+			handle(r, r, res, err)
+		}))
+		summary.Completed = len(req) - len(resolved) - len(notAttempted)
+		summary.NotAttempted = append(summary.NotAttempted, notAttempted...)
+		req = resolved
+	}
+
 	// For faster crawling, use new goroutine for each request and set waitgroup to wait for all goroutine to finish
 	var wg sync.WaitGroup
-	wg.Add(len(req))
 
 	// For a long list of requests, start a new goroutine for each request may allocate more memory than is available on the machine.
 	// To void it, set a limit on the number of requests we handle in parallel
-	guard := make(chan struct{}, runtime.NumCPU()*5)
+	concurrency := c.concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU() * 5
+	}
+	guard := make(chan struct{}, concurrency)
+
+	// requests are queued until crawled (or not attempted)
+	atomic.AddInt64(&c.metrics.queued, int64(len(req)))
 
 	// buffer of channels to handle response
-	for _, r := range req {
+	for index, r := range req {
 		// block if guard channel is already filled, to avoid "too many" parallel requests at the same time
-		guard <- struct{}{}
+		// (or until the tuner admits another request)
+		switch {
+		case ctx.Err() != nil:
+		case c.tuner != nil:
+			if c.tuner.acquire(ctx) && ctx.Err() != nil {
+				c.tuner.cancel()
+			}
+		default:
+			select {
+			case guard <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			summary.NotAttempted = append(summary.NotAttempted, req[index:]...)
+			atomic.AddInt64(&c.metrics.queued, -int64(len(req)-index))
+			break
+		}
+
 		// crawl and parse request
+		wg.Add(1)
 		go func(r *Request) {
-			res, err := Get(r)
-			h.Handle(r, res, err)
-			<-guard
 			defer wg.Done()
+			atomic.AddInt64(&c.metrics.queued, -1)
+
+			// crawl using copy of the request bound to context, caller request may be shared (e.g. listed twice)
+			cr := *r
+			cr.ctx = ctx
+			start := time.Now()
+			res, err := c.Get(&cr)
+			handle(r, &cr, res, err)
+			if c.tuner != nil {
+				c.tuner.release(err, time.Since(start))
+			} else {
+				<-guard
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				summary.InFlight = append(summary.InFlight, r)
+			} else {
+				summary.Completed++
+			}
 		}(r)
 	}
 
 	// Wait for all Requests to complete
 	wg.Wait()
+
+	summary.Err = ctx.Err()
+	return summary
 }
 
 // ParseBody parse Ads.txt file based on Ads.txt Specification Version 1.0.1
 // https://iabtechlab.com/wp-content/uploads/2017/09/IABOpenRTB_Ads.txt_Public_Spec_V1-0-1.pdf
 func ParseBody(b []byte) (*Records, error) {
-	// use custom split function to sunpport different end-of-line marker (CR, CRLF etc)
+	return parseReader(bytes.NewReader(b), 0)
+}
+
+// parseReader parse Ads.txt file read line by line from r. When abortAfter is positive, parsing stops with error
+// once the number of high sevirity warnings reaches it (e.g. remote host returned HTML page instead of Ads.txt)
+func parseReader(r io.Reader, abortAfter int) (*Records, error) {
+	// loop over Ads.txt file lines and parse each line
+	records := newRecords()
+	high := 0
+	err := scanLines(r, func(line string, truncated bool) error {
+		records.Body = append(records.Body, line)
+
+		n := len(records.Warnings)
+		if truncated {
+			records.Warnings = append(records.Warnings, &Warning{
+				Index:   len(records.Body),
+				Text:    line,
+				Code:    WarnLineTooLong,
+				Level:   HighSevirity,
+				Message: fmt.Sprintf(warnLineTooLong, MaxLineLength),
+			})
+			records.skip(len(records.Body), line, SkipInvalid, WarnLineTooLong)
+		} else {
+			records.parseRecord(len(records.Body), line)
+		}
+
+		if len(records.Warnings) > n && records.Warnings[n].Level == HighSevirity {
+			high++
+			if abortAfter > 0 && high >= abortAfter {
+				return fmt.Errorf(errParseAborted, high, len(records.Body))
+			}
+		}
+		return nil
+	})
+
+	var aborted *abortError
+	if errors.As(err, &aborted) {
+		return records, aborted.err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	records.setFileFlags()
+	return records, nil
+}
+
+// abortError error returned by scanLines line function, stopping the scan
+type abortError struct {
+	err error
+}
+
+func (a *abortError) Error() string {
+	return a.err.Error()
+}
+
+// eolLength return length of end-of-line marker data starts with: LF, CRLF or CR (so CR CR LF is a lone CR followed
+// by CRLF, two line ends). When the marker may continue beyond data, and more data is available, false is returned
+func eolLength(data []byte, atEOF bool) (int, bool) {
+	if data[0] == '\n' {
+		return 1, true
+	}
+
+	switch {
+	case len(data) > 1 && data[1] == '\n':
+		return 2, true
+	case len(data) == 1 && !atEOF:
+		return 0, false
+	}
+	return 1, true
+}
+
+// bodyLines split Ads.txt file body to lines, exactly as they are set on parsed records body
+func bodyLines(b []byte) []string {
+	lines := []string{}
+	scanLines(bytes.NewReader(b), func(line string, truncated bool) error {
+		lines = append(lines, line)
+		return nil
+	})
+	return lines
+}
+
+// scanLines read Ads.txt file lines from r and call fn with each line, until fn returns error. Scan stops with fn
+// error wrapped as abortError
+func scanLines(r io.Reader, fn func(line string, truncated bool) error) error {
+	// use custom split function to sunpport different end-of-line marker (LF, CRLF, CR), which may be mixed in a
+	// single file. Lines longer than MaxLineLength are truncated, and the rest of the line is discarded
+	truncated, discard := false, false
 	split := func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		if atEOF && len(data) == 0 {
 			return 0, nil, nil
 		}
-		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
-			if data[i] == '\n' {
-				// We have a line terminated by single newline.
-				return i + 1, data[0:i], nil
+		i := bytes.IndexAny(data, "\r\n")
+
+		// discard the rest of truncated line, up to and including its end-of-line marker
+		if discard {
+			if i < 0 {
+				discard = !atEOF
+				return len(data), nil, nil
 			}
-			advance = i + 1
-			if len(data) > i+1 && data[i+1] == '\n' {
-				advance++
+			n, ok := eolLength(data[i:], atEOF)
+			if !ok {
+				return i, nil, nil
+			}
+			discard = false
+			return i + n, nil, nil
+		}
+
+		truncated = false
+		if i > MaxLineLength || (i < 0 && len(data) > MaxLineLength) {
+			truncated, discard = true, true
+			return MaxLineLength, data[0:MaxLineLength], nil
+		}
+
+		if i >= 0 {
+			// end-of-line marker may be split between reads (e.g. CR is the last byte read), request more data
+			n, ok := eolLength(data[i:], atEOF)
+			if !ok {
+				return 0, nil, nil
 			}
-			return advance, data[0:i], nil
+			return i + n, data[0:i], nil
 		}
 		// If we're at EOF, we have a final, non-terminated line. Return it.
 		if atEOF {
@@ -125,18 +492,13 @@ func ParseBody(b []byte) (*Records, error) {
 		return 0, nil, nil
 	}
 
-	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner := bufio.NewScanner(r)
 	scanner.Split(split)
 
-	// loop over Ads.txt file lines and parse each line
-	lines := []string{}
 	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
+		if err := fn(scanner.Text(), truncated); err != nil {
+			return &abortError{err: err}
+		}
 	}
-
-	return parseRecords(lines), nil
+	return scanner.Err()
 }
@@ -1,11 +1,17 @@
 package adstxt
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // TestGetMultiple tesing fetch and parse multile Ads.txt files from remote hosts
@@ -42,6 +48,99 @@ func TestGetMultiple(t *testing.T) {
 	GetMultiple(requests, HandlerFunc(h))
 }
 
+// TestGetMultipleContext test crawl summary of interrupted, not attempted and completed requests
+func TestGetMultipleContext(t *testing.T) {
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer fast.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	var lock sync.Mutex
+	handled := make(map[string]error)
+	h := func(req *Request, res *Response, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		handled[req.URL] = err
+	}
+
+	fastReq, _ := NewRequest(fast.URL)
+	slowReq, _ := NewRequest(slow.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	summary := NewCrawler().GetMultipleContext(ctx, []*Request{fastReq, slowReq}, HandlerFunc(h))
+	if summary.Completed != 1 || len(summary.InFlight) != 1 || summary.InFlight[0] != slowReq || len(summary.NotAttempted) != 0 {
+		t.Errorf("Expected single completed and single in-flight request and not %+v", summary)
+	}
+	if !errors.Is(summary.Err, context.DeadlineExceeded) {
+		t.Errorf("Expected summary error to be deadline exceeded and not [%v]", summary.Err)
+	}
+	if err, ok := handled[slowReq.URL]; !ok || err == nil {
+		t.Errorf("Expected in-flight request to be reported to handler with error")
+	}
+	if err := handled[fastReq.URL]; err != nil {
+		t.Errorf("Expected completed request to be reported to handler without error and not [%s]", err)
+	}
+
+	// requests are not attempted once context is done
+	handled = make(map[string]error)
+	summary = NewCrawler().GetMultipleContext(ctx, []*Request{fastReq, slowReq}, HandlerFunc(h))
+	if summary.Completed != 0 || len(summary.InFlight) != 0 || len(summary.NotAttempted) != 2 || len(handled) != 0 {
+		t.Errorf("Expected all requests not to be attempted and not %+v", summary)
+	}
+}
+
+// TestGetMultipleContextShared test request listed twice is crawled using copies, and request which failed before
+// context was done is completed and not in-flight (even when handled after context is done)
+func TestGetMultipleContextShared(t *testing.T) {
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ads.txt" {
+			http.Redirect(w, r, "/v2/ads.txt", http.StatusFound)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer missing.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	missingReq, _ := NewRequest(missing.URL)
+	slowReq, _ := NewRequest(slow.URL)
+	rawurl := missingReq.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	h := func(req *Request, res *Response, err error) {
+		if req.URL != slowReq.URL {
+			<-ctx.Done()
+		}
+	}
+	summary := NewCrawler().GetMultipleContext(ctx, []*Request{missingReq, missingReq, slowReq}, HandlerFunc(h))
+	if summary.Completed != 2 || len(summary.InFlight) != 1 || summary.InFlight[0] != slowReq {
+		t.Errorf("Expected [2] completed requests and single in-flight request and not %+v", summary)
+	}
+	if missingReq.URL != rawurl || missingReq.ctx != nil {
+		t.Errorf("Expected caller request not to be modified and not [%s]", missingReq.URL)
+	}
+}
+
 // TestGet tesing fetch and parse Ads.txt file from remote host
 func TestGet(t *testing.T) {
 	// expected response
@@ -172,3 +271,373 @@ func TestParseRecordsFailure(t *testing.T) {
 	}
 
 }
+
+// TestGetLenientContentType test Ads.txt file with wrong content type is accepted (and flagged) in lenient mode only
+func TestGetLenientContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	req, _ := NewRequest(ts.URL)
+	if _, err := Get(req); err == nil {
+		t.Error("Expected error when Ads.txt content type is not text/plain")
+	}
+
+	req, _ = NewRequest(ts.URL)
+	req.Lenient = true
+
+	res, err := Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.DataRecords) != 1 {
+		t.Fatalf("Expected single DataReocrd but found [%d]", len(res.DataRecords))
+	}
+
+	flags := res.DataRecords[0].Flags
+	if len(flags) != 1 || flags[0] != FlagLenientContentType {
+		t.Errorf("Expected DataRecord flags to be [%s] and not %v", FlagLenientContentType, flags)
+	}
+
+	if len(res.Warnings) != 1 || res.Warnings[0].Index != 0 {
+		t.Errorf("Expected single HTTP response warning but found [%d]", len(res.Warnings))
+	}
+}
+
+// TestGetEarlyAbort test fetch is aborted once the number of high sevirity warnings reaches threshold
+func TestGetEarlyAbort(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "<!DOCTYPE html>\n<html>\n<head>\n<title>Not Found</title>\n</head>\n</html>")
+	}))
+	defer ts.Close()
+
+	req, _ := NewRequest(ts.URL)
+	if _, err := NewCrawler(WithEarlyAbort(3)).Get(req); err == nil {
+		t.Error("Expected fetch to be aborted when remote host returned HTML page")
+	}
+
+	// below threshold the file is parsed as usual
+	req, _ = NewRequest(ts.URL)
+	res, err := NewCrawler(WithEarlyAbort(10)).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Body) != 6 || len(res.Warnings) != 6 {
+		t.Errorf("Expected 6 lines with warnings and not [%d\\%d]", len(res.Body), len(res.Warnings))
+	}
+}
+
+// TestGetCapturedHeaders test only allowed HTTP response headers are captured onto the response
+func TestGetCapturedHeaders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Server", "gotest")
+		w.Header().Add("X-Cache", "HIT")
+		w.Header().Add("X-Cache", "MISS")
+		w.Header().Set("X-Other", "ignored")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	req, _ := NewRequest(ts.URL)
+	res, err := NewCrawler(WithCapturedHeaders("server", "x-cache", "cf-cache-status")).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Headers) != 2 || res.Headers.Get("Server") != "gotest" || len(res.Headers["X-Cache"]) != 2 {
+		t.Errorf("Expected Server and X-Cache headers to be captured and not %v", res.Headers)
+	}
+
+	req, _ = NewRequest(ts.URL)
+	if res, _ := Get(req); res.Headers != nil {
+		t.Errorf("Expected no headers to be captured by default and not %v", res.Headers)
+	}
+}
+
+// TestGetRedirectEvents test followed and rejected redirects are reported as redirect events
+func TestGetRedirectEvents(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ads.txt":
+			http.Redirect(w, r, "/v2/ads.txt", http.StatusMovedPermanently)
+		case "/v2/ads.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+		default:
+			http.Redirect(w, r, "/", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	req, _ := NewRequest(ts.URL)
+	res, err := Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Redirects) != 1 || res.Redirects[0].Decision != RedirectFollowed || res.Redirects[0].To != ts.URL+"/v2/ads.txt" {
+		t.Errorf("Expected single followed redirect to [%s] and not %v", ts.URL+"/v2/ads.txt", res.Redirects)
+	}
+
+	// redirect to homepage is rejected
+	req, _ = NewRequest(ts.URL + "/old/")
+	_, err = Get(req)
+
+	var redirectErr *RedirectError
+	if !errors.As(err, &redirectErr) {
+		t.Fatalf("Expected redirect error and not [%v]", err)
+	}
+	if e := redirectErr.Redirects[len(redirectErr.Redirects)-1]; e.Decision != RedirectRejected || e.Status != http.StatusFound || len(e.Reason) == 0 {
+		t.Errorf("Expected last redirect to be rejected and not [%s] [%d]", e.Decision, e.Status)
+	}
+}
+
+// TestGetRedirectConnectionReuse test redirect response body is drained, so the redirected request reuse the
+// kept alive connection
+func TestGetRedirectConnectionReuse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ads.txt" {
+			w.Header().Set("Location", "/sub/ads.txt")
+			w.WriteHeader(http.StatusFound)
+			io.WriteString(w, strings.Repeat("moved ", 1000))
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	req, _ := NewRequest(ts.URL)
+	res, err := NewCrawler(WithConnectionBudget(BulkBudget)).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Timings) != 2 || !res.Timings[1].Reused {
+		t.Errorf("Expected redirected request to reuse the connection [%+v]", res.Timings)
+	}
+}
+
+// TestParseBodyFileFlags test empty, comments only and variables only files are flagged
+func TestParseBodyFileFlags(t *testing.T) {
+	bodies := map[string]string{
+		"":                                   FileEmpty,
+		" \n\t\r\n":                          FileEmpty,
+		"# ads.txt\n\n  # no records yet":    FileCommentsOnly,
+		"# ads.txt\ncontact=ads@example.com": FileVariablesOnly,
+		"greenadexchange.com,XF7342,DIRECT":  "",
+		"not a valid line":                   "",
+	}
+
+	for body, expected := range bodies {
+		rec, err := ParseBody([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(expected) == 0 && len(rec.Flags) != 0 {
+			t.Errorf("[%q] Expected no file flags and not %v", body, rec.Flags)
+		}
+		if len(expected) > 0 && (len(rec.Flags) != 1 || rec.Flags[0] != expected) {
+			t.Errorf("[%q] Expected file flag [%s] and not %v", body, expected, rec.Flags)
+		}
+	}
+}
+
+// TestParseBodyAccountIDCase test account ID declared in different letter case for the same ad system is reported
+func TestParseBodyAccountIDCase(t *testing.T) {
+	rec, err := ParseBody([]byte("google.com, pub-ABC, DIRECT\ngoogle.com, pub-abc, RESELLER\nappnexus.com, pub-abc, DIRECT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rec.Warnings) != 1 || rec.Warnings[0].Code != WarnAccountIDCase || rec.Warnings[0].Index != 2 || rec.Warnings[0].Suggestion != "pub-ABC" {
+		t.Errorf("Expected single account ID case warning on line [2] and not %v", rec.Warnings)
+	}
+}
+
+// TestGetLenientAccountIDs test invisible characters are trimmed from account IDs in lenient mode only
+func TestGetLenientAccountIDs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "google.com, pub-123\u200b, DIRECT")
+	}))
+	defer ts.Close()
+
+	for _, lenient := range []bool{false, true} {
+		req, _ := NewRequest(ts.URL)
+		req.Lenient = lenient
+
+		res, err := Get(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dr := res.DataRecords[0]
+		if lenient && (dr.PublisherAccountID != "pub-123" || dr.OriginalPublisherAccountID != "pub-123\u200b") {
+			t.Errorf("Expected account ID to be trimmed in lenient mode and not [%q]", dr.PublisherAccountID)
+		}
+		if !lenient && dr.PublisherAccountID != "pub-123\u200b" {
+			t.Errorf("Expected account ID to be kept as is and not [%q]", dr.PublisherAccountID)
+		}
+		if len(res.Warnings) != 1 || res.Warnings[0].Code != WarnAccountIDInvisibleChars {
+			t.Errorf("Expected invisible characters warning and not %v", res.Warnings)
+		}
+	}
+}
+
+// TestGetSchemeUpgrade test HTTP to HTTPS upgrade of the same URL is recorded as scheme upgrade, and doesn't count
+// against redirect policy
+func TestGetSchemeUpgrade(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://"+r.Host+r.URL.Path, http.StatusMovedPermanently)
+	}))
+	defer plain.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer secure.Close()
+
+	route := routeSchemes(plain, secure, true)
+
+	req, _ := NewRequest("upgrade.example.com")
+	res, err := NewCrawler(route).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Redirects) != 1 || res.Redirects[0].Decision != RedirectSchemeUpgrade || res.URL != "https://upgrade.example.com/ads.txt" {
+		t.Errorf("Expected single scheme upgrade redirect and not %v", res.Redirects)
+	}
+
+	expected := map[string]bool{
+		"http://example.com/ads.txt|https://example.com/ads.txt":           true,
+		"http://example.com:80/ads.txt|https://EXAMPLE.com:443/ads.txt":    true,
+		"http://example.com/ads.txt|https://www.example.com/ads.txt":       false,
+		"http://example.com/ads.txt|https://example.com/v2/ads.txt":        false,
+		"http://example.com:8080/ads.txt|https://example.com:8443/ads.txt": false,
+		"https://example.com/ads.txt|http://example.com/ads.txt":           false,
+	}
+	for pair, upgrade := range expected {
+		urls := strings.Split(pair, "|")
+		if isSchemeUpgrade(urls[0], urls[1]) != upgrade {
+			t.Errorf("[%s] Expected scheme upgrade to be [%t]", pair, upgrade)
+		}
+	}
+}
+
+// TestParseBodyLimits test lines and fields beyond hard limits are reported instead of parsed
+func TestParseBodyLimits(t *testing.T) {
+	long := strings.Repeat("a", MaxLineLength+10)
+	expected := map[string]string{
+		long + "\ngreenadexchange.com,XF7342,DIRECT":                                                     WarnLineTooLong,
+		long + "\r\ngreenadexchange.com,XF7342,DIRECT":                                                   WarnLineTooLong,
+		strings.Repeat("a", 300) + ".com,XF7342,DIRECT\ngreenadexchange.com,XF7342,DIRECT":               WarnFieldTooLong,
+		"greenadexchange.com," + strings.Repeat("1", 300) + ",DIRECT\ngreenadexchange.com,XF7342,DIRECT": WarnFieldTooLong,
+		"contact=" + strings.Repeat("a", 2000) + "\ngreenadexchange.com,XF7342,DIRECT":                   WarnFieldTooLong,
+	}
+
+	for body, code := range expected {
+		rec, err := ParseBody([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rec.Body) != 2 || len(rec.Body[0]) > MaxLineLength {
+			t.Errorf("[%s] Expected [2] lines with first line truncated and not [%d]", code, len(rec.Body))
+		}
+		if len(rec.Warnings) != 1 || rec.Warnings[0].Code != code || rec.Warnings[0].Index != 1 {
+			t.Errorf("[%s] Expected single warning on first line and not %v", code, rec.Warnings)
+		}
+		if len(rec.DataRecords) != 1 || len(rec.Variables) != 0 {
+			t.Errorf("[%s] Expected only second line record to be parsed", code)
+		}
+	}
+}
+
+// TestParseBodySkipped test lines which are not parsed into records are listed with skip reason
+func TestParseBodySkipped(t *testing.T) {
+	body := "# ads.txt\n\ngreenadexchange.com,XF7342,DIRECT\n   \t\ngreenadexchange.com,XF7342,DIRECT,abc,extra\nfoo=bar\n<html>\ncontact=ads@example.com # comment"
+	expected := map[int]string{
+		1: SkipComment,
+		2: SkipBlank,
+		4: SkipBlank,
+		5: SkipInvalid + " " + WarnInvalidFieldsCount,
+		6: SkipInvalid + " " + WarnInvalidVariableType,
+		7: SkipInvalid + " " + WarnUnparsableLine,
+	}
+
+	rec, err := ParseBody([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Skipped) != len(expected) {
+		t.Fatalf("Expected [%d] skipped lines and not [%d]", len(expected), len(rec.Skipped))
+	}
+	for _, s := range rec.Skipped {
+		if e := expected[s.Index]; strings.TrimSpace(s.Reason+" "+s.Code) != e || s.Text != rec.Body[s.Index-1] {
+			t.Errorf("[%d] Expected line to be skipped as [%s] and not [%s %s]", s.Index, e, s.Reason, s.Code)
+		}
+	}
+}
+
+// TestParseLineEndings test files using different (and mixed) end-of-line markers are parsed the same, with the
+// same line numbers
+func TestParseLineEndings(t *testing.T) {
+	files, err := filepath.Glob("testdata/line-endings/*.txt")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected line endings fixture files [%v]", err)
+	}
+
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec, err := ParseBody(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(rec.Body) != 8 || len(rec.DataRecords) != 2 || len(rec.Variables) != 2 {
+			t.Errorf("[%s] Expected [8] lines, [2] data records and [2] variables and not [%d] [%d] [%d]", f, len(rec.Body), len(rec.DataRecords), len(rec.Variables))
+			continue
+		}
+		if len(rec.Warnings) != 1 || rec.Warnings[0].Index != 6 {
+			t.Errorf("[%s] Expected single warning on line [6] and not %v", f, rec.Warnings)
+		}
+		if dr := rec.DataRecords[1]; dr.AccountType != accountTypeReseller || dr.CertAuthorityID != "" || rec.DataRecords[0].CertAuthorityID != "5jyxf8k54" {
+			t.Errorf("[%s] Expected last fields to be parsed without trailing whitespace", f)
+		}
+		if rec.Variables[0].Value != "adops@example.com" {
+			t.Errorf("[%s] Expected contact value without trailing whitespace and not [%q]", f, rec.Variables[0].Value)
+		}
+
+		blank := 0
+		for _, s := range rec.Skipped {
+			if s.Reason == SkipBlank {
+				blank++
+			}
+		}
+		if blank != 2 {
+			t.Errorf("[%s] Expected [2] blank lines and not [%d]", f, blank)
+		}
+	}
+}
+
+// TestParseLineEndingsBoundary test CRLF split between reads is counted as single end-of-line marker, and CR CR LF
+// as two
+func TestParseLineEndingsBoundary(t *testing.T) {
+	// fill the scanner initial read buffer, so CR is its last byte
+	for _, size := range []int{4094, 4095, 4096} {
+		body := "#" + strings.Repeat("x", size-1) + "\r\ngreenadexchange.com,XF7342,DIRECT\r\r\ncontact=adops@example.com"
+		rec, err := ParseBody([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rec.Body) != 4 || len(rec.Body[0]) != size || len(rec.Body[2]) != 0 {
+			t.Errorf("[%d] Expected [4] lines and not %d", size, len(rec.Body))
+		}
+	}
+}
@@ -681,7 +681,8 @@ func validateDomainName(domain string) bool {
 }
 
 // RootDomain Extract “root domain” from specified URL. Root domain is defined as the “public suffix” plus one sting in the name.
-func rootDomain(rawurl string) (string, error) {
+// Requests, caches, stores and redirect scope checks are keyed by it, so callers should use it to key their own data
+func RootDomain(rawurl string) (string, error) {
 	// Strip domain from specified URL: remove HTTP schema (http/s) and path from input URL string
 	stripDomain := func(rawurl string) string {
 		var index int
@@ -697,7 +698,14 @@ func rootDomain(rawurl string) (string, error) {
 			rawurl = rawurl[0:index]
 		}
 
-		// remove port
+		// remove port, IPv6 hosts are enclosed in brackets (e.g. [::1]:8080)
+		if strings.HasPrefix(rawurl, "[") {
+			index = strings.Index(rawurl, "]")
+			if index != -1 {
+				rawurl = rawurl[1:index]
+			}
+			return rawurl
+		}
 		index = strings.Index(rawurl, ":")
 		if index != -1 {
 			rawurl = rawurl[0:index]
@@ -706,14 +714,20 @@ func rootDomain(rawurl string) (string, error) {
 		return rawurl
 	}
 
+	// valid host is normalized (lower case, punycode) so root domains of the same host always compare equal
+	host := stripDomain(rawurl)
+	if d, err := ParseDomain(host); err == nil {
+		return d.Registrable().String(), nil
+	}
+
 	// Some private eTLD's are used, eg Amazons. Below should avoid these throwing an error
-	_, err := publicsuffix.EffectiveTLDPlusOne(stripDomain(rawurl))
+	_, err := publicsuffix.EffectiveTLDPlusOne(host)
 	if err != nil {
-		tld, _ := publicsuffix.PublicSuffix(stripDomain(rawurl))
+		tld, _ := publicsuffix.PublicSuffix(host)
 		return tld, nil
 	}
 	// extract top level domain
-	return publicsuffix.EffectiveTLDPlusOne(stripDomain(rawurl))
+	return publicsuffix.EffectiveTLDPlusOne(host)
 }
 
 // VaidateAdSystemCName validate that the specifiied ad system domain is a known Ad System.
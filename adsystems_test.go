@@ -56,10 +56,12 @@ func TestRootDomain(t *testing.T) {
 		"http://abc.raisingourkids.com/": "raisingourkids.com",
 		"https://testme.tumblr.com/":     "tumblr.com",
 		"http://port.com:8080/grid":      "port.com",
+		"http://[::1]:8080/ads.txt":      "::1",
+		"[2001:db8::1]/ads.txt":          "2001:db8::1",
 	}
 
 	for k, v := range domains {
-		res, err := rootDomain(k)
+		res, err := RootDomain(k)
 		if err != nil {
 			t.Error(err)
 		}
@@ -0,0 +1,92 @@
+package adstxt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AdSystemReport holds aggregated usage of a single ad system across a collection of Ads.txt responses
+type AdSystemReport struct {
+	Domain     string   `json:"domain"`     // Domain name of the advertising system (lower case)
+	Publishers int      `json:"publishers"` // Number of publishers referencing the advertising system
+	Direct     int      `json:"direct"`     // Number of publishers with at least one DIRECT record for the advertising system
+	Reseller   int      `json:"reseller"`   // Number of publishers with at least one RESELLER record for the advertising system
+	AccountIDs []string `json:"accountIds"` // Distinct publisher account IDs declared for the advertising system
+}
+
+// Aggregate build per ad system report from a collection of Ads.txt responses (i.e. crawl corpus). Reports are
+// sorted by number of publishers (descending) and then by ad system domain
+func Aggregate(responses []*Response) []*AdSystemReport {
+	type usage struct {
+		direct   bool
+		reseller bool
+	}
+
+	systems := make(map[string]map[string]*usage)
+	accounts := make(map[string]map[string]bool)
+
+	for index, res := range responses {
+		if res == nil || res.Records == nil {
+			continue
+		}
+
+		// publisher is identified by the request root domain, responses without request are counted separately
+		publisher := fmt.Sprintf("#%d", index)
+		if res.Request != nil {
+			publisher = res.Request.Domain.String()
+		}
+
+		for _, r := range res.DataRecords {
+			d := strings.ToLower(r.AdverterDomain.String())
+			if _, ok := systems[d]; !ok {
+				systems[d] = make(map[string]*usage)
+				accounts[d] = make(map[string]bool)
+			}
+
+			u, ok := systems[d][publisher]
+			if !ok {
+				u = &usage{}
+				systems[d][publisher] = u
+			}
+
+			switch r.AccountType {
+			case accountTypeDirect:
+				u.direct = true
+			case accountTypeReseller:
+				u.reseller = true
+			}
+
+			accounts[d][r.PublisherAccountID] = true
+		}
+	}
+
+	reports := make([]*AdSystemReport, 0, len(systems))
+	for d, publishers := range systems {
+		report := &AdSystemReport{Domain: d, Publishers: len(publishers), AccountIDs: []string{}}
+		for _, u := range publishers {
+			if u.direct {
+				report.Direct++
+			}
+			if u.reseller {
+				report.Reseller++
+			}
+		}
+
+		for id := range accounts[d] {
+			report.AccountIDs = append(report.AccountIDs, id)
+		}
+		sort.Strings(report.AccountIDs)
+
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Publishers != reports[j].Publishers {
+			return reports[i].Publishers > reports[j].Publishers
+		}
+		return reports[i].Domain < reports[j].Domain
+	})
+
+	return reports
+}
@@ -0,0 +1,45 @@
+package adstxt
+
+import (
+	"testing"
+)
+
+// TestAggregate test building per ad system report from multiple Ads.txt responses
+func TestAggregate(t *testing.T) {
+	files := map[string]string{
+		"example.com": "greenadexchange.com,XF7342,DIRECT\ngreenadexchange.com,XF7343,RESELLER\ntestexchange.net,1234,DIRECT",
+		"test.com":    "greenadexchange.com,XF7342,RESELLER\ngreenadexchange.com,XF7342,RESELLER",
+	}
+
+	responses := []*Response{}
+	for d, body := range files {
+		rec, err := ParseBody([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, &Response{Request: &Request{Domain: Domain(d)}, Records: rec})
+	}
+
+	reports := Aggregate(responses)
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 ad system reports but found [%d]", len(reports))
+	}
+
+	r := reports[0]
+	if r.Domain != "greenadexchange.com" {
+		t.Errorf("Expected first report to be for [greenadexchange.com] and not [%s]", r.Domain)
+	}
+	if r.Publishers != 2 {
+		t.Errorf("Expected [%s] to be referenced by 2 publishers and not [%d]", r.Domain, r.Publishers)
+	}
+	if r.Direct != 1 || r.Reseller != 2 {
+		t.Errorf("Expected [%s] DIRECT\\RESELLER split to be [1\\2] and not [%d\\%d]", r.Domain, r.Direct, r.Reseller)
+	}
+	if len(r.AccountIDs) != 2 || r.AccountIDs[0] != "XF7342" || r.AccountIDs[1] != "XF7343" {
+		t.Errorf("Expected [%s] distinct account IDs to be [XF7342 XF7343] and not %v", r.Domain, r.AccountIDs)
+	}
+
+	if reports[1].Publishers != 1 || reports[1].Direct != 1 {
+		t.Errorf("Expected [%s] to be referenced by single DIRECT publisher", reports[1].Domain)
+	}
+}
@@ -0,0 +1,155 @@
+package adstxt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// errAlertSink alert couldn't be delivered
+const errAlertSink = "[%s] failed to send alert: %s"
+
+// Alert change of publisher Ads.txt file, delivered by alert sinks
+type Alert struct {
+	Domain  string    `json:"domain"`  // Domain publisher root domain
+	URL     string    `json:"url"`     // URL of the changed Ads.txt file
+	At      time.Time `json:"at"`      // At crawl date of the change
+	Added   []string  `json:"added"`   // Added lines of the file (comments and blank lines excluded)
+	Removed []string  `json:"removed"` // Removed lines of the file (comments and blank lines excluded)
+}
+
+// NewAlert create alert of changes between previous Ads.txt file body (e.g. latest stored snapshot body, see
+// Bodies) and crawled response. Returns nil when no meaningful line was added or removed
+func NewAlert(previous []string, res *Response, at time.Time) *Alert {
+	if res == nil || res.Request == nil || res.Records == nil {
+		return nil
+	}
+
+	// lines are compared as multisets, so reordering isn't reported as change
+	count := map[string]int{}
+	for _, l := range previous {
+		if l = removeComment(l); len(l) > 0 {
+			count[l]++
+		}
+	}
+
+	a := &Alert{Domain: res.Request.Domain.String(), URL: res.Request.URL, At: at, Added: []string{}, Removed: []string{}}
+	for _, l := range res.Body {
+		if l = removeComment(l); len(l) == 0 {
+			continue
+		}
+		if count[l] > 0 {
+			count[l]--
+			continue
+		}
+		a.Added = append(a.Added, l)
+	}
+	for _, l := range previous {
+		if l = removeComment(l); len(l) > 0 && count[l] > 0 {
+			count[l]--
+			a.Removed = append(a.Removed, l)
+		}
+	}
+
+	if len(a.Added) == 0 && len(a.Removed) == 0 {
+		return nil
+	}
+	return a
+}
+
+// The AlertSink interface is used to deliver Ads.txt change alerts (e.g. e-mail or chat message)
+type AlertSink interface {
+	Send(*Alert) error
+}
+
+// DefaultAlertTemplate alert message template, executed with the Alert as data
+var DefaultAlertTemplate = template.Must(template.New("alert").Parse(`Ads.txt file of {{.Domain}} changed ({{.At.Format "2006-01-02 15:04 MST"}})
+{{.URL}}
+{{range .Added}}
++ {{.}}{{end}}{{range .Removed}}
+- {{.}}{{end}}
+`))
+
+// renderAlert execute alert message template, default template is used when t is nil
+func renderAlert(t *template.Template, a *Alert) (string, error) {
+	if t == nil {
+		t = DefaultAlertTemplate
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, a); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// slackEscaper escape control characters of Slack message text (crawled lines may contain e.g. <!channel>)
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SlackSink deliver alerts to Slack incoming webhook
+type SlackSink struct {
+	WebhookURL string             // WebhookURL Slack incoming webhook URL
+	Template   *template.Template // Template of the message text (DefaultAlertTemplate when nil)
+	Client     *http.Client       // Client used to post messages (http.DefaultClient when nil)
+}
+
+// Send post alert message to Slack webhook
+func (s *SlackSink) Send(a *Alert) error {
+	text, err := renderAlert(s.Template, a)
+	if err != nil {
+		return fmt.Errorf(errAlertSink, a.Domain, err.Error())
+	}
+	payload, err := json.Marshal(map[string]string{"text": slackEscaper.Replace(text)})
+	if err != nil {
+		return fmt.Errorf(errAlertSink, a.Domain, err.Error())
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Post(s.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf(errAlertSink, a.Domain, err.Error())
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf(errAlertSink, a.Domain, "Slack webhook responded with "+res.Status)
+	}
+	return nil
+}
+
+// SMTPSink deliver alerts as plain text e-mail messages
+type SMTPSink struct {
+	Addr     string             // Addr SMTP server address (host:port)
+	Auth     smtp.Auth          // Auth SMTP authentication, nil for none
+	From     string             // From sender address
+	To       []string           // To recipients addresses
+	Template *template.Template // Template of the message body (DefaultAlertTemplate when nil)
+}
+
+// Send e-mail alert message to recipients
+func (s *SMTPSink) Send(a *Alert) error {
+	text, err := renderAlert(s.Template, a)
+	if err != nil {
+		return fmt.Errorf(errAlertSink, a.Domain, err.Error())
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&msg, "Subject: Ads.txt file of %s changed\r\n", a.Domain)
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+
+	if err := smtp.SendMail(s.Addr, s.Auth, s.From, s.To, msg.Bytes()); err != nil {
+		return fmt.Errorf(errAlertSink, a.Domain, err.Error())
+	}
+	return nil
+}
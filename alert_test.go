@@ -0,0 +1,145 @@
+package adstxt
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNewAlert test alert lists added and removed lines, ignoring comments and reordering
+func TestNewAlert(t *testing.T) {
+	previous := []string{"# v1", "greenadexchange.com,XF7342,DIRECT", "appnexus.com,1234,RESELLER", ""}
+	body := map[string]int{
+		"greenadexchange.com,XF7342,DIRECT # v2\nappnexus.com,1234,RESELLER": 0,
+		"appnexus.com,1234,RESELLER\ngreenadexchange.com,XF7342,DIRECT":      0,
+		"greenadexchange.com,XF7342,DIRECT\nappnexus.com,5678,RESELLER":      2,
+	}
+
+	for b, changes := range body {
+		records, _ := ParseBody([]byte(b))
+		a := NewAlert(previous, &Response{Request: &Request{Domain: "example.com"}, Records: records}, time.Now())
+		if changes == 0 && a != nil {
+			t.Errorf("Expected no alert of [%s]", b)
+		}
+		if changes > 0 && (a == nil || len(a.Added)+len(a.Removed) != changes) {
+			t.Errorf("Expected alert of [%d] changed lines of [%s]", changes, b)
+		}
+	}
+}
+
+// testAlert alert of single added and removed line
+var testAlert = &Alert{
+	Domain:  "example.com",
+	URL:     "https://example.com/ads.txt",
+	At:      time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC),
+	Added:   []string{"appnexus.com,5678,RESELLER"},
+	Removed: []string{"appnexus.com,1234,RESELLER"},
+}
+
+// TestSlackSink test alert is posted to Slack webhook as message text
+func TestSlackSink(t *testing.T) {
+	var text string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		text = payload["text"]
+	}))
+	defer ts.Close()
+
+	if err := (&SlackSink{WebhookURL: ts.URL}).Send(testAlert); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"example.com changed", "https://example.com/ads.txt", "+ appnexus.com,5678,RESELLER", "- appnexus.com,1234,RESELLER"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("Expected Slack message to contain [%s]:\n%s", s, text)
+		}
+	}
+}
+
+// TestSlackSinkEscape test Slack control characters of alert lines are escaped
+func TestSlackSinkEscape(t *testing.T) {
+	var text string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		text = payload["text"]
+	}))
+	defer ts.Close()
+
+	a := *testAlert
+	a.Added = []string{"<!channel> a&b <https://evil.com|click>"}
+	if err := (&SlackSink{WebhookURL: ts.URL}).Send(&a); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "&lt;!channel&gt; a&amp;b &lt;https://evil.com|click&gt;"; !strings.Contains(text, expected) {
+		t.Errorf("Expected Slack message to contain escaped [%s]:\n%s", expected, text)
+	}
+	if strings.Contains(text, "<") || strings.Contains(text, ">") {
+		t.Errorf("Expected Slack message without control characters:\n%s", text)
+	}
+}
+
+// TestSMTPSink test alert is sent as e-mail message
+func TestSMTPSink(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// minimal SMTP server, accepting single message
+	data := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var msg strings.Builder
+				for {
+					l, _ := r.ReadString('\n')
+					if l == ".\r\n" || len(l) == 0 {
+						break
+					}
+					msg.WriteString(l)
+				}
+				data <- msg.String()
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	sink := &SMTPSink{Addr: l.Addr().String(), From: "crawler@example.com", To: []string{"adops@example.com"}}
+	if err := sink.Send(testAlert); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := <-data
+	for _, s := range []string{"Subject: Ads.txt file of example.com changed", "To: adops@example.com", "+ appnexus.com,5678,RESELLER"} {
+		if !strings.Contains(msg, s) {
+			t.Errorf("Expected e-mail message to contain [%s]:\n%s", s, msg)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package adstxt
+
+import (
+	"strings"
+)
+
+// Seller account authorization status
+const (
+	// AuthDirect seller account is authorized as DIRECT (if declared both as DIRECT and RESELLER, DIRECT wins)
+	AuthDirect = accountTypeDirect
+	// AuthReseller seller account is authorized as RESELLER
+	AuthReseller = accountTypeReseller
+	// AuthUnauthorized seller account is not declared in publisher Ads.txt file
+	AuthUnauthorized = "UNAUTHORIZED"
+)
+
+// AuthResult answer whether seller account is authorized to sell publisher inventory, with supporting evidence
+type AuthResult struct {
+	Status   string        `json:"status"`   // Status DIRECT, RESELLER or UNAUTHORIZED
+	Evidence []*DataRecord `json:"evidence"` // Evidence Ads.txt data records declaring the seller account
+	URL      string        `json:"url"`      // URL of the Ads.txt file used for the check (after redirects)
+	Expires  Expiration    `json:"expires"`  // Expires Ads.txt file expiration date, the answer is valid until then
+}
+
+// Authorized check if seller account is authorized (either DIRECT or RESELLER)
+func (a AuthResult) Authorized() bool {
+	return a.Status != AuthUnauthorized
+}
+
+// authConfig IsAuthorized settings
+type authConfig struct {
+	crawler *Crawler
+	cache   *Cache
+}
+
+// AuthOption configure IsAuthorized
+type AuthOption func(*authConfig)
+
+// WithAuthCrawler fetch Ads.txt file using crawler (instead of default crawler)
+func WithAuthCrawler(c *Crawler) AuthOption {
+	return func(a *authConfig) {
+		a.crawler = c
+	}
+}
+
+// WithAuthCache lookup Ads.txt file in cache before fetching it (cache fetch function is used on cache miss)
+func WithAuthCache(c *Cache) AuthOption {
+	return func(a *authConfig) {
+		a.cache = c
+	}
+}
+
+// IsAuthorized fetch publisher Ads.txt file (or look it up in cache) and check if seller account ID on ad system
+// is authorized to sell publisher inventory
+func IsAuthorized(domain string, adSystem string, sellerAccountID string, opts ...AuthOption) (AuthResult, error) {
+	cfg := &authConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	req, err := NewRequest(domain)
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	var res *Response
+	switch {
+	case cfg.cache != nil:
+		res, err = cfg.cache.Get(req)
+	case cfg.crawler != nil:
+		res, err = cfg.crawler.Get(req)
+	default:
+		res, err = Get(req)
+	}
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	return authorize(res, adSystem, sellerAccountID), nil
+}
+
+// authorize check seller account against Ads.txt response data records
+func authorize(res *Response, adSystem string, sellerAccountID string) AuthResult {
+	a := AuthResult{Status: AuthUnauthorized, Evidence: []*DataRecord{}, Expires: res.Expires}
+	if res.Request != nil {
+		a.URL = res.Request.URL
+	}
+	if res.Records == nil {
+		return a
+	}
+
+	key := newSellerKey(canonicalAdSystemDomain(strings.TrimSpace(adSystem)), sellerAccountID)
+	for _, dr := range res.DataRecords {
+		if newSellerKey(dr.AdverterDomain.String(), dr.PublisherAccountID) != key {
+			continue
+		}
+
+		a.Evidence = append(a.Evidence, dr)
+		if a.Status != AuthDirect {
+			a.Status = dr.AccountType
+		}
+	}
+
+	return a
+}
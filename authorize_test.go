@@ -0,0 +1,40 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestIsAuthorized test seller account authorization status and evidence
+func TestIsAuthorized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "google.com, pub-1, RESELLER\ngoogle.com, pub-1, DIRECT\ngoogle.com, pub-2, RESELLER\n")
+	}))
+	defer ts.Close()
+
+	accounts := map[string]struct {
+		status   string
+		evidence int
+	}{
+		"pub-1": {AuthDirect, 2},
+		"pub-2": {AuthReseller, 1},
+		"pub-3": {AuthUnauthorized, 0},
+	}
+
+	cache := NewCache(0, 0)
+	for account, expected := range accounts {
+		a, err := IsAuthorized(ts.URL, "WWW.Google.com", account, WithAuthCache(cache))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Status != expected.status || len(a.Evidence) != expected.evidence {
+			t.Errorf("[%s] Expected status [%s] with [%d] records and not [%s] [%d]", account, expected.status, expected.evidence, a.Status, len(a.Evidence))
+		}
+		if a.Authorized() != (expected.status != AuthUnauthorized) {
+			t.Errorf("[%s] Unexpected authorization [%t]", account, a.Authorized())
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package adstxt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Tuner default settings
+const (
+	defaultTunerWindow       = 20
+	defaultTunerErrorRate    = 0.25
+	defaultTunerOpenFileRate = 0.8
+)
+
+// Tuner adjust GetMultiple concurrency at runtime within Min and Max bounds (see WithAutoTuning). Concurrency starts
+// at Min and is re-evaluated after every Window completed crawls: it is halved when the window error rate, average
+// latency or open file descriptors usage is over its threshold, and increased by a quarter otherwise. The tuner is
+// safe for concurrent use, and may be shared by crawls of a single crawler. Tuner literals are usable as is: Min is at
+// least 1 and Max at least Min, and unset thresholds keep their zero meaning (use NewTuner for default thresholds)
+type Tuner struct {
+	Min          int           // Min minimum number of requests crawled in parallel
+	Max          int           // Max maximum number of requests crawled in parallel
+	Window       int           // Window number of completed crawls between adjustments
+	MaxErrorRate float64       // MaxErrorRate rate of transport, origin and throttling failures in window over which concurrency is decreased
+	MaxLatency   time.Duration // MaxLatency average crawl latency in window over which concurrency is decreased (0 to ignore latency)
+	MaxOpenFiles float64       // MaxOpenFiles rate of open file descriptors to process limit over which concurrency is decreased (0 to ignore)
+
+	lock    sync.Mutex
+	limit   int
+	active  int
+	wake    chan struct{} // closed (and replaced) when slot is released or limit changes
+	crawls  int
+	failed  int
+	latency time.Duration
+
+	openFiles func() (open int, limit int, ok bool)
+}
+
+// NewTuner create new concurrency tuner with bounds
+func NewTuner(min int, max int) *Tuner {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &Tuner{
+		Min:          min,
+		Max:          max,
+		Window:       defaultTunerWindow,
+		MaxErrorRate: defaultTunerErrorRate,
+		MaxOpenFiles: defaultTunerOpenFileRate,
+		limit:        min,
+		wake:         make(chan struct{}),
+		openFiles:    openFiles,
+	}
+}
+
+// Limit return current concurrency limit
+func (t *Tuner) Limit() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.init()
+	return t.limit
+}
+
+// init lazily set up state of tuner which was not created with NewTuner, caller must hold the lock
+func (t *Tuner) init() {
+	if t.wake == nil {
+		t.wake = make(chan struct{})
+	}
+	if t.openFiles == nil {
+		t.openFiles = openFiles
+	}
+	if t.limit == 0 {
+		t.limit, _ = t.bounds()
+	}
+}
+
+// bounds return concurrency bounds, Min is at least 1 and Max at least Min
+func (t *Tuner) bounds() (min int, max int) {
+	min, max = t.Min, t.Max
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
+
+// acquire wait for crawl slot under current limit, false when context is done first
+func (t *Tuner) acquire(ctx context.Context) bool {
+	for {
+		t.lock.Lock()
+		t.init()
+		if t.active < t.limit {
+			t.active++
+			t.lock.Unlock()
+			return true
+		}
+		wake := t.wake
+		t.lock.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// release crawl slot and record crawl result, adjusting the limit at the end of window
+func (t *Tuner) release(err error, latency time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.init()
+
+	t.active--
+	t.crawls++
+	t.latency += latency
+	if overloaded(err) {
+		t.failed++
+	}
+
+	if t.crawls >= t.Window {
+		t.adjust()
+		t.crawls, t.failed, t.latency = 0, 0, 0
+	}
+	t.notify()
+}
+
+// cancel release acquired crawl slot of request which was not crawled
+func (t *Tuner) cancel() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.init()
+
+	t.active--
+	t.notify()
+}
+
+// notify wake up requests waiting for crawl slot, caller must hold the lock
+func (t *Tuner) notify() {
+	close(t.wake)
+	t.wake = make(chan struct{})
+}
+
+// adjust limit using window results, caller must hold the lock
+func (t *Tuner) adjust() {
+	pressure := float64(t.failed)/float64(t.crawls) > t.MaxErrorRate
+	if t.MaxLatency > 0 && t.latency/time.Duration(t.crawls) > t.MaxLatency {
+		pressure = true
+	}
+	if open, limit, ok := t.openFiles(); ok && t.MaxOpenFiles > 0 && limit > 0 && float64(open)/float64(limit) > t.MaxOpenFiles {
+		pressure = true
+	}
+
+	if pressure {
+		t.limit /= 2
+	} else {
+		t.limit += t.limit/4 + 1
+	}
+	min, max := t.bounds()
+	if t.limit < min {
+		t.limit = min
+	}
+	if t.limit > max {
+		t.limit = max
+	}
+}
+
+// overloaded check if crawl error indicates remote hosts or local network overload: transport and origin failures,
+// and throttling (429). Other client errors (e.g. missing Ads.txt file) are expected on domain lists, and don't count
+func overloaded(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.Retryable()
+	}
+	return FailureClass(err) == FailureTransport
+}
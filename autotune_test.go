@@ -0,0 +1,127 @@
+package adstxt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestTuner test concurrency limit grows while crawls are healthy, and is halved under error rate, latency and
+// open files pressure, within bounds
+func TestTuner(t *testing.T) {
+	tuner := NewTuner(2, 10)
+	tuner.Window = 4
+	tuner.MaxLatency = time.Second
+	open := 0
+	tuner.openFiles = func() (int, int, bool) { return open, 100, true }
+
+	window := func(err error, latency time.Duration) int {
+		for i := 0; i < tuner.Window; i++ {
+			tuner.acquire(context.Background())
+			tuner.release(err, latency)
+		}
+		return tuner.Limit()
+	}
+
+	transportErr := fmt.Errorf("dial: %w", context.DeadlineExceeded)
+	windows := []struct {
+		name    string
+		err     error
+		latency time.Duration
+		open    int
+		limit   int
+	}{
+		{"healthy", nil, time.Millisecond, 10, 3},
+		{"healthy", nil, time.Millisecond, 10, 4},
+		{"healthy", nil, time.Millisecond, 10, 6},
+		{"client-error", &StatusError{StatusCode: http.StatusNotFound}, time.Millisecond, 10, 8},
+		{"healthy", nil, time.Millisecond, 10, 10},
+		{"healthy", nil, time.Millisecond, 10, 10},
+		{"transport-error", transportErr, time.Millisecond, 10, 5},
+		{"slow", nil, 2 * time.Second, 10, 2},
+		{"slow", nil, 2 * time.Second, 10, 2},
+		{"healthy", nil, time.Millisecond, 10, 3},
+		{"open-files", nil, time.Millisecond, 90, 2},
+		{"throttled", &StatusError{StatusCode: http.StatusTooManyRequests}, time.Millisecond, 10, 2},
+	}
+	for i, w := range windows {
+		open = w.open
+		if limit := window(w.err, w.latency); limit != w.limit {
+			t.Errorf("Expected limit [%d] after [%s] window [%d] and not [%d]", w.limit, w.name, i, limit)
+		}
+	}
+
+	// waiting request is admitted on release, or given up on context done
+	tuner = NewTuner(1, 1)
+	tuner.acquire(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if tuner.acquire(ctx) {
+		t.Errorf("Expected request over the limit to wait until context is done")
+	}
+	admitted := make(chan bool)
+	go func() { admitted <- tuner.acquire(context.Background()) }()
+	tuner.release(nil, 0)
+	if !<-admitted {
+		t.Errorf("Expected waiting request to be admitted on release")
+	}
+
+	// tuner literals start at Min, and are bounded by Max once adjusted
+	for _, tuner := range []*Tuner{{Min: 2, Max: 8, Window: 1}, {Window: 1}} {
+		min, max := tuner.bounds()
+		if !tuner.acquire(context.Background()) || tuner.Limit() != min {
+			t.Errorf("Expected tuner literal [%d-%d] to admit request with limit [%d]", tuner.Min, tuner.Max, min)
+		}
+		for i := 0; i < 10; i++ {
+			tuner.release(nil, 0)
+			tuner.acquire(context.Background())
+		}
+		tuner.cancel()
+		if tuner.Limit() != max {
+			t.Errorf("Expected tuner literal limit to grow to [%d] and not [%d]", max, tuner.Limit())
+		}
+	}
+}
+
+// TestGetMultipleAutoTuning test GetMultiple crawl within tuner limit, which grows on healthy crawl
+func TestGetMultipleAutoTuning(t *testing.T) {
+	var inFlight, peak int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for p := atomic.LoadInt64(&peak); n > p && !atomic.CompareAndSwapInt64(&peak, p, n); p = atomic.LoadInt64(&peak) {
+		}
+
+		time.Sleep(2 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	req := []*Request{}
+	for i := 0; i < 60; i++ {
+		r, _ := NewRequest(ts.URL)
+		req = append(req, r)
+	}
+
+	tuner := NewTuner(1, 4)
+	tuner.Window = 5
+	var failed int64
+	NewCrawler(WithAutoTuning(tuner)).GetMultiple(req, HandlerFunc(func(req *Request, res *Response, err error) {
+		if err != nil {
+			atomic.AddInt64(&failed, 1)
+		}
+	}))
+
+	if failed > 0 {
+		t.Errorf("Expected all requests to be crawled and not [%d] failed", failed)
+	}
+	if peak > 4 || tuner.Limit() != 4 {
+		t.Errorf("Expected tuner limit to grow up to [4] and not [%d] (peak [%d])", tuner.Limit(), peak)
+	}
+}
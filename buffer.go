@@ -0,0 +1,229 @@
+package adstxt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Result single crawl result collected by result buffer
+type Result struct {
+	Request  *Request  `json:"request"`            // Request crawled request
+	Response *Response `json:"response,omitempty"` // Response parsed Ads.txt file, nil on error
+	Error    string    `json:"error,omitempty"`    // Error message of failed crawl
+	Err      error     `json:"-"`                  // Err crawl error (spilled results error only holds the message)
+}
+
+// ResultBuffer collect crawl results (it is a Handler of GetMultiple), keeping up to Limit results in memory.
+// Beyond the limit results are spilled to temporary file encoded using Codec, so corpus scale crawls can be
+// collected (e.g. for summary or export) in bounded memory. The buffer is safe for concurrent use, and must be
+// closed to remove the temporary file
+type ResultBuffer struct {
+	Limit int    // Limit maximum number of results kept in memory
+	Dir   string // Dir directory of the temporary spill file (default temporary directory when empty)
+	Codec Codec  // Codec used to encode spilled results
+
+	lock    sync.Mutex
+	memory  []*Result
+	file    *os.File
+	w       *bufio.Writer
+	spilled int
+	err     error
+}
+
+// NewResultBuffer create new result buffer keeping up to limit results in memory, spilled results are encoded
+// using JSON codec
+func NewResultBuffer(limit int) *ResultBuffer {
+	return &ResultBuffer{Limit: limit, Codec: JSONCodec, memory: []*Result{}}
+}
+
+// Handle collect crawl result
+func (b *ResultBuffer) Handle(req *Request, res *Response, err error) {
+	r := &Result{Request: req, Response: res, Err: err}
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.memory) < b.Limit {
+		b.memory = append(b.memory, r)
+		return
+	}
+	if b.err == nil {
+		b.err = b.spill(r)
+	}
+}
+
+// spill write result to spill file, caller must hold the buffer lock. Results are length prefixed, since codec
+// may be binary
+func (b *ResultBuffer) spill(r *Result) error {
+	if b.file == nil {
+		f, err := os.CreateTemp(b.Dir, "adstxt-results-*")
+		if err != nil {
+			return err
+		}
+		b.file, b.w = f, bufio.NewWriter(f)
+	}
+
+	data, err := b.Codec.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := writeFrame(b.w, data); err != nil {
+		return err
+	}
+	b.spilled++
+	return nil
+}
+
+// maxFrameSize maximum size of length prefixed data, larger length prefix means the file is corrupt
+const maxFrameSize = 256 << 20
+
+// errFrameTooLarge frame length prefix exceeds maxFrameSize
+const errFrameTooLarge = "frame of [%d] bytes is larger than [%d] bytes"
+
+// writeFrame write length prefixed data
+func writeFrame(w io.Writer, data []byte) error {
+	if len(data) > maxFrameSize {
+		return fmt.Errorf(errFrameTooLarge, len(data), maxFrameSize)
+	}
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readFrame read length prefixed data written by writeFrame
+func readFrame(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxFrameSize {
+		return nil, fmt.Errorf(errFrameTooLarge, n, maxFrameSize)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// framesEnd return offset of the end of the last complete length prefixed frame, data after it was torn while
+// it was written
+func framesEnd(r io.Reader) (int64, error) {
+	br := bufio.NewReader(r)
+	var end int64
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(br, size[:]); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return end, nil
+		} else if err != nil {
+			return 0, err
+		}
+		n := binary.BigEndian.Uint32(size[:])
+		if n > maxFrameSize {
+			return 0, fmt.Errorf(errFrameTooLarge, n, maxFrameSize)
+		}
+		if _, err := io.CopyN(io.Discard, br, int64(n)); errors.Is(err, io.EOF) {
+			return end, nil
+		} else if err != nil {
+			return 0, err
+		}
+		end += 4 + int64(n)
+	}
+}
+
+// Len return number of collected results
+func (b *ResultBuffer) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.memory) + b.spilled
+}
+
+// Spilled return number of results spilled to disk
+func (b *ResultBuffer) Spilled() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.spilled
+}
+
+// Err return the first error writing results to spill file, results collected after the error are dropped
+func (b *ResultBuffer) Err() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.err
+}
+
+// Each call fn with every result collected when the iteration started, in collection order: in memory results
+// first, then spilled results decoded one by one. Iteration stops on the first error. Results are collected
+// concurrently with the iteration (the buffer is not locked while fn is called), but the buffer must not be closed
+func (b *ResultBuffer) Each(fn func(*Result) error) error {
+	b.lock.Lock()
+	memory, spilled, file := b.memory, b.spilled, b.file
+	var err error
+	if file != nil {
+		err = b.w.Flush()
+	}
+	b.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range memory {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	if file == nil {
+		return nil
+	}
+
+	// read spill file at offsets (ReadAt), so results spilled during iteration are still appended to its end
+	reader := bufio.NewReader(io.NewSectionReader(file, 0, 1<<62))
+	for i := 0; i < spilled; i++ {
+		data, err := readFrame(reader)
+		if err != nil {
+			return err
+		}
+
+		r := &Result{}
+		if err := b.Codec.Unmarshal(data, r); err != nil {
+			return err
+		}
+		if len(r.Error) > 0 {
+			r.Err = errors.New(r.Error)
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close remove spill file, and release results kept in memory
+func (b *ResultBuffer) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.memory, b.spilled = []*Result{}, 0
+	if b.file == nil {
+		return nil
+	}
+
+	f := b.file
+	b.file, b.w = nil, nil
+	err := f.Close()
+	if rerr := os.Remove(f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package adstxt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestResultBuffer test results beyond the buffer limit are spilled to disk and iterated in collection order
+func TestResultBuffer(t *testing.T) {
+	for _, codec := range []Codec{JSONCodec, MsgpackCodec} {
+		b := NewResultBuffer(2)
+		b.Dir = t.TempDir()
+		b.Codec = codec
+
+		for i := 0; i < 5; i++ {
+			req := &Request{Domain: Domain(fmt.Sprintf("example%d.com", i)), URL: fmt.Sprintf("https://example%d.com/ads.txt", i)}
+			if i == 3 {
+				b.Handle(req, nil, errors.New("connection refused"))
+				continue
+			}
+			res, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT"))
+			b.Handle(req, &Response{Request: req, Records: res}, nil)
+		}
+
+		if b.Len() != 5 || b.Spilled() != 3 || b.Err() != nil {
+			t.Fatalf("[%s] Expected [5] results with [3] spilled and not [%d] [%d] [%v]", codec.Name(), b.Len(), b.Spilled(), b.Err())
+		}
+
+		// results collected during iteration are not iterated
+		i := 0
+		err := b.Each(func(r *Result) error {
+			if b.Len() != 5+i {
+				t.Errorf("[%s] Expected [%d] results collected during iteration and not [%d]", codec.Name(), 5+i, b.Len())
+			}
+			req := &Request{Domain: "late.com", URL: "https://late.com/ads.txt"}
+			b.Handle(req, nil, errors.New("connection refused"))
+
+			if r.Request.Domain.String() != fmt.Sprintf("example%d.com", i) {
+				t.Errorf("[%s] Expected result #%d of [example%d.com] and not [%s]", codec.Name(), i, i, r.Request.Domain)
+			}
+			if i == 3 && (r.Err == nil || r.Response != nil) {
+				t.Errorf("[%s] Expected failed result #%d to keep its error", codec.Name(), i)
+			}
+			if i != 3 && (r.Response == nil || len(r.Response.DataRecords) != 1) {
+				t.Errorf("[%s] Expected result #%d to keep its data records", codec.Name(), i)
+			}
+			i++
+			return nil
+		})
+		if err != nil || i != 5 {
+			t.Errorf("[%s] Expected iteration of [5] results and not [%d] [%v]", codec.Name(), i, err)
+		}
+
+		if b.Len() != 10 {
+			t.Errorf("[%s] Expected [10] results after iteration and not [%d]", codec.Name(), b.Len())
+		}
+
+		name := b.file.Name()
+		if err := b.Close(); err != nil {
+			t.Error(err)
+		}
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("[%s] Expected spill file to be removed on close", codec.Name())
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package adstxt
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache holds Ads.txt responses keyed by the request tenant, registrable (root) domain and file (see FileKey). Entries are refreshed before
+// their expiration date with a random jitter, so a corpus crawled at once does not expire at the same instant.
+// Expired entries are still served during the stale-while-revalidate window while being refreshed in background.
+// Cache is safe for concurrent use
+type Cache struct {
+	Jitter               float64       // Jitter fraction of entry TTL (0-1) randomly subtracted from entry expiration date
+	StaleWhileRevalidate time.Duration // StaleWhileRevalidate duration in which expired entry is served while refreshed
+
+	lock     sync.Mutex
+	entries  map[string]*cacheEntry
+	fetching map[string]*cacheFetch            // in-flight fetches of cache misses
+	fetch    func(*Request) (*Response, error) // fetch Ads.txt file from remote host
+	path     func(*Request) string             // default file path of the crawler fetching request (see WithDefaultPath)
+	now      func() time.Time
+	random   func() float64
+}
+
+// cacheEntry single cached Ads.txt response
+type cacheEntry struct {
+	res          *Response
+	refreshAt    time.Time // jittered expiration date of the entry
+	staleUntil   time.Time // entry is not served after this date
+	revalidating bool      // background refresh in progress
+}
+
+// cacheFetch in-flight fetch of cache miss, shared by concurrent misses of the same entry
+type cacheFetch struct {
+	done chan struct{} // closed when the fetch is done
+	res  *Response
+	err  error
+}
+
+// CacheOption configure Cache
+type CacheOption func(*Cache)
+
+// WithCacheCrawler fetch Ads.txt files of cache misses and refreshes using crawler (instead of default crawler), so
+// crawler settings (politeness, retries, user agent, differential crawl etc) apply
+func WithCacheCrawler(c *Crawler) CacheOption {
+	return func(cache *Cache) {
+		cache.fetch = c.Get
+		cache.path = func(*Request) string { return c.path }
+	}
+}
+
+// WithCacheTenants fetch Ads.txt files of cache misses and refreshes using the request tenant crawler
+func WithCacheTenants(t *Tenants) CacheOption {
+	return func(cache *Cache) {
+		cache.fetch = t.Get
+		cache.path = func(req *Request) string { return t.Crawler(req.Tenant).path }
+	}
+}
+
+// NewCache create new Ads.txt response cache with the specified TTL jitter and stale-while-revalidate window
+func NewCache(jitter float64, staleWhileRevalidate time.Duration, opts ...CacheOption) *Cache {
+	c := &Cache{
+		Jitter:               jitter,
+		StaleWhileRevalidate: staleWhileRevalidate,
+		entries:              make(map[string]*cacheEntry),
+		fetching:             make(map[string]*cacheFetch),
+		fetch:                Get,
+		now:                  time.Now,
+		random:               rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get return cached Ads.txt response for the request domain, fetching it from remote host when the domain is
+// not cached or its entry is expired beyond the stale-while-revalidate window. Concurrent misses of the same entry
+// wait for single fetch
+func (c *Cache) Get(req *Request) (*Response, error) {
+	key := c.key(req)
+
+	c.lock.Lock()
+	e, ok := c.entries[key]
+	now := c.now()
+	if ok && now.Before(e.refreshAt) {
+		c.lock.Unlock()
+		return e.res, nil
+	}
+
+	// serve stale entry and refresh it in background (only single refresh per entry)
+	if ok && now.Before(e.staleUntil) {
+		if !e.revalidating {
+			e.revalidating = true
+			go c.revalidate(key, req)
+		}
+		c.lock.Unlock()
+		return e.res, nil
+	}
+
+	// wait for fetch of concurrent miss
+	if f, ok := c.fetching[key]; ok {
+		c.lock.Unlock()
+		<-f.done
+		return f.res, f.err
+	}
+	f := &cacheFetch{done: make(chan struct{})}
+	c.fetching[key] = f
+	c.lock.Unlock()
+
+	// fetch using copy of the request, since fetch updates request URL (default path and redirects)
+	r := *req
+	f.res, f.err = c.fetch(&r)
+	if f.err == nil {
+		c.Set(f.res)
+	}
+
+	c.lock.Lock()
+	delete(c.fetching, key)
+	c.lock.Unlock()
+	close(f.done)
+
+	return f.res, f.err
+}
+
+// Set add Ads.txt response to the cache (replacing any existing entry for the same domain)
+func (c *Cache) Set(res *Response) {
+	if res == nil || res.Request == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	refreshAt := res.Expires.RefreshAt
+
+	// subtract random jitter from entry TTL, never extending it beyond Ads.txt file refresh date
+	if ttl := refreshAt.Sub(now); ttl > 0 && c.Jitter > 0 {
+		refreshAt = refreshAt.Add(-time.Duration(float64(ttl) * c.Jitter * c.random()))
+	}
+
+	c.entries[tenantKey(res.Request.Tenant, FileKey(res.Request.Domain.String(), res.requestedURL()))] = &cacheEntry{
+		res:        res,
+		refreshAt:  refreshAt,
+		staleUntil: refreshAt.Add(c.StaleWhileRevalidate),
+	}
+}
+
+// Delete remove cached Ads.txt responses of all files of the specified root domain
+func (c *Cache) Delete(domain string) {
+	c.DeleteTenant("", domain)
+}
+
+// DeleteTenant remove cached Ads.txt responses of all files of the specified tenant root domain
+func (c *Cache) DeleteTenant(tenant string, domain string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := tenantKey(tenant, domain)
+	for k := range c.entries {
+		if k == key || strings.HasPrefix(k, key+fileSeparator) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// revalidate refresh cache entry in background. On failure stale entry is kept until it is no longer served
+func (c *Cache) revalidate(key string, req *Request) {
+	// fetch using copy of the request, since fetch updates request URL (default path and redirects)
+	r := *req
+	res, err := c.fetch(&r)
+	if err == nil {
+		c.Set(res)
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.revalidating = false
+	}
+}
+
+// key return cache key of Ads.txt request fetched by cache crawler: crawler default file path is applied to the
+// request URL first, the same way the crawler does, so the key matches the key of the fetched response
+func (c *Cache) key(req *Request) string {
+	if c.path == nil {
+		return cacheKey(req)
+	}
+	path := c.path(req)
+	if len(path) == 0 {
+		return cacheKey(req)
+	}
+	r := *req
+	r.defaultPath(path)
+	return cacheKey(&r)
+}
+
+// cacheKey return cache key of Ads.txt request: the request registrable (root) domain file, scoped by tenant
+func cacheKey(req *Request) string {
+	return tenantKey(req.Tenant, FileKey(req.Domain.String(), req.URL))
+}
@@ -0,0 +1,217 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCacheJitter test cache entry refresh date is jittered within Ads.txt file expiration date
+func TestCacheJitter(t *testing.T) {
+	now := time.Now()
+
+	c := NewCache(0.5, 0)
+	c.now = func() time.Time { return now }
+	c.random = func() float64 { return 0.5 }
+
+	res := &Response{Request: &Request{Domain: "example.com"}, Expires: newExpiration(now.Add(8*time.Hour), ExpiresSourceHeader, now)}
+	c.Set(res)
+
+	e := c.entries["example.com"]
+	if expected := now.Add(6 * time.Hour); !e.refreshAt.Equal(expected) {
+		t.Errorf("Expected entry refresh date to be [%s] and not [%s]", expected, e.refreshAt)
+	}
+}
+
+// TestCacheStaleWhileRevalidate test expired entries are served while being refreshed in background
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	fetched := 0
+
+	c := NewCache(0, time.Hour)
+	c.now = func() time.Time {
+		lock.Lock()
+		defer lock.Unlock()
+		return now
+	}
+	c.fetch = func(req *Request) (*Response, error) {
+		defer wg.Done()
+		lock.Lock()
+		defer lock.Unlock()
+		fetched++
+		return &Response{Request: req, Expires: newExpiration(now.Add(time.Duration(fetched)*time.Minute), ExpiresSourceHeader, now)}, nil
+	}
+
+	req := &Request{Domain: "example.com", URL: "http://example.com/ads.txt"}
+
+	// first request fetch the file, second is served from cache
+	wg.Add(1)
+	first, _ := c.Get(req)
+	second, _ := c.Get(req)
+	if first != second || fetched != 1 {
+		t.Errorf("Expected second request to be served from cache, fetched [%d] times", fetched)
+	}
+
+	// expired entry is served stale and refreshed once in background
+	lock.Lock()
+	now = now.Add(2 * time.Minute)
+	lock.Unlock()
+	wg.Add(1)
+	stale, _ := c.Get(req)
+	c.Get(req)
+	if stale != first {
+		t.Error("Expected expired entry to be served within stale-while-revalidate window")
+	}
+	wg.Wait()
+
+	// wait for background refresh to update the cache
+	for revalidating := true; revalidating; time.Sleep(time.Millisecond) {
+		c.lock.Lock()
+		revalidating = c.entries["example.com"].revalidating
+		c.lock.Unlock()
+	}
+
+	lock.Lock()
+	if fetched != 2 {
+		t.Errorf("Expected single background refresh, fetched [%d] times", fetched)
+	}
+
+	// entry expired beyond stale-while-revalidate window is fetched synchronously
+	now = now.Add(2 * time.Hour)
+	lock.Unlock()
+	wg.Add(1)
+	c.Get(req)
+	wg.Wait()
+	if fetched != 3 {
+		t.Errorf("Expected expired entry to be fetched again, fetched [%d] times", fetched)
+	}
+}
+
+// TestCacheFiles test responses of different files of the same domain are cached separately
+func TestCacheFiles(t *testing.T) {
+	now := time.Now()
+	c := NewCache(0, 0)
+	c.now = func() time.Time { return now }
+	c.fetch = func(req *Request) (*Response, error) {
+		return &Response{Request: req, Expires: newExpiration(now.Add(time.Hour), ExpiresSourceHeader, now)}, nil
+	}
+
+	files := []string{"http://example.com/ads.txt", "http://example.com/app-ads.txt", "http://sub.example.com/ads.txt"}
+	for _, u := range files {
+		c.Get(&Request{Domain: "example.com", URL: u})
+	}
+	for _, u := range files {
+		if res, _ := c.Get(&Request{Domain: "example.com", URL: u}); res.Request.URL != u {
+			t.Errorf("Expected cached response of [%s] and not [%s]", u, res.Request.URL)
+		}
+	}
+
+	// https and www. variants locate the same file
+	if res, _ := c.Get(&Request{Domain: "example.com", URL: "https://www.example.com/ads.txt"}); res.Request.URL != files[0] {
+		t.Errorf("Expected cached response of [%s] and not [%s]", files[0], res.Request.URL)
+	}
+
+	c.Delete("example.com")
+	if len(c.entries) != 0 {
+		t.Errorf("Expected all domain files to be deleted and not [%d]", len(c.entries))
+	}
+}
+
+// TestCacheCrawler test cache misses are fetched using cache crawler
+func TestCacheCrawler(t *testing.T) {
+	var agent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	crawler := NewCrawler()
+	crawler.UserAgent = "cache-crawler"
+
+	req, _ := NewRequest(ts.URL)
+	if _, err := NewCache(0, 0, WithCacheCrawler(crawler)).Get(req); err != nil || agent != "cache-crawler" {
+		t.Errorf("Expected file to be fetched by cache crawler and not [%s] [%v]", agent, err)
+	}
+}
+
+// TestCacheDefaultPath test files fetched by crawler of custom default path are served from cache
+func TestCacheDefaultPath(t *testing.T) {
+	fetched := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/ads.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		fetched++
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	c := NewCache(0, 0, WithCacheCrawler(NewCrawler(WithDefaultPath("/.well-known/ads.txt"))))
+	for i := 0; i < 3; i++ {
+		req, _ := NewRequest(ts.URL)
+		if _, err := c.Get(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetched != 1 {
+		t.Errorf("Expected file to be fetched once and served from cache, fetched [%d] times", fetched)
+	}
+}
+
+// TestCacheConcurrentMisses test concurrent misses of the same entry are fetched once, and the caller request is
+// not updated by the fetch
+func TestCacheConcurrentMisses(t *testing.T) {
+	now := time.Now()
+
+	var lock sync.Mutex
+	fetched := 0
+	release := make(chan struct{})
+
+	c := NewCache(0, 0)
+	c.now = func() time.Time { return now }
+	c.fetch = func(req *Request) (*Response, error) {
+		lock.Lock()
+		fetched++
+		lock.Unlock()
+		<-release
+		req.URL = "http://www.example.com/ads.txt"
+		return &Response{Request: req, Expires: newExpiration(now.Add(time.Hour), ExpiresSourceHeader, now)}, nil
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	responses := make([]*Response, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := &Request{Domain: "example.com", URL: "http://example.com/ads.txt"}
+			responses[i], _ = c.Get(req)
+			if req.URL != "http://example.com/ads.txt" {
+				t.Errorf("Expected caller request URL not to be updated and not [%s]", req.URL)
+			}
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if fetched != 1 {
+		t.Errorf("Expected concurrent misses to be fetched once, fetched [%d] times", fetched)
+	}
+	for i, res := range responses {
+		if res == nil || res != responses[0] {
+			t.Errorf("[%d] Expected concurrent misses to share the fetched response", i)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package adstxt
+
+import (
+	"sort"
+	"strings"
+)
+
+// SupplyPath single path from publisher Ads.txt data record to the ad system, through sellers.json intermediaries
+type SupplyPath struct {
+	AdSystem       string   `json:"adSystem"`       // AdSystem domain of the ad system declared in the Ads.txt record
+	AccountID      string   `json:"accountId"`      // AccountID seller account ID declared in the Ads.txt record
+	AccountType    string   `json:"accountType"`    // AccountType DIRECT or RESELLER as declared in the Ads.txt record
+	Intermediaries []string `json:"intermediaries"` // Intermediaries seller domains between the publisher and the ad system
+	Depth          int      `json:"depth"`          // Depth number of intermediaries
+	Resolved       bool     `json:"resolved"`       // Resolved path reached the publisher, else depth is a lower bound
+}
+
+// ChainReport reseller chain depth of single publisher
+type ChainReport struct {
+	Domain   string        `json:"domain"`   // Domain publisher root domain
+	Paths    []*SupplyPath `json:"paths"`    // Paths supply path of each publisher data record
+	MinDepth int           `json:"minDepth"` // MinDepth minimum number of intermediaries across publisher paths
+	Flagged  bool          `json:"flagged"`  // Flagged publisher inventory is only reachable through threshold or more intermediaries
+}
+
+// ChainDepth compute reseller chain depth of each publisher, based on crawled Ads.txt responses and sellers.json files
+// (keyed by ad system domain). Intermediaries are followed through their own sellers.json file, when available.
+// Publishers whose every path has at least threshold intermediaries are flagged. Reports are sorted by publisher domain
+func ChainDepth(responses []*Response, sellers map[string]*Sellers, threshold int) []*ChainReport {
+	reports := []*ChainReport{}
+	for _, res := range responses {
+		if res == nil || res.Request == nil || res.Records == nil {
+			continue
+		}
+
+		r := &ChainReport{Domain: res.Request.Domain.String(), Paths: []*SupplyPath{}, MinDepth: -1}
+		for _, dr := range res.DataRecords {
+			p := supplyPath(r.Domain, dr, sellers)
+			r.Paths = append(r.Paths, p)
+			if r.MinDepth == -1 || p.Depth < r.MinDepth {
+				r.MinDepth = p.Depth
+			}
+		}
+		r.Flagged = len(r.Paths) > 0 && r.MinDepth >= threshold
+
+		reports = append(reports, r)
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Domain < reports[j].Domain
+	})
+
+	return reports
+}
+
+// supplyPath walk sellers.json files from the ad system declared in Ads.txt record until publisher is reached
+func supplyPath(publisher string, dr *DataRecord, sellers map[string]*Sellers) *SupplyPath {
+	p := &SupplyPath{
+		AdSystem:       dr.AdverterDomain.String(),
+		AccountID:      dr.PublisherAccountID,
+		AccountType:    dr.AccountType,
+		Intermediaries: []string{},
+	}
+
+	s, ok := sellers[strings.ToLower(dr.AdverterDomain.String())]
+	if !ok {
+		return p
+	}
+
+	seller := s.Seller(dr.PublisherAccountID)
+	visited := map[string]bool{s.AdSystem: true}
+	for seller != nil {
+		// seller owns the inventory (confidential sellers can't be followed, and are assumed to be the publisher)
+		if seller.SellerType == SellerTypePublisher || (seller.SellerType == SellerTypeBoth && (len(seller.Domain) == 0 || sameRootDomain(seller.Domain, publisher))) {
+			p.Resolved = true
+			return p
+		}
+
+		d := strings.ToLower(seller.Domain)
+		if len(d) == 0 {
+			return p
+		}
+		p.Intermediaries = append(p.Intermediaries, d)
+		p.Depth++
+
+		// follow intermediary sellers.json file, looking for the publisher
+		next, ok := sellers[d]
+		if !ok || visited[d] {
+			return p
+		}
+		visited[d] = true
+
+		seller = nil
+		for _, i := range next.Sellers {
+			if sameRootDomain(i.Domain, publisher) {
+				seller = i
+				break
+			}
+		}
+	}
+
+	return p
+}
+
+// sameRootDomain check if both domains share the same root domain
+func sameRootDomain(a string, b string) bool {
+	ra, err := RootDomain(a)
+	if err != nil {
+		return false
+	}
+	rb, err := RootDomain(b)
+	if err != nil {
+		return false
+	}
+	return ra == rb
+}
@@ -0,0 +1,60 @@
+package adstxt
+
+import (
+	"testing"
+)
+
+// TestChainDepth test reseller chain depth through sellers.json intermediaries
+func TestChainDepth(t *testing.T) {
+	sellers := map[string]*Sellers{
+		"greenadexchange.com": &Sellers{AdSystem: "greenadexchange.com", Sellers: []*Seller{
+			{SellerID: "1", Domain: "example.com", SellerType: SellerTypePublisher},
+			{SellerID: "2", Domain: "reseller.com", SellerType: SellerTypeIntermediary},
+			{SellerID: "3", Domain: "unknown.com", SellerType: SellerTypeIntermediary},
+		}},
+		"reseller.com": &Sellers{AdSystem: "reseller.com", Sellers: []*Seller{
+			{SellerID: "10", Domain: "other.com", SellerType: SellerTypeIntermediary},
+			{SellerID: "11", Domain: "www.test.com", SellerType: SellerTypeBoth},
+		}},
+	}
+
+	responses := []*Response{
+		{Request: &Request{Domain: "test.com"}, Records: &Records{DataRecords: []*DataRecord{
+			{AdverterDomain: "greenadexchange.com", PublisherAccountID: "2", AccountType: accountTypeReseller},
+			{AdverterDomain: "greenadexchange.com", PublisherAccountID: "3", AccountType: accountTypeReseller},
+		}}},
+		{Request: &Request{Domain: "example.com"}, Records: &Records{DataRecords: []*DataRecord{
+			{AdverterDomain: "greenadexchange.com", PublisherAccountID: "1", AccountType: accountTypeDirect},
+			{AdverterDomain: "greenadexchange.com", PublisherAccountID: "2", AccountType: accountTypeReseller},
+		}}},
+	}
+
+	reports := ChainDepth(responses, sellers, 1)
+	if len(reports) != 2 {
+		t.Fatalf("Expected [2] reports and not [%d]", len(reports))
+	}
+
+	expected := map[string]struct {
+		minDepth int
+		flagged  bool
+	}{
+		"example.com": {0, false},
+		"test.com":    {1, true},
+	}
+	for _, r := range reports {
+		e := expected[r.Domain]
+		if r.MinDepth != e.minDepth || r.Flagged != e.flagged {
+			t.Errorf("[%s] Expected min depth [%d] flagged [%t] and not [%d] [%t]", r.Domain, e.minDepth, e.flagged, r.MinDepth, r.Flagged)
+		}
+	}
+
+	// example.com reseller path goes through reseller.com, which does not declare example.com
+	p := reports[0].Paths[1]
+	if p.Resolved || p.Depth != 1 || p.Intermediaries[0] != "reseller.com" {
+		t.Errorf("Expected unresolved path through [reseller.com] and not %v resolved [%t]", p.Intermediaries, p.Resolved)
+	}
+	// test.com path reach the publisher through reseller.com
+	if p := reports[1].Paths[0]; !p.Resolved || p.Depth != 1 {
+		t.Errorf("Expected resolved path of depth [1] and not [%d] resolved [%t]", p.Depth, p.Resolved)
+	}
+}
@@ -0,0 +1,52 @@
+//go:build js && wasm
+
+// Command adstxt-wasm expose the Ads.txt parser to JavaScript (browser tooling, Node.js), so it uses the exact same
+// parser semantics. Build with:
+//
+//	GOOS=js GOARCH=wasm go build -o adstxt.wasm ./cmd/adstxt-wasm
+//
+// Once loaded (see wasm_exec.js of the Go distribution), the module registers global adstxtParse(body) function
+// returning JSON encoded records, or {"error": "..."} on failure.
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/ehulsbosch/go-adstxt-crawler"
+)
+
+// result JSON envelope of the exposed functions (same as the C shared library)
+type result struct {
+	Records *adstxt.Records `json:"records,omitempty"` // Records parsed Ads.txt records and warnings
+	Error   string          `json:"error,omitempty"`   // Error parsing failure
+}
+
+// parse Ads.txt file content passed as first argument
+func parse(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return encode(result{Error: "adstxtParse expects single string argument"})
+	}
+
+	records, err := adstxt.ParseBody([]byte(args[0].String()))
+	if err != nil {
+		return encode(result{Error: err.Error()})
+	}
+	return encode(result{Records: records})
+}
+
+// encode JSON encode result
+func encode(r result) string {
+	b, err := json.Marshal(r)
+	if err != nil {
+		b, _ = json.Marshal(result{Error: err.Error()})
+	}
+	return string(b)
+}
+
+func main() {
+	js.Global().Set("adstxtParse", js.FuncOf(parse))
+
+	// keep the module running so exposed function can be called
+	select {}
+}
@@ -0,0 +1,72 @@
+// Command libadstxt is a C shared library exposing the Ads.txt parser to non Go systems (Python, Node.js etc), so
+// they use the exact same parser semantics. Build with:
+//
+//	go build -buildmode=c-shared -o libadstxt.so ./cmd/libadstxt
+//
+// All functions return JSON encoded results as NUL terminated strings allocated by the library, which must be
+// released by the caller using adstxt_free.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"unsafe"
+
+	"github.com/ehulsbosch/go-adstxt-crawler"
+)
+
+// abiVersion version of the library C ABI, bumped on any incompatible change
+const abiVersion = 1
+
+// result JSON envelope of all library functions
+type result struct {
+	Records *adstxt.Records `json:"records,omitempty"` // Records parsed Ads.txt records and warnings
+	Error   string          `json:"error,omitempty"`   // Error parsing failure
+}
+
+// adstxt_parse parse (and validate) Ads.txt file content of length bytes. Return JSON encoded records, including
+// validation warnings
+//
+//export adstxt_parse
+func adstxt_parse(body *C.char, length C.int) *C.char {
+	// C.GoBytes panics on negative length, and NULL body of non zero length would crash the caller process
+	if length < 0 || (body == nil && length > 0) {
+		return encode(result{Error: fmt.Sprintf("invalid Ads.txt body of length [%d]", length)})
+	}
+
+	records, err := adstxt.ParseBody(C.GoBytes(unsafe.Pointer(body), length))
+	if err != nil {
+		return encode(result{Error: err.Error()})
+	}
+	return encode(result{Records: records})
+}
+
+// adstxt_abi_version return library C ABI version
+//
+//export adstxt_abi_version
+func adstxt_abi_version() C.int {
+	return abiVersion
+}
+
+// adstxt_free release string returned by library function
+//
+//export adstxt_free
+func adstxt_free(p *C.char) {
+	C.free(unsafe.Pointer(p))
+}
+
+// encode JSON encode result into C string
+func encode(r result) *C.char {
+	b, err := json.Marshal(r)
+	if err != nil {
+		b, _ = json.Marshal(result{Error: err.Error()})
+	}
+	return C.CString(string(b))
+}
+
+func main() {}
@@ -0,0 +1,176 @@
+package adstxt
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Codec serialize Ads.txt results (responses, records etc) for storage or transport
+type Codec interface {
+	Name() string                               // Name of the encoding (json, msgpack, protobuf)
+	Marshal(v interface{}) ([]byte, error)      // Marshal return the encoding of v
+	Unmarshal(data []byte, v interface{}) error // Unmarshal parse encoded data and store the result in the value pointed to by v
+}
+
+// Supported codecs. JSON is the only interchange format. Binary codecs (msgpack and protobuf) are internal compact
+// storage formats of this package: structs are encoded by field position instead of field name, fields which are
+// encoded by the JSON codec are numbered in declaration order, and there is no published schema. Fields of stored
+// types must only be added after existing ones (never reordered, inserted or removed), otherwise results stored
+// earlier are decoded into wrong fields. Values of interface type (e.g. map[string]interface{}) are encoded with
+// their names, as their type is not known on decoding. Use JSONCodec for results read by other tools
+var (
+	// JSONCodec encode results as JSON
+	JSONCodec Codec = jsonCodec{}
+	// MsgpackCodec encode results using MessagePack (https://msgpack.org) wire format, structs are encoded as arrays
+	// of field values. Internal format, see above
+	MsgpackCodec Codec = msgpackCodec{}
+	// ProtobufCodec encode results using protocol buffers wire format, struct field number is its position. There is
+	// no .proto schema, so the output is not meant to be read by other protocol buffers tools. Values of interface
+	// type are encoded as google.protobuf.Value message (see struct.proto well known type). Empty and nil slices and
+	// maps are not distinguished. Internal format, see above
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+// maxCodecDepth maximum nesting depth of values decoded by binary codecs, so corrupted data can't exhaust the stack
+const maxCodecDepth = 100
+
+// errCodecDepth encoded value nesting is deeper than maxCodecDepth
+var errCodecDepth = fmt.Errorf("codec: maximum nesting depth [%d] exceeded", maxCodecDepth)
+
+// CodecByName return supported codec by its name, ".gz" suffix return gzip compressed codec (e.g. "json.gz")
+func CodecByName(name string) (Codec, error) {
+	for _, c := range []Codec{JSONCodec, MsgpackCodec, ProtobufCodec} {
+		if c.Name() == name {
+			return c, nil
+		}
+		if c.Name()+gzipExtension == name {
+			return GzipCodec(c), nil
+		}
+	}
+	return nil, fmt.Errorf("[%s] is not a supported codec", name)
+}
+
+// jsonCodec JSON encoding
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// toDocument convert v into generic document model (nil, bool, json.Number, string, []interface{} and
+// map[string]interface{}) using v JSON encoding
+func toDocument(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// sortedKeys return document object keys sorted, so encoding output is deterministic
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// codecFieldsCache encoded field indexes by struct type
+var codecFieldsCache sync.Map
+
+// codecFields return indexes of struct fields encoded by binary codecs, in declaration order: exported fields
+// (embedded structs included, as nested value) which are not skipped by the JSON codec (json:"-")
+func codecFields(t reflect.Type) []int {
+	if fields, ok := codecFieldsCache.Load(t); ok {
+		return fields.([]int)
+	}
+
+	fields := []int{}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() && f.Tag.Get("json") != "-" {
+			fields = append(fields, i)
+		}
+	}
+	codecFieldsCache.Store(t, fields)
+	return fields
+}
+
+// Marshaler interfaces of values encoded as bytes by binary codecs (e.g. time.Time)
+var (
+	binaryMarshalerType   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	textMarshalerType     = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bytes encodings of marshaler values
+const (
+	bytesNone = iota
+	bytesBinary
+	bytesText
+)
+
+// bytesEncoding return bytes encoding of type values: binary or text marshaler implemented by the type (with the
+// matching unmarshaler), or bytesNone
+func bytesEncoding(t reflect.Type) int {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface {
+		return bytesNone
+	}
+
+	pt := reflect.PointerTo(t)
+	switch {
+	case t.Implements(binaryMarshalerType) && pt.Implements(binaryUnmarshalerType):
+		return bytesBinary
+	case t.Implements(textMarshalerType) && pt.Implements(textUnmarshalerType):
+		return bytesText
+	}
+	return bytesNone
+}
+
+// marshalBytes return bytes encoding of marshaler value
+func marshalBytes(v reflect.Value) ([]byte, error) {
+	if bytesEncoding(v.Type()) == bytesBinary {
+		return v.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+	}
+	return v.Interface().(encoding.TextMarshaler).MarshalText()
+}
+
+// unmarshalBytes decode bytes encoding into addressable marshaler value
+func unmarshalBytes(v reflect.Value, b []byte) error {
+	if bytesEncoding(v.Type()) == bytesBinary {
+		return v.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+	}
+	return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(b)
+}
+
+// decodeTarget return value pointed to by v, which is decoded by binary codecs
+func decodeTarget(name string, v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return reflect.Value{}, fmt.Errorf("%s: decode target must be non-nil pointer and not %T", name, v)
+	}
+	return rv.Elem(), nil
+}
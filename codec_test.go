@@ -0,0 +1,200 @@
+package adstxt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCodecRoundTrip test encoding and decoding Ads.txt response with all supported codecs
+func TestCodecRoundTrip(t *testing.T) {
+	rec, err := ParseBody([]byte("greenadexchange.com,XF7342,direct\nsubdomain=dev.example.com\nnot a valid line"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := &Response{
+		Request: &Request{Domain: "example.com", URL: "http://example.com/ads.txt"},
+		Records: rec,
+		Expires: newExpiration(time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC), ExpiresSourceHeader, time.Time{}),
+	}
+
+	expected, _ := json.Marshal(res)
+
+	for _, name := range []string{"json", "msgpack", "protobuf"} {
+		c, err := CodecByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := c.Marshal(res)
+		if err != nil {
+			t.Errorf("[%s] failed to encode response [%s]", name, err.Error())
+			continue
+		}
+
+		decoded := &Response{}
+		if err := c.Unmarshal(b, decoded); err != nil {
+			t.Errorf("[%s] failed to decode response [%s]", name, err.Error())
+			continue
+		}
+
+		if j, _ := json.Marshal(decoded); !bytes.Equal(j, expected) {
+			t.Errorf("[%s] expected decoded response to be [%s] and not [%s]", name, expected, j)
+		}
+	}
+
+	if _, err := CodecByName("xml"); err == nil {
+		t.Error("Expected error for unsupported codec")
+	}
+}
+
+// TestCodecWireFormat test binary codecs output against known encodings
+func TestCodecWireFormat(t *testing.T) {
+	doc := map[string]interface{}{"a": "b", "n": 1}
+
+	// {"a":"b","n":1} as MessagePack fixmap with fixstr keys and positive fixint
+	msgpack := []byte{0x82, 0xa1, 'a', 0xa1, 'b', 0xa1, 'n', 0x01}
+	if b, _ := MsgpackCodec.Marshal(doc); !bytes.Equal(b, msgpack) {
+		t.Errorf("Expected msgpack encoding to be [% x] and not [% x]", msgpack, b)
+	}
+
+	// struct as MessagePack fixarray of field values, negative fixint
+	record := struct {
+		Domain string
+		N      int
+	}{"ab", -1}
+	msgpack = []byte{0x92, 0xa2, 'a', 'b', 0xff}
+	if b, _ := MsgpackCodec.Marshal(record); !bytes.Equal(b, msgpack) {
+		t.Errorf("Expected msgpack encoding to be [% x] and not [% x]", msgpack, b)
+	}
+
+	// struct as message of field 1 (string) and field 2 (zigzag varint)
+	protobuf := []byte{0x0a, 0x02, 'a', 'b', 0x10, 0x01}
+	if b, _ := ProtobufCodec.Marshal(record); !bytes.Equal(b, protobuf) {
+		t.Errorf("Expected protobuf encoding to be [% x] and not [% x]", protobuf, b)
+	}
+
+	// top level value as field 1 of message
+	protobuf = []byte{0x0a, 0x03, 'a', 'd', 's'}
+	if b, _ := ProtobufCodec.Marshal("ads"); !bytes.Equal(b, protobuf) {
+		t.Errorf("Expected protobuf encoding to be [% x] and not [% x]", protobuf, b)
+	}
+
+	// map entry of key (field 1) and google.protobuf.Value{string_value: "b"} (field 2)
+	protobuf = []byte{0x0a, 0x08, 0x0a, 0x01, 'a', 0x12, 0x03, 0x1a, 0x01, 'b'}
+	if b, _ := ProtobufCodec.Marshal(map[string]interface{}{"a": "b"}); !bytes.Equal(b, protobuf) {
+		t.Errorf("Expected protobuf encoding to be [% x] and not [% x]", protobuf, b)
+	}
+}
+
+// TestCodecFieldOrder test field positions of stored types, which are used by binary codecs instead of field names.
+// Results stored by binary codecs are decoded into wrong fields when these change: new fields must be appended
+func TestCodecFieldOrder(t *testing.T) {
+	expected := map[reflect.Type][]string{
+		reflect.TypeOf(Response{}):   {"Request", "Records", "Expires", "Headers", "Redirects", "Security", "Score", "Timings", "Annotations", "ETag", "LastModified", "Unchanged"},
+		reflect.TypeOf(Snapshot{}):   {"Domain", "Tenant", "CrawledAt", "Digest", "Response", "Diff", "ETag", "LastModified", "URL"},
+		reflect.TypeOf(DataRecord{}): {"AdverterDomain", "PublisherAccountID", "AccountType", "CertAuthorityID", "Flags", "OriginalAdverterDomain", "OriginalPublisherAccountID"},
+	}
+	for typ, names := range expected {
+		fields := []string{}
+		for _, i := range codecFields(typ) {
+			fields = append(fields, typ.Field(i).Name)
+		}
+		if strings.Join(fields, ",") != strings.Join(names, ",") {
+			t.Errorf("[%s] Expected encoded fields %v and not %v", typ.Name(), names, fields)
+		}
+	}
+}
+
+// TestCodecSize test binary codecs encode responses more compactly than JSON
+func TestCodecSize(t *testing.T) {
+	body := &strings.Builder{}
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(body, "greenadexchange.com,XF%d,DIRECT,d75815a79\n", i)
+	}
+	rec, err := ParseBody([]byte(body.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := &Response{Request: &Request{Domain: "example.com", URL: "http://example.com/ads.txt"}, Records: rec}
+
+	j, _ := JSONCodec.Marshal(res)
+	for _, c := range []Codec{MsgpackCodec, ProtobufCodec} {
+		b, err := c.Marshal(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b)*3 > len(j)*2 {
+			t.Errorf("[%s] expected encoding to be smaller than 2/3 of JSON [%d] and not [%d]", c.Name(), len(j), len(b))
+		}
+	}
+}
+
+// TestCodecIntegers test integers are decoded without loss of precision
+func TestCodecIntegers(t *testing.T) {
+	type numbers struct {
+		I   int64
+		U   uint64
+		Neg int8
+	}
+	expected := numbers{I: 1<<62 + 1, U: math.MaxUint64, Neg: -100}
+
+	for _, c := range []Codec{MsgpackCodec, ProtobufCodec} {
+		b, err := c.Marshal(expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded numbers
+		if err := c.Unmarshal(b, &decoded); err != nil || decoded != expected {
+			t.Errorf("[%s] expected integers to be decoded as [%+v] and not [%+v] [%v]", c.Name(), expected, decoded, err)
+		}
+
+		// overflowing target type is an error
+		var small struct{ I int8 }
+		if err := c.Unmarshal(b, &small); err == nil {
+			t.Errorf("[%s] expected error decoding into overflowing integer", c.Name())
+		}
+	}
+
+	var i interface{}
+	b, _ := MsgpackCodec.Marshal(uint64(math.MaxUint64))
+	if err := MsgpackCodec.Unmarshal(b, &i); err != nil || i != uint64(math.MaxUint64) {
+		t.Errorf("Expected msgpack integer to be decoded as uint64 and not [%T] [%v]", i, err)
+	}
+}
+
+// TestCodecDepth test deeply nested data is rejected instead of exhausting the stack
+func TestCodecDepth(t *testing.T) {
+	// MessagePack nested single item arrays
+	msgpack := bytes.Repeat([]byte{0x91}, 100000)
+	var v interface{}
+	if err := MsgpackCodec.Unmarshal(msgpack, &v); err == nil {
+		t.Error("Expected msgpack nesting depth error")
+	}
+
+	// google.protobuf.Value nested list values, built from the innermost value
+	value := []byte{0x08, 0x00}
+	for i := 0; i < 1000; i++ {
+		value = pbAppendBytes(nil, pbValueList, pbAppendBytes(nil, 1, value))
+	}
+	if err := ProtobufCodec.Unmarshal(pbAppendBytes(nil, 1, value), &v); err == nil {
+		t.Error("Expected protobuf nesting depth error")
+	}
+
+	type node struct{ Next *node }
+	deep := &node{}
+	for i := 0; i < 1000; i++ {
+		deep = &node{Next: deep}
+	}
+	for _, c := range []Codec{MsgpackCodec, ProtobufCodec} {
+		if _, err := c.Marshal(deep); err == nil {
+			t.Errorf("[%s] expected nesting depth error on encoding", c.Name())
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package adstxt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// gzipExtension name suffix of gzip compressed codecs
+const gzipExtension = ".gz"
+
+// MaxDecompressedSize maximum size of data decompressed by Decompress (bytes), so corrupt or malicious data can't
+// exhaust memory. Large exports should be read as stream (gzip.NewReader) instead
+const MaxDecompressedSize = 256 << 20
+
+// errDecompressedTooLarge decompressed data exceeds MaxDecompressedSize
+const errDecompressedTooLarge = "decompressed data is larger than [%d] bytes"
+
+// gzipMagic first bytes of gzip stream (RFC 1952)
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Compress gzip compress data (e.g. raw Ads.txt body before storage). Compression is gzip only: zstd is out of
+// scope, since it is not in the standard library, while gzip is what web archive tooling (.warc.gz) and data
+// warehouses load natively
+func Compress(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Decompress gzip compressed data, data which is not gzip compressed is returned as is. Data stored before
+// compression was enabled can be read transparently. Decompressed data larger than MaxDecompressedSize is an error
+func Decompress(data []byte) ([]byte, error) {
+	return decompress(data, MaxDecompressedSize)
+}
+
+// decompress gzip compressed data up to max bytes
+func decompress(data []byte, max int) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	b, err := io.ReadAll(io.LimitReader(gz, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > max {
+		return nil, fmt.Errorf(errDecompressedTooLarge, max)
+	}
+	return b, nil
+}
+
+// GzipCodec wrap codec to gzip compress encoded results, e.g. NewFileStore(dir, GzipCodec(JSONCodec)) store domain
+// snapshots in compressed "json.gz" files. Uncompressed encoding is accepted on decoding, so file store still reads
+// existing "json" files (which are replaced by compressed file on next write)
+func GzipCodec(c Codec) Codec {
+	return gzipCodec{codec: c}
+}
+
+// gzipCodec gzip compressed encoding of wrapped codec
+type gzipCodec struct {
+	codec Codec
+}
+
+func (g gzipCodec) Name() string {
+	return g.codec.Name() + gzipExtension
+}
+
+func (g gzipCodec) Marshal(v interface{}) ([]byte, error) {
+	b, err := g.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Compress(b)
+}
+
+func (g gzipCodec) Unmarshal(data []byte, v interface{}) error {
+	b, err := Decompress(data)
+	if err != nil {
+		return err
+	}
+	return g.codec.Unmarshal(b, v)
+}
+
+// NewGzipWARCWriter create new WARC writer writing each record to w as separate gzip member (standard .warc.gz
+// format), so archive tooling can seek to single record
+func NewGzipWARCWriter(w io.Writer) *WARCWriter {
+	return &WARCWriter{w: w, gzip: true}
+}
+
+// NewGzipExporter create new CSV exporter writing gzip compressed rows to w, exporter must be closed to write the
+// end of compressed stream
+func NewGzipExporter(w io.Writer) *Exporter {
+	gz := gzip.NewWriter(w)
+	e := NewExporter(gz)
+	e.gz = gz
+	return e
+}
@@ -0,0 +1,157 @@
+package adstxt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCompress test compressed data is restored, and uncompressed data is read as is
+func TestCompress(t *testing.T) {
+	body := []byte(strings.Repeat("greenadexchange.com,XF7342,DIRECT\n", 100))
+
+	compressed, err := Compress(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compressed) >= len(body)/10 {
+		t.Errorf("Expected Ads.txt body to compress well and not to [%d] of [%d] bytes", len(compressed), len(body))
+	}
+
+	for name, data := range map[string][]byte{"compressed": compressed, "plain": body} {
+		if b, err := Decompress(data); err != nil || !bytes.Equal(b, body) {
+			t.Errorf("[%s] Expected body to be restored [%v]", name, err)
+		}
+	}
+
+	// decompressed size is limited
+	if b, err := decompress(compressed, len(body)); err != nil || !bytes.Equal(b, body) {
+		t.Errorf("Expected body of maximum size to be restored [%v]", err)
+	}
+	if _, err := decompress(compressed, len(body)-1); err == nil {
+		t.Error("Expected error for body larger than maximum decompressed size")
+	}
+}
+
+// TestGzipCodecStore test file store of domain snapshots encoded using compressed codec
+func TestGzipCodecStore(t *testing.T) {
+	codec, err := CodecByName("msgpack.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	s, err := NewFileStore(dir, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT"))
+	res := &Response{Request: &Request{Domain: "example.com", URL: "http://example.com/ads.txt"}, Records: rec}
+	if err := s.Put(NewSnapshot(res, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "example.com.msgpack.gz"))
+	if err != nil || !bytes.HasPrefix(b, gzipMagic) {
+		t.Fatalf("Expected compressed domain file [%v]", err)
+	}
+
+	domains, _ := s.Domains()
+	snapshots, err := s.Snapshots("example.com")
+	if err != nil || len(domains) != 1 || len(snapshots) != 1 || len(snapshots[0].Response.DataRecords) != 1 {
+		t.Errorf("Expected compressed snapshot to be read back [%v] [%v]", domains, err)
+	}
+
+	// uncompressed encoding is accepted
+	var v map[string]string
+	if err := GzipCodec(JSONCodec).Unmarshal([]byte(`{"domain":"example.com"}`), &v); err != nil || v["domain"] != "example.com" {
+		t.Errorf("Expected uncompressed encoding to be decoded [%v]", err)
+	}
+}
+
+// TestGzipCodecStoreMigration test file store switched to compressed codec reads existing uncompressed files
+func TestGzipCodecStoreMigration(t *testing.T) {
+	dir := t.TempDir()
+	plain, _ := NewFileStore(dir, JSONCodec)
+
+	rec, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT"))
+	res := &Response{Request: &Request{Domain: "example.com", URL: "http://example.com/ads.txt"}, Records: rec}
+	plain.Put(NewSnapshot(res, time.Now()))
+	plain.UpdateHealth("example.com", func(h *DomainHealth) { h.Failures = 2 })
+
+	s, _ := NewFileStore(dir, GzipCodec(JSONCodec))
+	domains, _ := s.Domains()
+	snapshots, err := s.Snapshots("example.com")
+	if err != nil || len(domains) != 1 || len(snapshots) != 1 {
+		t.Fatalf("Expected uncompressed snapshot to be read [%v] [%v]", domains, err)
+	}
+	if h, err := s.Health("example.com"); err != nil || h == nil || h.Failures != 2 {
+		t.Errorf("Expected uncompressed health entry to be read [%+v] [%v]", h, err)
+	}
+
+	// next write replace uncompressed file
+	if err := s.Put(NewSnapshot(res, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "example.com.json")); err == nil {
+		t.Error("Expected uncompressed file to be removed once compressed file is written")
+	}
+	domains, _ = s.Domains()
+	snapshots, err = s.Snapshots("example.com")
+	if err != nil || len(domains) != 1 || len(snapshots) != 2 {
+		t.Errorf("Expected both snapshots in compressed file [%v] [%d] [%v]", domains, len(snapshots), err)
+	}
+}
+
+// TestGzipWARCAndExport test compressed WARC records and export rows
+func TestGzipWARCAndExport(t *testing.T) {
+	var b bytes.Buffer
+	w := NewGzipWARCWriter(&b)
+	w.WriteInfo([][2]string{{"software", userAgent}})
+	w.WriteExchange("http://example.com/ads.txt", time.Now(), []byte("GET /ads.txt HTTP/1.1\r\n\r\n"), []byte("HTTP/1.1 200 OK\r\n\r\n"), nil)
+
+	// each record is separate gzip member
+	members := 0
+	gz, err := gzip.NewReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz.Multistream(false)
+	for {
+		data, err := io.ReadAll(gz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(data, []byte(warcVersion)) {
+			t.Errorf("Expected gzip member [%d] to hold single WARC record", members)
+		}
+		members++
+		if err := gz.Reset(&b); err == io.EOF {
+			break
+		}
+		gz.Multistream(false)
+	}
+	if members != 3 {
+		t.Errorf("Expected [3] WARC records and not [%d]", members)
+	}
+
+	var out bytes.Buffer
+	e := NewGzipExporter(&out)
+	rec, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT"))
+	e.WriteHeader()
+	e.Write(&Response{Request: &Request{Domain: "example.com", URL: "http://example.com/ads.txt"}, Records: rec})
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := Decompress(out.Bytes())
+	if err != nil || strings.Count(string(rows), "\n") != 2 {
+		t.Errorf("Expected header and single record row [%s] [%v]", rows, err)
+	}
+}
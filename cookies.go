@@ -0,0 +1,34 @@
+package adstxt
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// WithCookieJar crawl using cookie jar, so origins that set cookies before serving content (e.g. WAF challenge
+// flows) can be crawled in a two-step session. When jar is nil, new in-memory jar is used. Crawler is cookie-free
+// by default
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(c *Crawler) {
+		if jar == nil {
+			jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+		}
+		c.jar = jar
+	}
+}
+
+// startSession check if response starts two-step cookie session: origin set cookies and either redirected back to
+// the same URL or refused the cookie-less request. Only single session step is taken per fetch
+func (c *Crawler) startSession(req *Request, res *http.Response, started bool) bool {
+	if c.jar == nil || started || len(res.Cookies()) == 0 {
+		return false
+	}
+
+	if 300 <= res.StatusCode && res.StatusCode < 400 {
+		redirect, err := resolveRedirect(req.URL, res.Header.Get("Location"))
+		return err == nil && redirect == req.URL
+	}
+	return 400 <= res.StatusCode && res.StatusCode < 600
+}
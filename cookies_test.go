@@ -0,0 +1,43 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCookieSession test two-step cookie sessions are crawled only when cookie jar is set
+func TestCookieSession(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("challenge"); err != nil {
+			http.SetCookie(w, &http.Cookie{Name: "challenge", Value: "passed", Path: "/"})
+			if r.URL.Query().Get("refuse") == "1" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			http.Redirect(w, r, r.URL.String(), http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	for _, rawurl := range []string{ts.URL, ts.URL + "/ads.txt?refuse=1"} {
+		req, _ := NewRequest(rawurl)
+		if _, err := Get(req); err == nil {
+			t.Errorf("[%s] Expected cookie-free crawl to fail", rawurl)
+		}
+
+		req, _ = NewRequest(rawurl)
+		res, err := NewCrawler(WithCookieJar(nil)).Get(req)
+		if err != nil {
+			t.Errorf("[%s] Expected cookie session crawl to succeed and not [%s]", rawurl, err)
+			continue
+		}
+		if len(res.DataRecords) != 1 {
+			t.Errorf("[%s] Expected single data record and not [%d]", rawurl, len(res.DataRecords))
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package adstxt
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
+	"mime"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -13,9 +16,21 @@ import (
 
 // Calling remote host error\warning
 const (
-	errHTTPClientError    = "[%s] remote host [%s] Ads.txt URL [%s]"
-	errHTTPGeneralError   = "[%s] remote host [%s] Ads.txt URL [%s]"
-	errHTTPBadContentType = "[%s] Ads.txt file content type should be ‘text/plain’ and not [%s]"
+	errHTTPClientError     = "[%s] remote host [%s] Ads.txt URL [%s]"
+	errHTTPGeneralError    = "[%s] remote host [%s] Ads.txt URL [%s]"
+	errHTTPBadContentType  = "[%s] Ads.txt file content type should be ‘text/plain’ and not [%s]"
+	errHTTPAmbiguousHeader = "[%s] remote host response include conflicting [%s] header values %q"
+	errHTTPMissingHeader   = "[%s] remote host response is missing required [%s] header"
+	errHTTPFetchAborted    = "[%s] Ads.txt fetch aborted: %s"
+	errHTTPFileTooLarge    = "[%s] Ads.txt file is larger than [%d] bytes"
+)
+
+// HTTP response header warnings (response was used, but header values are not as expected)
+const (
+	warnDuplicateHeader     = "HTTP response include multiple [%s] header values %q, using [%s]"
+	warnLenientContentType  = "Ads.txt file accepted in lenient mode: %s"
+	warnRedirectPathVariant = "redirect to non canonical ads.txt path [%s]"
+	warnRedirectWellKnown   = "redirect to well-known file [%s] was followed"
 )
 
 // parsing error\warning: each error includes Ads.txt remote host (domain level) and explanaiton about the error
@@ -26,6 +41,8 @@ const (
 	errInfiniteRedirect          = "Reached the maximum number of allowed redirects while trying to redirect from [%s]: [%s]"
 	errRedirectSameDomain        = "Error on redirect: [%s] is redirecting to the same page. Redirecting from [%s] to [%s]"
 	errRedirctToMainPage         = "Error on redirect for [%s]: [%s] redirected to [%s] which looks like a homepage"
+	errRedirectToWellKnown       = "[%s] failed to get Ads.txt file, redirect from [%s] to well-known file [%s] is rejected"
+	errParseAborted              = "reached [%d] high sevirity warnings after parsing [%d] lines"
 )
 
 // HTTP crawler settings
@@ -35,90 +52,335 @@ const (
 	maxNumRedirects = 10
 )
 
+// Crawler provide methods for downloading Ads.txt files from remote host. Crawler is created using NewCrawler,
+// and can be configured using crawler options. Crawler is safe for concurrent use by multiple goroutines, and
+// should be shared (instead of created per goroutine), so connections are pooled within its connection budget
+type Crawler struct {
+	client     *http.Client                                // HTTP client used to make HTTP request for Ads.txt file from remote host
+	UserAgent  string                                      // crawler UserAgent string
+	userAgents UserAgentProvider                           // provide User-Agent string per request (overrides UserAgent)
+	budget     ConnectionBudget                            // transport level connection limits
+	abortAfter int                                         // abort fetch after this number of high sevirity warnings (0 to read the whole file)
+	wrappers   []func(http.RoundTripper) http.RoundTripper // wrappers applied to crawler transport, in order
+
+	profileLimits ProfileLimits // maximum number of declarations followed when building publisher profile
+	headers       []string      // response headers captured onto the Ads.txt response (canonical form)
+
+	resolveConcurrency int        // number of concurrent DNS lookups on GetMultiple pre-resolution stage (0 disables the stage)
+	lookup             lookupFunc // resolve host IP addresses on pre-resolution stage
+
+	race    bool // race Ads.txt fetch over both schemes, first valid Ads.txt file wins
+	raceWWW bool // race www. (or apex) host variant as well
+
+	preprocessors []Preprocessor // transform Ads.txt file body before parsing
+	security      bool           // attach fetch-time security report to Ads.txt response
+
+	jar http.CookieJar // cookie jar of two-step cookie sessions, nil for cookie-free crawling
+
+	retries      int           // maximum number of retries of origin errors (5xx)
+	retryBackoff time.Duration // delay before the first retry, doubled on every retry
+
+	metrics *crawlerMetrics // internal gauges (see Stats)
+	scoring *ScoreWeights   // score Ads.txt responses using weights, nil disables scoring
+
+	sellersRegistry *SellersRegistry // locate ad systems sellers.json files, nil for the default registry
+	politeness      *Politeness      // per host politeness state, nil disables politeness tracking
+
+	transformers []Transformer // transform Ads.txt responses before they are returned, in order
+	differential Store         // store of baseline snapshots of differential crawl, nil disables conditional requests
+
+	path               string // default path of requested files, instead of /ads.txt (see WithDefaultPath)
+	wellKnownRedirects string // treatment of redirects to other well-known files: follow, warn or reject
+
+	events  EventSink          // consume typed crawl events, nil disables events
+	profile *ValidationProfile // validate Ads.txt files against spec version profile, nil for parser rules only
+
+	rangeResumes int // maximum number of range requests resuming interrupted download (0 to fail on interruption)
+
+	health      HealthStore    // domain health ledger updated on every crawl, nil disables the ledger
+	deadLetters DeadLetterSink // sink of requests failed after all retries, nil disables dead-lettering
+
+	tuner       *Tuner        // GetMultiple concurrency auto-tuning, overrides concurrency when set
+	concurrency int           // maximum number of requests crawled in parallel by GetMultiple (0 for CPU based default)
+	timeout     time.Duration // HTTP request timeout, including reading the response body
+}
+
+// ConnectionBudget holds transport level connection limits of a crawler
+type ConnectionBudget struct {
+	MaxIdleConns        int           // MaxIdleConns maximum number of idle (keep-alive) connections across all hosts, 0 disables keep-alives
+	MaxIdleConnsPerHost int           // MaxIdleConnsPerHost maximum number of idle (keep-alive) connections per host
+	MaxConnsPerHost     int           // MaxConnsPerHost maximum number of connections per host (including active ones), 0 for no limit
+	MaxConns            int           // MaxConns maximum number of open connections across all hosts, 0 for no limit
+	IdleConnTimeout     time.Duration // IdleConnTimeout maximum amount of time idle connection is kept before it is closed
+}
+
+// Connection budgets
 var (
-	redirects = make(map[string]int)
-	lock      = sync.RWMutex{}
+	// DefaultBudget connection budget for single Ads.txt fetch: keep-alives are disabled
+	DefaultBudget = ConnectionBudget{}
+	// BulkBudget connection budget for crawling large number of hosts: connections are reused for redirects on the same
+	// host (e.g. HTTP to HTTPS) and the total number of open connections is bounded, to avoid ephemeral port exhaustion
+	BulkBudget = ConnectionBudget{
+		MaxIdleConns:        1024,
+		MaxIdleConnsPerHost: 2,
+		MaxConnsPerHost:     4,
+		MaxConns:            2048,
+		IdleConnTimeout:     30 * time.Second,
+	}
 )
 
-func writeRedirects(s string) {
-	lock.Lock()
-	defer lock.Unlock()
-	redirects[s] += 1
+// NewCrawler Create new crawler to fetch Ads.txt file from remote host
+func NewCrawler(opts ...Option) *Crawler {
+	c := &Crawler{
+		UserAgent: userAgent,
+		budget:    DefaultBudget,
+		lookup:    lookupIP,
+		metrics:   &crawlerMetrics{},
+		profileLimits: ProfileLimits{
+			MaxSubdomains:        DefaultMaxSubdomains,
+			MaxInventoryPartners: DefaultMaxInventoryPartners,
+		},
+		wellKnownRedirects: WellKnownWarn,
+		timeout:            time.Second * requestTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	var transport http.RoundTripper = newTransport(c.budget, c.metrics)
+	for _, wrap := range c.wrappers {
+		transport = wrap(transport)
+	}
+
+	// Create client with required custom parameters.
+	// Options: n/w call timeout (30sec by default), do not follow redirects by default
+	c.client = &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: transport,
+		Timeout:   c.timeout,
+		Jar:       c.jar,
+	}
+
+	return c
 }
 
-func readRedirects(s string) int {
-	lock.RLock()
-	defer lock.RUnlock()
-	return redirects[s]
+// CloseIdleConnections close any idle (keep-alive) connections of the crawler
+func (c *Crawler) CloseIdleConnections() {
+	c.client.CloseIdleConnections()
 }
 
-// crawler provide methods for downloading Ads.txt files from remote host
-type crawler struct {
-	client    *http.Client // HTTP client used to make HTTP request for Ads.txt file from remote host
-	UserAgent string       // crawler UserAgent string
+// dialFunc dial network connection to address
+type dialFunc func(ctx context.Context, network string, addr string) (net.Conn, error)
+
+// newTransport create HTTP transport with the specified connection budget, counting connections in metrics
+func newTransport(b ConnectionBudget, m *crawlerMetrics) *http.Transport {
+	t := &http.Transport{
+		DisableKeepAlives:   b.MaxIdleConns <= 0,
+		MaxIdleConns:        b.MaxIdleConns,
+		MaxIdleConnsPerHost: b.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     b.MaxConnsPerHost,
+		IdleConnTimeout:     b.IdleConnTimeout,
+	}
+
+	// dial pre-resolved request IP addresses when available
+	dial := resolvedDial((&net.Dialer{Timeout: time.Second * requestTimeout}).DialContext)
+	if b.MaxConns > 0 {
+		dial = newConnLimiter(b.MaxConns, dial).DialContext
+	}
+	t.DialContext = m.countDial(dial)
+
+	return t
 }
 
-// NewCrawler Create new crawler to fetch Ads.txt file from remote host
-func newCrawler() *crawler {
-	return &crawler{
-		// Create client with required custom parameters.
-		// Options: Disable keep-alives, 30sec n/w call timeout, do not follow redirects by default
-		client: &http.Client{
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-			Transport: &http.Transport{
-				DisableKeepAlives: true,
-			},
-			Timeout: time.Second * requestTimeout,
-		},
-		UserAgent: userAgent,
+// resolvedIPsKey context key of request pre-resolved IP addresses
+type resolvedIPsKey struct{}
+
+// resolvedDial wrap dial function so hosts with pre-resolved IP addresses (see Request.IPs) are dialed directly,
+// skipping DNS resolution. Addresses are tried in order until connection succeeds
+func resolvedDial(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		ips, _ := ctx.Value(resolvedIPsKey{}).(map[string][]net.IP)
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || len(ips[host]) == 0 {
+			return dial(ctx, network, addr)
+		}
+
+		for _, ip := range ips[host] {
+			var conn net.Conn
+			conn, err = dial(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+// connLimiter limit total number of open connections of a dialer
+type connLimiter struct {
+	sem  chan struct{}
+	dial dialFunc
+}
+
+// newConnLimiter create new connection limiter allowing up to max open connections
+func newConnLimiter(max int, dial dialFunc) *connLimiter {
+	return &connLimiter{sem: make(chan struct{}, max), dial: dial}
+}
+
+// DialContext open new connection, blocking until connection budget is available or context is done
+func (l *connLimiter) DialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	select {
+	case l.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	conn, err := l.dial(ctx, network, addr)
+	if err != nil {
+		<-l.sem
+		return nil, err
 	}
+
+	return &limitedConn{Conn: conn, release: func() { <-l.sem }}, nil
+}
+
+// limitedConn connection which release its connection budget when closed
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+// Close close the connection and release its connection budget (only once)
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
 }
 
-// send HTTP request to fetch Ads.txt file from remote host
-func (c *crawler) sendRequest(req *Request) (*http.Response, error) {
-	httpRequest, err := http.NewRequest("GET", req.URL, nil)
+// send HTTP request to fetch Ads.txt file (or sellers.json file) from remote host
+func (c *Crawler) sendRequest(req *Request) (*http.Response, error) {
+	ctx := req.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", req.URL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	httpRequest.Header.Add("User-Agent", c.UserAgent)
-	httpRequest.Header.Add("Accept", "text/plain")
+	if len(req.IPs) > 0 {
+		httpRequest = httpRequest.WithContext(context.WithValue(httpRequest.Context(), resolvedIPsKey{}, req.IPs))
+	}
+
+	ua := c.UserAgent
+	if c.userAgents != nil {
+		ua = c.userAgents.UserAgent(req)
+	}
+
+	accept := req.accept
+	if len(accept) == 0 {
+		accept = "text/plain"
+	}
+
+	httpRequest.Header.Add("User-Agent", ua)
+	httpRequest.Header.Add("Accept", accept)
 	httpRequest.Header.Add("Accept-Charset", "utf-8")
 	httpRequest.Header.Add("Content-Type", "text/plain; charset=utf-8")
 
+	// credentials are never sent out of the request root domain scope (e.g. on redirect to third party host)
+	if req.Auth != nil {
+		if d, err := RootDomain(req.URL); err == nil && Domain(d) == req.Domain {
+			req.Auth.apply(httpRequest)
+		}
+	}
+
+	// differential crawl conditional request, or resumption of interrupted download
+	if req.baseline != nil {
+		req.baseline.conditional(httpRequest)
+	}
+	if req.resume != nil {
+		req.resume.apply(httpRequest)
+	}
+
+	if req.local {
+		c.emit(req, &Event{Type: EventRequestStarted})
+		res, err := localResponse(req, httpRequest)
+		if err == nil {
+			c.emit(req, &Event{Type: EventResponseReceived, Status: res.StatusCode})
+		}
+		return res, err
+	}
+
+	// backed off hosts are not crawled, and requests are spaced by host crawl delay
+	if c.politeness != nil {
+		delay, err := c.politeness.admit(req, httpRequest.URL.Host)
+		if err != nil {
+			return nil, err
+		}
+		if delay > 0 && !wait(req, delay) {
+			return nil, ctx.Err()
+		}
+	}
+
+	c.emit(req, &Event{Type: EventRequestStarted})
+	httpRequest, counted := c.metrics.countActive(httpRequest)
+	httpRequest, timer := traceTiming(httpRequest)
 	res, err := c.client.Do(httpRequest)
+	counted(res, err)
 	if err != nil {
+		c.emit(req, &Event{Type: EventRequestFailed, Error: err.Error()})
 		return nil, err
 	}
+	res.Body = &timedBody{ReadCloser: res.Body, timer: timer}
+	c.emit(req, &Event{Type: EventResponseReceived, Status: res.StatusCode})
+
+	if c.politeness != nil {
+		c.politeness.observe(httpRequest.URL.Host, res)
+	}
 
 	return res, nil
 }
 
 // handle HTTP redirect resonse: parse new redirect destination from HTTP response header
-func (c *crawler) handleRedirect(req *Request, res *http.Response) (string, error) {
-	redirect := res.Header.Get("Location")
+func (c *Crawler) handleRedirect(req *Request, res *http.Response) (string, []*Warning, error) {
+	// Location header value is a single URI (RFC 7231 section 7.1.2): identical duplicates are accepted with warning,
+	// conflicting values are treated as an error
+	redirect, w, err := singleHeader(req, res, "Location", false)
+	if err != nil {
+		return "", nil, err
+	}
 
-	// Returning error when redirect is happening to the same location
-	if redirect == req.URL {
-		return "", fmt.Errorf(errRedirectSameDomain, req.Domain, req.URL, redirect)
+	warnings := []*Warning{}
+	if w != nil {
+		warnings = append(warnings, w)
 	}
 
-	// Increasing the number of redirects for the same url
-	writeRedirects(redirect)
+	// Location may be a relative reference (RFC 7231 section 7.1.2), resolve it against the request URL before
+	// applying any redirect scope checks
+	redirect, err = resolveRedirect(req.URL, redirect)
+	if err != nil {
+		return "", nil, fmt.Errorf(errFailToParseRedirect, req.Domain, req.URL, redirect, err.Error())
+	}
 
-	// Return error when the number of redirects for a single url are reaching a max
-	if readRedirects(redirect) > maxNumRedirects {
-		return "", fmt.Errorf(errInfiniteRedirect, req.Domain, req.URL, redirect)
+	// Returning error when redirect is happening to the same location
+	if redirect == req.URL {
+		return "", nil, fmt.Errorf(errRedirectSameDomain, req.Domain, req.URL, redirect)
 	}
 
-	log.Printf("[%s]: redirect from [%s] to [%s]", res.Status, req.URL, redirect)
+	// HTTP to HTTPS upgrade of the same URL doesn't count against redirect policy
+	if isSchemeUpgrade(req.URL, redirect) {
+		return redirect, warnings, nil
+	}
 
 	// Check if redirect destination has the same root domain as the reguest initial root doamin.
-	d, err := rootDomain(redirect)
+	d, err := RootDomain(redirect)
 	if err != nil {
-		return "", fmt.Errorf(errFailToParseRedirect, req.Domain, req.URL, redirect, err.Error())
+		return "", nil, fmt.Errorf(errFailToParseRedirect, req.Domain, req.URL, redirect, err.Error())
 	}
 
 	// According to IAB's ads.txt specification, section 3.1 "ACCESS METHOD":
@@ -126,71 +388,255 @@ func (c *crawler) handleRedirect(req *Request, res *http.Response) (string, erro
 	// the advertising system should follow the redirect and consume the data as authoritative for the source of the redirect,
 	// if and only if the redirect is within scope of the original root domain as defined above.
 	// Multiple redirects are valid as long as each redirect location remains within the original root domain."
-	if d != req.Domain {
+	if Domain(d) != req.Domain {
 		// If redirect to different domain, check that this is the first redirect to different domain
 		// According to IAB's ads.txt specification, section 3.1 "ACCESS METHOD":
 		// "Only a single HTTP redirect to a destination outside the original root domain is allowed to
 		// facilitate one-hop delegation of authority to a third party's web server domain."
-		prevDomain, _ := rootDomain(req.URL)
-		if prevDomain != req.Domain && prevDomain != d {
-			return "", fmt.Errorf(errRedirectToDifferentDomain, req.Domain, prevDomain, d)
+		prevDomain, _ := RootDomain(req.URL)
+		if Domain(prevDomain) != req.Domain && prevDomain != d {
+			return "", nil, fmt.Errorf(errRedirectToDifferentDomain, req.Domain, prevDomain, d)
 		}
 	}
 
+	// redirect to another well-known file (e.g. ads.txt and app-ads.txt served from a single endpoint) is treated by
+	// crawler policy, instead of the homepage heuristic below
+	if file := redirectWellKnown(req.URL, redirect); len(file) > 0 {
+		switch c.wellKnownRedirects {
+		case WellKnownReject:
+			return "", nil, fmt.Errorf(errRedirectToWellKnown, req.Domain, req.URL, redirect)
+		case WellKnownWarn:
+			warnings = append(warnings, &Warning{
+				Code:    WarnRedirectWellKnown,
+				Text:    fmt.Sprintf("Location: %s", redirect),
+				Level:   LowSevirity,
+				Message: fmt.Sprintf(warnRedirectWellKnown, file),
+			})
+		}
+		return redirect, warnings, nil
+	}
+
 	// Make sure redirects takes us to another Ads.txt file and not just to home page
 	// File doesn't necessarily need to be from a filesystem, so needed more checks for match.
 	// Assume when filename equals ads.txt it's coming from filesystem.
 	if !strings.HasSuffix(redirect, "/ads.txt") {
 		_, err := url.ParseRequestURI(redirect)
 		if err != nil {
-			return "", fmt.Errorf(errRedirctToInvalidAdsTxt, req.Domain, req.URL, redirect)
+			return "", nil, fmt.Errorf(errRedirctToInvalidAdsTxt, req.Domain, req.URL, redirect)
 		}
 
 		u, err := url.Parse(redirect)
 		if err != nil || u.Scheme == "" || u.Host == "" {
-			return "", fmt.Errorf(errRedirctToInvalidAdsTxt, req.Domain, req.URL, redirect)
+			return "", nil, fmt.Errorf(errRedirctToInvalidAdsTxt, req.Domain, req.URL, redirect)
 		}
 
-		if u.Scheme+"://"+u.Hostname() == redirect {
-			return "", fmt.Errorf(errRedirctToMainPage, req.Domain, req.URL, redirect)
+		if u.Scheme+"://"+u.Host == strings.TrimSuffix(redirect, "/") {
+			return "", nil, fmt.Errorf(errRedirctToMainPage, req.Domain, req.URL, redirect)
 		}
 
-		return redirect, nil
+		// ads.txt path variants (letter case, trailing slash or query string e.g. CDN cache busting parameters) are
+		// followed with warning, records are flagged in lenient mode
+		if isAdsTxtPathVariant(u) {
+			warnings = append(warnings, &Warning{
+				Code:    WarnRedirectPathVariant,
+				Text:    fmt.Sprintf("Location: %s", redirect),
+				Level:   LowSevirity,
+				Message: fmt.Sprintf(warnRedirectPathVariant, redirect),
+			})
+		}
+
+		return redirect, warnings, nil
 	}
 
-	return redirect, nil
+	return redirect, warnings, nil
+}
+
+// isAdsTxtPathVariant check if URL is a non canonical form of ads.txt file path: ads.txt in different letter case,
+// with trailing slash or with query string
+func isAdsTxtPathVariant(u *url.URL) bool {
+	p := strings.ToLower(strings.TrimSuffix(u.Path, "/"))
+	return strings.HasSuffix(p, "/ads.txt") && (u.Path != p || len(u.RawQuery) > 0 || u.ForceQuery)
 }
 
-// Read HTTP response body
-func (c *crawler) readBody(req *Request, res *http.Response) ([]byte, error) {
+// resolveRedirect resolve redirect location (absolute, path relative or scheme relative) against request URL
+func resolveRedirect(rawurl string, location string) (string, error) {
+	base, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+
+	// redirect destination must be absolute HTTP(S) URL, opaque references (e.g. "host:80/ads.txt") resolve to
+	// non HTTP scheme
+	u := base.ResolveReference(ref)
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Hostname()) == 0 {
+		return u.String(), fmt.Errorf("redirect destination is not absolute HTTP(S) URL")
+	}
+	return u.String(), nil
+}
+
+// check HTTP response content type. When request is lenient, wrong content type is accepted with warning
+// (and returned lenient flag is set)
+func (c *Crawler) checkContentType(req *Request, res *http.Response) (bool, *Warning, error) {
 	// The HTTP Content-type should be ‘text/plain’, and all other Content-types should be treated as
 	// an error and the content ignored
-	contentType := res.Header.Get("Content-Type")
-	if strings.Index(contentType, "text/plain") != 0 {
-		return nil, fmt.Errorf(errHTTPBadContentType, req.URL, contentType)
+	contentType, w, err := singleHeader(req, res, "Content-Type", true)
+	if err == nil {
+		// media type is case insensitive and may include parameters (RFC 7231 section 3.1.1.1)
+		mediaType, _, e := mime.ParseMediaType(contentType)
+		if e != nil && e != mime.ErrInvalidMediaParameter || mediaType != "text/plain" {
+			err = fmt.Errorf(errHTTPBadContentType, req.URL, contentType)
+		}
 	}
 
+	if err != nil {
+		if !req.Lenient {
+			return false, nil, err
+		}
+		return true, &Warning{
+			Code:    WarnLenientContentType,
+			Text:    fmt.Sprintf("Content-Type: %s", strings.Join(headerValues(res, "Content-Type", false), ", ")),
+			Level:   LowSevirity,
+			Message: fmt.Sprintf(warnLenientContentType, err.Error()),
+		}, nil
+	}
+
+	return false, w, nil
+}
+
+// Read HTTP response body. Interrupted download is resumed using range requests, when crawler range resumption
+// is set (see WithRangeResume)
+func (c *Crawler) readBody(req *Request, res *http.Response) ([]byte, error) {
 	// read response body
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := ioutil.ReadAll(limitBody(res.Body))
+	validator := rangeValidator(res)
+	for attempt := 0; err != nil && attempt < c.rangeResumes && canResume(res, err, len(body)); attempt++ {
+		body, err = c.resumeBody(req, body, validator)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if len(body) > MaxFileSize {
+		return nil, fmt.Errorf(errHTTPFileTooLarge, req.URL, MaxFileSize)
+	}
 
 	return body, nil
 }
 
-// parse Ads.txt file expiration date from the response Expires header
-func (c *crawler) parseExpires(res *http.Response) (time.Time, error) {
-	expires := res.Header.Get("Expires")
-	if len(expires) == 0 {
-		return time.Time{}, fmt.Errorf("Failed to parse expires from response header")
+// limitBody limit response body read to MaxFileSize+1 bytes, so larger file is detected without reading it whole
+// (the limit was exceeded when no bytes are left to read)
+func limitBody(r io.Reader) *io.LimitedReader {
+	return &io.LimitedReader{R: r, N: MaxFileSize + 1}
+}
+
+// maxDrainSize maximum size of unread response body discarded before closing it, larger bodies close the connection
+const maxDrainSize = 64 << 10
+
+// drainBody discard and close response body which is not read (e.g. redirect), so the connection can be reused
+func drainBody(res *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxDrainSize))
+	res.Body.Close()
+}
+
+// parse Ads.txt file expiration date from the response Expires header. Invalid dates are ignored, expiration falls
+// back to the specification default (ExpiresSourceDefault) when no date is valid
+func (c *Crawler) parseExpires(res *http.Response) (time.Time, *Warning, error) {
+	values := headerValues(res, "Expires", false)
+	if len(values) == 0 {
+		return time.Time{}, nil, fmt.Errorf("Failed to parse expires from response header")
 	}
 
-	parsedHeader, err := http.ParseTime(expires)
-	if err != nil {
-		log.Printf("[%s] Error when parsing HTTP expires header from response [%s]", res.Request.URL, err.Error())
-		return time.Time{}, err
+	// Expires is not a list based header, when multiple values are sent use the earliest valid date since
+	// it is the most conservative choice (a cache should not serve stale content)
+	var expires time.Time
+	var err error
+	for _, v := range values {
+		t, e := http.ParseTime(v)
+		if e != nil {
+			err = e
+			continue
+		}
+		if expires.IsZero() || t.Before(expires) {
+			expires = t
+		}
+	}
+
+	if expires.IsZero() {
+		return time.Time{}, nil, err
+	}
+
+	var w *Warning
+	if len(values) > 1 {
+		w = newHeaderWarning("Expires", values, expires.Format(http.TimeFormat))
+	}
+
+	return expires, w, nil
+}
+
+// headerValues return all non empty values of HTTP response header. Values are trimmed from surrounding
+// whitespace, and when split is set values combined in a single comma separated line are split as well
+func headerValues(res *http.Response, name string, split bool) []string {
+	values := []string{}
+	for _, v := range res.Header[http.CanonicalHeaderKey(name)] {
+		items := []string{v}
+		if split {
+			items = strings.Split(v, ",")
+		}
+		for _, i := range items {
+			i = strings.TrimSpace(i)
+			if len(i) > 0 {
+				values = append(values, i)
+			}
+		}
+	}
+	return values
+}
+
+// captureHeaders return copy of the crawler captured headers from HTTP response, nil when none is captured
+func (c *Crawler) captureHeaders(res *http.Response) http.Header {
+	var h http.Header
+	for _, name := range c.headers {
+		if values, ok := res.Header[name]; ok {
+			if h == nil {
+				h = make(http.Header, len(c.headers))
+			}
+			h[name] = append([]string{}, values...)
+		}
 	}
+	return h
+}
 
-	return parsedHeader, nil
+// singleHeader return value of single value HTTP response header. Identical duplicates values are accepted
+// with warning, while conflicting values are treated as an error
+func singleHeader(req *Request, res *http.Response, name string, split bool) (string, *Warning, error) {
+	values := headerValues(res, name, split)
+	if len(values) == 0 {
+		return "", nil, fmt.Errorf(errHTTPMissingHeader, req.URL, name)
+	}
+
+	for _, v := range values[1:] {
+		if v != values[0] {
+			return "", nil, fmt.Errorf(errHTTPAmbiguousHeader, req.URL, name, values)
+		}
+	}
+
+	if len(values) > 1 {
+		return values[0], newHeaderWarning(name, values, values[0]), nil
+	}
+
+	return values[0], nil, nil
+}
+
+// newHeaderWarning create new warning for HTTP response header with multiple values
+func newHeaderWarning(name string, values []string, used string) *Warning {
+	return &Warning{
+		Code:    WarnDuplicateHeader,
+		Text:    fmt.Sprintf("%s: %s", name, strings.Join(values, ", ")),
+		Level:   LowSevirity,
+		Message: fmt.Sprintf(warnDuplicateHeader, name, values, used),
+	}
 }
@@ -1,9 +1,12 @@
 package adstxt
 
 import (
+	"context"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,7 +26,7 @@ func TestSendAndParseRquest(t *testing.T) {
 	req, _ := NewRequest(ts.URL)
 
 	// test send request
-	c := newCrawler()
+	c := NewCrawler()
 	res, err := c.sendRequest(req)
 	if err != nil {
 		t.Error(err)
@@ -41,6 +44,38 @@ func TestSendAndParseRquest(t *testing.T) {
 	}
 }
 
+// TestMaxFileSize test crawler fail to read Ads.txt file larger than MaxFileSize
+func TestMaxFileSize(t *testing.T) {
+	const line = "greenadexchange.com,XF7342,DIRECT\n"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		size := MaxFileSize
+		if r.URL.Query().Get("size") == "large" {
+			size++
+		}
+		io.WriteString(w, strings.Repeat(line, size/len(line)))
+		io.WriteString(w, strings.Repeat("#", size%len(line)))
+	}))
+	defer ts.Close()
+
+	for _, abortAfter := range []int{0, 100} {
+		c := NewCrawler(WithEarlyAbort(abortAfter))
+
+		req, _ := NewRequest(ts.URL)
+		req.URL += "?size=max"
+		if _, err := c.Get(req); err != nil {
+			t.Errorf("Expected Ads.txt file of [%d] bytes to be read [%s]", MaxFileSize, err)
+		}
+
+		req, _ = NewRequest(ts.URL)
+		req.URL += "?size=large"
+		if _, err := c.Get(req); err == nil || !strings.Contains(err.Error(), "is larger than") {
+			t.Errorf("Expected Ads.txt file larger than [%d] bytes to fail [%v]", MaxFileSize, err)
+		}
+	}
+}
+
 // TestHandleRedirect test crawler handle HTTP redirect response: extract new redirect destination from HTTP resposne
 func TestHandleRedirect(t *testing.T) {
 	const redirect = "http://gotest.com/ads.txt"
@@ -55,7 +90,7 @@ func TestHandleRedirect(t *testing.T) {
 	req, _ := NewRequest(ts.URL)
 
 	// test send request
-	c := newCrawler()
+	c := NewCrawler()
 	res, err := c.sendRequest(req)
 	if err != nil {
 		t.Error(err)
@@ -64,7 +99,7 @@ func TestHandleRedirect(t *testing.T) {
 	defer res.Body.Close()
 
 	// parse redirect location
-	r, err := c.handleRedirect(req, res)
+	r, _, err := c.handleRedirect(req, res)
 	if err != nil {
 		t.Error(err)
 	}
@@ -89,7 +124,7 @@ func TestParseExpires(t *testing.T) {
 	req, _ := NewRequest(ts.URL)
 
 	// test send request
-	c := newCrawler()
+	c := NewCrawler()
 	res, err := c.sendRequest(req)
 	if err != nil {
 		t.Error(err)
@@ -97,7 +132,7 @@ func TestParseExpires(t *testing.T) {
 
 	defer res.Body.Close()
 
-	expires, err := c.parseExpires(res)
+	expires, _, err := c.parseExpires(res)
 	if err != nil {
 		t.Error(err)
 	}
@@ -107,3 +142,361 @@ func TestParseExpires(t *testing.T) {
 	}
 
 }
+
+// TestMultiValueHeaders test crawler handling of duplicate and whitespace padded HTTP response headers
+func TestMultiValueHeaders(t *testing.T) {
+	const redirect = "http://gotest.com/ads.txt"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("case") {
+		case "duplicate":
+			w.Header().Add("Location", redirect)
+			w.Header().Add("Location", " "+redirect)
+			w.WriteHeader(http.StatusMovedPermanently)
+		case "conflict":
+			w.Header().Add("Location", redirect)
+			w.Header().Add("Location", "http://other.com/ads.txt")
+			w.WriteHeader(http.StatusMovedPermanently)
+		default:
+			w.Header().Add("Content-Type", " TEXT/PLAIN ; charset=utf-8")
+			w.Header().Add("Expires", "Sun, 06 Nov 2044 08:49:37 GMT")
+			w.Header().Add("Expires", "Sat, 05 Nov 2044 08:49:37 GMT")
+			io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+		}
+	}))
+	defer ts.Close()
+
+	domain, err := RootDomain(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCrawler()
+	send := func(query string) (*Request, *http.Response) {
+		req := &Request{URL: ts.URL + "/ads.txt?case=" + query, Domain: Domain(domain)}
+		res, err := c.sendRequest(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req, res
+	}
+
+	// identical Location values are accepted with warning
+	req, res := send("duplicate")
+	r, w, err := c.handleRedirect(req, res)
+	res.Body.Close()
+	if err != nil {
+		t.Error(err)
+	}
+	if r != redirect {
+		t.Errorf("Expected redirect destination to be [%s] and not [%s]", redirect, r)
+	}
+	if len(w) != 1 {
+		t.Error("Expected warning for duplicate Location header values")
+	}
+
+	// conflicting Location values are an error
+	req, res = send("conflict")
+	_, _, err = c.handleRedirect(req, res)
+	res.Body.Close()
+	if err == nil {
+		t.Error("Expected error for conflicting Location header values")
+	}
+
+	// padded and upper case Content-Type is valid, earliest Expires is used
+	req, res = send("ok")
+	defer res.Body.Close()
+	if _, _, err := c.checkContentType(req, res); err != nil {
+		t.Error(err)
+	}
+
+	expires, ew, err := c.parseExpires(res)
+	if err != nil {
+		t.Error(err)
+	}
+	if expected := "Sat, 05 Nov 2044 08:49:37 GMT"; expires.Format(http.TimeFormat) != expected {
+		t.Errorf("Expected expires [%s] to be the earliest header value [%s]", expires.Format(http.TimeFormat), expected)
+	}
+	if ew == nil {
+		t.Error("Expected warning for multiple Expires header values")
+	}
+}
+
+// TestRedirectHops test redirects are counted per fetch: shared crawler follow the same redirect on any number of
+// fetches, and fetch fail on redirect loop
+func TestRedirectHops(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/ads.txt":
+			http.Redirect(w, r, "/cdn/ads.txt", http.StatusFound)
+		case r.URL.Path == "/loop/a/ads.txt":
+			http.Redirect(w, r, "/loop/b/ads.txt", http.StatusFound)
+		case r.URL.Path == "/loop/b/ads.txt":
+			http.Redirect(w, r, "/loop/a/ads.txt", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+		}
+	}))
+	defer ts.Close()
+
+	c := NewCrawler()
+	for i := 0; i <= maxNumRedirects*2; i++ {
+		req, _ := NewRequest(ts.URL)
+		if _, err := c.Get(req); err != nil {
+			t.Fatalf("Expected fetch [%d] redirected to the same location to succeed [%v]", i, err)
+		}
+	}
+
+	req, _ := NewRequest(ts.URL + "/loop/a/ads.txt")
+	_, err := c.Get(req)
+	if err == nil || !strings.Contains(err.Error(), "maximum number of allowed redirects") {
+		t.Errorf("Expected redirect loop to fail on maximum number of redirects [%v]", err)
+	}
+}
+
+// TestResolveRedirect test resolving relative redirect locations against the request URL
+func TestResolveRedirect(t *testing.T) {
+	const base = "http://www.example.com/path/ads.txt"
+
+	locations := map[string]string{
+		"http://example.com/ads.txt": "http://example.com/ads.txt",
+		"/ads.txt":                   "http://www.example.com/ads.txt",
+		"ads.txt":                    "http://www.example.com/path/ads.txt",
+		"../ads.txt":                 "http://www.example.com/ads.txt",
+		"//cdn.example.com/ads.txt":  "http://cdn.example.com/ads.txt",
+	}
+
+	for k, v := range locations {
+		r, err := resolveRedirect(base, k)
+		if err != nil {
+			t.Error(err)
+		}
+		if r != v {
+			t.Errorf("Expected redirect [%s] to be resolved to [%s] and not [%s]", k, v, r)
+		}
+	}
+}
+
+// TestHandleRelativeRedirect test crawler handle HTTP redirect response with relative Location header
+func TestHandleRelativeRedirect(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/sub/ads.txt")
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer ts.Close()
+
+	// request mock
+	req, _ := NewRequest(ts.URL)
+
+	c := NewCrawler()
+	res, err := c.sendRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	r, _, err := c.handleRedirect(req, res)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if expected := ts.URL + "/sub/ads.txt"; r != expected {
+		t.Errorf("Expected redirect destination to be [%s] and not [%s]", expected, r)
+	}
+}
+
+// TestConnectionBudget test crawler transport is configured according to its connection budget
+func TestConnectionBudget(t *testing.T) {
+	c := NewCrawler()
+	if tr := c.client.Transport.(*http.Transport); !tr.DisableKeepAlives {
+		t.Error("Expected default crawler to disable keep-alives")
+	}
+
+	c = NewCrawler(WithConnectionBudget(BulkBudget))
+	tr := c.client.Transport.(*http.Transport)
+	if tr.DisableKeepAlives || tr.MaxIdleConnsPerHost != BulkBudget.MaxIdleConnsPerHost || tr.MaxConnsPerHost != BulkBudget.MaxConnsPerHost {
+		t.Error("Expected bulk crawler transport to be configured with bulk connection budget")
+	}
+}
+
+// TestConnLimiter test total number of open connections is limited, and budget is released when connection is closed
+func TestConnLimiter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	addr := ts.Listener.Addr().String()
+	l := newConnLimiter(1, (&net.Dialer{}).DialContext)
+
+	conn, err := l.DialContext(context.Background(), "tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := l.DialContext(ctx, "tcp", addr); err == nil {
+		t.Error("Expected dial to block when connection budget is exhausted")
+	}
+
+	conn.Close()
+	conn.Close()
+
+	conn, err = l.DialContext(context.Background(), "tcp", addr)
+	if err != nil {
+		t.Errorf("Expected connection budget to be released after connection is closed [%s]", err.Error())
+	} else {
+		conn.Close()
+	}
+}
+
+// TestResolvedIPs test request pre-resolved IP addresses are dialed instead of resolving the request host
+func TestResolvedIPs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	// host name is not resolvable, pre-resolved address points to the test server
+	req, _ := NewRequest("http://adstxt.invalid:" + port)
+	req.IPs = map[string][]net.IP{"adstxt.invalid": {net.ParseIP("127.0.0.1")}}
+
+	res, err := Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.DataRecords) != 1 {
+		t.Errorf("Expected single DataReocrd but found [%d]", len(res.DataRecords))
+	}
+}
+
+// TestRedirectPathVariants test redirect to ads.txt path variants is followed with warning in every mode
+func TestRedirectPathVariants(t *testing.T) {
+	var location string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", location)
+		w.WriteHeader(http.StatusMovedPermanently)
+	}))
+	defer ts.Close()
+
+	locations := []string{
+		"http://gotest.com/ads.txt?x=1",
+		"http://gotest.com/Ads.txt",
+		"http://gotest.com/ads.txt/",
+	}
+
+	c := NewCrawler()
+	for _, l := range locations {
+		location = l
+		for _, lenient := range []bool{false, true} {
+			req, _ := NewRequest(ts.URL)
+			req.Lenient = lenient
+
+			res, err := c.sendRequest(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, w, err := c.handleRedirect(req, res)
+			res.Body.Close()
+
+			if err != nil || r != l || len(w) != 1 || w[0].Code != WarnRedirectPathVariant {
+				t.Errorf("[%t] Expected redirect to [%s] to be followed with warning", lenient, l)
+			}
+			if e := newRedirectEvent(req, res, r, w, err); (e.Decision == RedirectFollowedLenient) != lenient {
+				t.Errorf("[%t] Expected redirect to [%s] decision to be lenient in lenient mode only and not [%s]", lenient, l, e.Decision)
+			}
+		}
+	}
+
+	// records fetched via path variant are flagged in lenient mode
+	variant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ads.txt" {
+			http.Redirect(w, r, "/Ads.txt", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer variant.Close()
+
+	for _, lenient := range []bool{false, true} {
+		req, _ := NewRequest(variant.URL)
+		req.Lenient = lenient
+		res, err := c.Get(req)
+		if err != nil || len(res.DataRecords) != 1 {
+			t.Fatalf("[%t] Expected fetch via path variant redirect to succeed [%v]", lenient, err)
+		}
+		warned := false
+		for _, w := range res.Warnings {
+			warned = warned || w.Code == WarnRedirectPathVariant
+		}
+		flagged := len(res.DataRecords[0].Flags) == 1 && res.DataRecords[0].Flags[0] == FlagLenientRedirectPath
+		if !warned || flagged != lenient {
+			t.Errorf("[%t] Expected path variant warning, and records to be flagged in lenient mode only %v", lenient, res.DataRecords[0].Flags)
+		}
+	}
+}
+
+// TestRedirectPreservePort test non standard port is preserved through relative redirects and homepage check
+func TestRedirectPreservePort(t *testing.T) {
+	c := NewCrawler()
+	req, _ := NewRequest("http://example.com:8443")
+
+	locations := map[string]string{
+		"/v2/ads.txt":                         "http://example.com:8443/v2/ads.txt",
+		"http://www.example.com:8443/ads.txt": "http://www.example.com:8443/ads.txt",
+		"http://example.com:8443/":            "",
+		"http://example.com:8443":             "",
+	}
+
+	for location, expected := range locations {
+		res := &http.Response{StatusCode: http.StatusFound, Header: http.Header{"Location": {location}}}
+		r, _, err := c.handleRedirect(req, res)
+		if len(expected) == 0 && err == nil {
+			t.Errorf("Expected redirect to homepage [%s] to fail", location)
+		}
+		if len(expected) > 0 && r != expected {
+			t.Errorf("Expected redirect to [%s] and not [%s] [%v]", expected, r, err)
+		}
+	}
+}
+
+// TestRedirectWellKnown test redirects to other well-known files are treated by crawler policy
+func TestRedirectWellKnown(t *testing.T) {
+	locations := map[string]bool{
+		"https://example.com/app-ads.txt":             true,
+		"https://example.com/sellers.json":            true,
+		"https://example.com/.well-known/ads.txt.sig": true,
+		"https://example.com/v2/ads.txt":              false,
+		"https://example.com/ADS.TXT":                 false,
+	}
+
+	for policy, warned := range map[string]int{WellKnownFollow: 0, WellKnownWarn: 1, WellKnownReject: 0} {
+		c := NewCrawler(WithWellKnownRedirects(policy))
+		for l, wellKnown := range locations {
+			req := &Request{URL: "https://example.com/ads.txt", Domain: "example.com", Lenient: true}
+			res := &http.Response{StatusCode: http.StatusFound, Header: http.Header{"Location": {l}}}
+
+			r, w, err := c.handleRedirect(req, res)
+			if !wellKnown {
+				if err != nil || r != l {
+					t.Errorf("[%s] Expected redirect to [%s] to be followed [%v]", policy, l, err)
+				}
+				continue
+			}
+
+			if policy == WellKnownReject {
+				if err == nil {
+					t.Errorf("[%s] Expected redirect to [%s] to be rejected", policy, l)
+				}
+				continue
+			}
+			if err != nil || r != l || len(w) != warned || (warned > 0 && w[0].Code != WarnRedirectWellKnown) {
+				t.Errorf("[%s] Expected redirect to [%s] to be followed with [%d] warnings [%v]", policy, l, warned, err)
+			}
+		}
+	}
+}
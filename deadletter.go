@@ -0,0 +1,145 @@
+package adstxt
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// FailedAttempt single failed fetch attempt of dead-lettered request
+type FailedAttempt struct {
+	At         time.Time `json:"at"`                   // At date the attempt failed
+	Class      string    `json:"class"`                // Class failure class (see FailureClass)
+	Error      string    `json:"error"`                // Error message of the attempt failure
+	StatusCode int       `json:"statusCode,omitempty"` // StatusCode HTTP response status code, when remote host responded
+}
+
+// DeadLetter request which failed to be crawled, with history of all its failed attempts. Dead letters can be
+// reprocessed later by crawling the request again
+type DeadLetter struct {
+	Request   *Request         `json:"request"`   // Request failed request (credentials and pre-resolved addresses are not kept)
+	Attempts  []*FailedAttempt `json:"attempts"`  // Attempts failed attempts, oldest first
+	Exhausted bool             `json:"exhausted"` // Exhausted request failed with retryable failure after all retries (see WithRetry)
+}
+
+// DeadLetterSink receive dead letters of failed requests. Sinks are called from crawling goroutines, and must be
+// safe for concurrent use
+type DeadLetterSink interface {
+	DeadLetter(l *DeadLetter) error
+}
+
+// DeadLetterSinkFunc adapter of ordinary function to dead-letter sink (e.g. to insert dead letters to database table)
+type DeadLetterSinkFunc func(l *DeadLetter) error
+
+// DeadLetter call f(l)
+func (f DeadLetterSinkFunc) DeadLetter(l *DeadLetter) error {
+	return f(l)
+}
+
+// DeadLetterQueue dead-letter sink sending dead letters to channel, crawl of the failed request blocks until the
+// dead letter is received
+func DeadLetterQueue(queue chan<- *DeadLetter) DeadLetterSink {
+	return DeadLetterSinkFunc(func(l *DeadLetter) error {
+		queue <- l
+		return nil
+	})
+}
+
+// DeadLetterFile dead-letter sink appending dead letters to file encoded using codec, read them back with
+// ReadDeadLetters. Dead letters are written as they arrive, so they survive crawler crash
+type DeadLetterFile struct {
+	lock  sync.Mutex
+	file  *os.File
+	codec Codec
+}
+
+// NewDeadLetterFile open dead-letter file for appending (created when missing). Dead letter torn by crawler crash
+// while it was written is truncated, so new dead letters are appended right after the last complete one
+func NewDeadLetterFile(path string, codec Codec) (*DeadLetterFile, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := framesEnd(f)
+	if err == nil {
+		err = f.Truncate(end)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &DeadLetterFile{file: f, codec: codec}, nil
+}
+
+// DeadLetter append dead letter to file. Dead letters are length prefixed, since codec may be binary
+func (d *DeadLetterFile) DeadLetter(l *DeadLetter) error {
+	data, err := d.codec.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return writeFrame(d.file, data)
+}
+
+// Close dead-letter file
+func (d *DeadLetterFile) Close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.file.Close()
+}
+
+// ReadDeadLetters read all dead letters of dead-letter file, oldest first. Dead letter torn by crawler crash while
+// it was written is the last in the file, and is dropped
+func ReadDeadLetters(path string, codec Codec) ([]*DeadLetter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	letters := []*DeadLetter{}
+	r := bufio.NewReader(f)
+	for {
+		data, err := readFrame(r)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return letters, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		l := &DeadLetter{}
+		if err := codec.Unmarshal(data, l); err != nil {
+			return nil, err
+		}
+		letters = append(letters, l)
+	}
+}
+
+// newFailedAttempt create failed attempt record of fetch error
+func newFailedAttempt(err error) *FailedAttempt {
+	a := &FailedAttempt{At: time.Now().UTC(), Class: FailureClass(err), Error: err.Error()}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		a.StatusCode = statusErr.StatusCode
+	}
+	return a
+}
+
+// deadLetter send failed request to crawler dead-letter sink. Requests interrupted by their context are not
+// dead-lettered, since they didn't fail on their own. Sink is best effort, its errors don't change the crawl error
+func (c *Crawler) deadLetter(req *Request, rawurl string, attempts []*FailedAttempt, err error) {
+	if req.ctx != nil && req.ctx.Err() != nil {
+		return
+	}
+
+	r := &Request{Domain: req.Domain, URL: rawurl, Lenient: req.Lenient, Tenant: req.Tenant}
+	c.deadLetters.DeadLetter(&DeadLetter{Request: r, Attempts: attempts, Exhausted: Retryable(err)})
+}
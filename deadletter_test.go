@@ -0,0 +1,130 @@
+package adstxt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestDeadLetters test failed requests are dead-lettered with history of all their attempts
+func TestDeadLetters(t *testing.T) {
+	status := map[string]int{"example.com": http.StatusOK, "failing.com": http.StatusServiceUnavailable, "missing.com": http.StatusNotFound}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status[r.Host])
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, nil)
+
+	path := filepath.Join(t.TempDir(), "dead-letters")
+	for _, codec := range []Codec{JSONCodec, MsgpackCodec} {
+		f, err := NewDeadLetterFile(path+"."+codec.Name(), codec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := []*Request{}
+		for domain := range status {
+			r, _ := NewRequest(domain, WithTenant("acme"))
+			req = append(req, r)
+		}
+		NewCrawler(route, WithRetry(2, time.Millisecond), WithDeadLetters(f)).GetMultiple(req, HandlerFunc(func(*Request, *Response, error) {}))
+		f.Close()
+
+		letters, err := ReadDeadLetters(path+"."+codec.Name(), codec)
+		if err != nil || len(letters) != 2 {
+			t.Fatalf("[%s] Expected [2] dead letters and not [%d] [%v]", codec.Name(), len(letters), err)
+		}
+
+		expected := map[string][2]int{"failing.com": {3, http.StatusServiceUnavailable}, "missing.com": {1, http.StatusNotFound}}
+		for _, l := range letters {
+			e, ok := expected[l.Request.Domain.String()]
+			if !ok || len(l.Attempts) != e[0] || l.Attempts[0].StatusCode != e[1] || l.Request.Tenant != "acme" {
+				t.Errorf("[%s] Expected [%s] dead letter with [%d] attempts and not [%d]", codec.Name(), l.Request.Domain, e[0], len(l.Attempts))
+				continue
+			}
+			if l.Exhausted != (l.Request.Domain == "failing.com") {
+				t.Errorf("[%s] Expected only retryable failure to exhaust retries and not [%s]", codec.Name(), l.Request.Domain)
+			}
+			if l.Attempts[0].Class == "" || l.Attempts[0].At.IsZero() {
+				t.Errorf("[%s] Expected [%s] attempt failure to be classified", codec.Name(), l.Request.Domain)
+			}
+		}
+	}
+
+	// dead letters can be queued, requests interrupted by context are not dead-lettered
+	queue := make(chan *DeadLetter, 1)
+	c := NewCrawler(route, WithDeadLetters(DeadLetterQueue(queue)))
+	req, _ := NewRequest("missing.com")
+	c.Get(req)
+	if l := <-queue; l.Request.URL != req.URL {
+		t.Errorf("Expected queued dead letter of [%s] and not [%s]", req.URL, l.Request.URL)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req.ctx = ctx
+	if _, err := c.Get(req); err == nil || len(queue) != 0 {
+		t.Errorf("Expected request interrupted by context not to be dead-lettered [%v]", err)
+	}
+}
+
+// TestReadDeadLettersTruncated test dead letter torn while it was written is dropped, also when the file is appended
+// later, and corrupt frame length is rejected without allocating it
+func TestReadDeadLettersTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letters")
+	f, err := NewDeadLetterFile(path, JSONCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, domain := range []string{"failing.com", "missing.com"} {
+		r, _ := NewRequest(domain)
+		if err := f.DeadLetter(&DeadLetter{Request: r, Attempts: []*FailedAttempt{{Class: "http", Error: "failed"}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+	letters, err := ReadDeadLetters(path, JSONCodec)
+	if err != nil || len(letters) != 1 || letters[0].Request.Domain != "failing.com" {
+		t.Fatalf("Expected torn dead letter to be dropped, and [1] dead letter to be read and not [%d] [%v]", len(letters), err)
+	}
+
+	// dead letters appended after torn dead letter are readable
+	if f, err = NewDeadLetterFile(path, JSONCodec); err != nil {
+		t.Fatal(err)
+	}
+	for _, domain := range []string{"example.com", "other.com"} {
+		r, _ := NewRequest(domain)
+		if err := f.DeadLetter(&DeadLetter{Request: r}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+
+	letters, err = ReadDeadLetters(path, JSONCodec)
+	if err != nil || len(letters) != 3 || letters[1].Request.Domain != "example.com" || letters[2].Request.Domain != "other.com" {
+		t.Fatalf("Expected dead letters appended after torn dead letter to be read, read [%d] [%v]", len(letters), err)
+	}
+
+	if err := os.WriteFile(path, []byte{0xff, 0xff, 0xff, 0xff, '{'}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadDeadLetters(path, JSONCodec); err == nil {
+		t.Error("Expected error for frame length larger than maximum frame size")
+	}
+}
@@ -0,0 +1,101 @@
+package adstxt
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// baselineSnapshot return the latest stored snapshot (not compacted) of request file from crawler differential
+// store, nil when the file wasn't stored yet. Snapshots of other files of the domain (e.g. app-ads.txt or file of
+// subdomain) are never used as baseline
+func (c *Crawler) baselineSnapshot(req *Request) *Snapshot {
+	if c.differential == nil {
+		return nil
+	}
+
+	snapshots, err := c.differential.Snapshots(tenantKey(req.Tenant, FileKey(req.Domain.String(), req.URL)))
+	if err != nil {
+		return nil
+	}
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		s := snapshots[i]
+		if s.Response == nil || s.Response.Records == nil || s.Response.Request == nil {
+			continue
+		}
+		if sameFile(req.URL, s.Response.requestedURL()) || sameFile(req.URL, s.Response.Request.URL) {
+			return s
+		}
+	}
+	return nil
+}
+
+// requestedURL return URL response was requested at, before following any redirect
+func (r *Response) requestedURL() string {
+	if len(r.Redirects) > 0 {
+		return r.Redirects[0].From
+	}
+	return r.Request.URL
+}
+
+// fileLocation return host (lower case, without port and www. prefix) and path of file URL
+func fileLocation(rawurl string) (string, string) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", rawurl
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), strings.TrimSuffix(u.Path, "/")
+}
+
+// sameFile check if URLs locate the same file, regardless of scheme and www. host prefix (e.g. fetch raced over
+// both schemes, see WithRace)
+func sameFile(a string, b string) bool {
+	ah, ap := fileLocation(a)
+	bh, bp := fileLocation(b)
+	return ah == bh && ap == bp
+}
+
+// conditional set HTTP conditional request headers, using validators of baseline snapshot
+func (s *Snapshot) conditional(r *http.Request) {
+	if len(s.ETag) > 0 {
+		r.Header.Set("If-None-Match", s.ETag)
+	}
+	if len(s.LastModified) > 0 {
+		r.Header.Set("If-Modified-Since", s.LastModified)
+	}
+}
+
+// unchanged check if Ads.txt file body is the same as the baseline snapshot body
+func (s *Snapshot) unchanged(body []byte) bool {
+	return len(s.Digest) > 0 && bodyDigest(bodyLines(body)) == s.Digest
+}
+
+// unchangedResponse create response of Ads.txt file which didn't change since request baseline snapshot. Response
+// records are the baseline snapshot records (shared with the store, and must not be modified)
+func (c *Crawler) unchangedResponse(req *Request, res *http.Response, redirects []*RedirectEvent, timings []*Timing) *Response {
+	expires, _ := c.expiration(res, time.Now())
+
+	response := &Response{
+		Request:   req,
+		Records:   req.baseline.Response.Records,
+		Expires:   expires,
+		Headers:   c.captureHeaders(res),
+		Redirects: redirects,
+		Timings:   timings,
+		Unchanged: true,
+	}
+	response.setValidators(res)
+
+	// server doesn't have to send validators on 304 response, keep the baseline ones
+	if res.StatusCode == http.StatusNotModified && len(response.ETag) == 0 && len(response.LastModified) == 0 {
+		response.ETag, response.LastModified = req.baseline.ETag, req.baseline.LastModified
+	}
+	return response
+}
+
+// setValidators set HTTP cache validators of response, used by differential crawl conditional requests
+func (r *Response) setValidators(res *http.Response) {
+	r.ETag = res.Header.Get("ETag")
+	r.LastModified = res.Header.Get("Last-Modified")
+}
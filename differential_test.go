@@ -0,0 +1,118 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestDifferential test differential crawl short-circuit files not modified since the stored snapshot
+func TestDifferential(t *testing.T) {
+	body := "greenadexchange.com,XF7342,DIRECT"
+	conditional := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// ETag is sent only for the versioned path, other paths ignore conditional requests
+		if r.URL.Path == "/ads.txt" {
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				conditional++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/moved/ads.txt", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, body)
+		if r.URL.Path == "/changed/ads.txt" {
+			io.WriteString(w, "\ngreenadexchange.com,XF7343,RESELLER")
+		}
+	}))
+	defer ts.Close()
+
+	store := NewMemoryStore()
+	c := NewCrawler(WithDifferential(store))
+
+	// the first crawl of domain isn't differential
+	res, err := c.Get(&Request{URL: ts.URL + "/ads.txt", Domain: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Unchanged || res.ETag != `"v1"` || len(res.DataRecords) != 1 {
+		t.Fatalf("Expected parsed response with ETag and not [%t] [%s]", res.Unchanged, res.ETag)
+	}
+	store.Put(NewSnapshot(res, time.Now()))
+
+	// same body 200 response short-circuit parsing, but only against the baseline of the same file
+	redirected := &Request{URL: ts.URL + "/moved", Domain: "127.0.0.1"}
+	res, err = c.Get(redirected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Put(NewSnapshot(res, time.Now()))
+
+	expected := map[string][2]int{
+		"/ads.txt":         {1, 1}, // 304 on conditional request
+		"/moved":           {1, 1}, // 200 of the same body, baseline found by request URL before redirect
+		"/v2/ads.txt":      {0, 1}, // other file of the domain
+		"/changed/ads.txt": {0, 2},
+	}
+	for path, e := range expected {
+		unchanged, records := e[0] == 1, e[1]
+		res, err := c.Get(&Request{URL: ts.URL + path, Domain: "127.0.0.1"})
+		if err != nil {
+			t.Fatalf("[%s] %s", path, err)
+		}
+		if res.Unchanged != unchanged {
+			t.Errorf("[%s] Expected unchanged response [%t] and not [%t]", path, unchanged, res.Unchanged)
+		}
+
+		if len(res.DataRecords) != records {
+			t.Errorf("[%s] Expected [%d] data records and not [%d]", path, records, len(res.DataRecords))
+		}
+	}
+
+	if conditional != 1 {
+		t.Errorf("Expected single conditional request to be served with 304 and not [%d]", conditional)
+	}
+}
+
+// TestDifferentialOtherFile test snapshot of other file of the same domain is not used as differential crawl baseline
+func TestDifferentialOtherFile(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// CDN serve validators which don't depend on the file
+		w.Header().Set("ETag", `"cdn"`)
+		if r.Header.Get("If-None-Match") == `"cdn"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/app-ads.txt" {
+			io.WriteString(w, "google.com,APP1,DIRECT")
+			return
+		}
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	store := NewMemoryStore()
+	c := NewCrawler(WithDifferential(store))
+
+	app, err := c.Get(&Request{URL: ts.URL + "/app-ads.txt", Domain: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Put(NewSnapshot(app, time.Now()))
+
+	res, err := c.Get(&Request{URL: ts.URL + "/ads.txt", Domain: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Unchanged || len(res.DataRecords) != 1 || res.DataRecords[0].PublisherAccountID != "XF7342" {
+		t.Errorf("Expected ads.txt records and not baseline of app-ads.txt [%t] [%+v]", res.Unchanged, res.DataRecords)
+	}
+}
@@ -0,0 +1,97 @@
+package adstxt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// maxLabelLength maximum length of domain name label (DNS label limit)
+const maxLabelLength = 63
+
+// Domain parse errors
+const (
+	errDomainEmpty  = "invalid domain [%s]: empty domain name"
+	errDomainLength = "invalid domain [%s]: domain name is longer than %d characters"
+	errDomainLabel  = "invalid domain [%s]: invalid label [%s]"
+	errDomainIDNA   = "invalid domain [%s]: %s"
+)
+
+// Domain normalized domain name: lower case ASCII labels, with internationalized labels in punycode (A-label) form.
+// Domains parsed by ParseDomain compare equal regardless of the case, trailing dot or Unicode\punycode form they were
+// declared in (e.g. "Bücher.DE." and "xn--bcher-kva.de"). Request and DataRecord domains are typed, domains of stored
+// and reported values (e.g. Snapshot, DomainHealth, Event or ReverseRecord) remain strings holding the normalized form
+type Domain string
+
+// ParseDomain validate and normalize domain name using IDNA lookup profile (RFC 5891): labels are mapped (e.g. lower
+// cased), must be letters, digits or hyphens (not at label start or end) once punycode encoded, and 1 to 63 characters
+// long
+func ParseDomain(name string) (Domain, error) {
+	s := strings.TrimSuffix(strings.TrimSpace(name), ".")
+	if len(s) == 0 {
+		return "", fmt.Errorf(errDomainEmpty, name)
+	}
+
+	d, err := idna.Lookup.ToASCII(s)
+	if err != nil {
+		return "", fmt.Errorf(errDomainIDNA, name, err.Error())
+	}
+	for _, label := range strings.Split(d, ".") {
+		if len(label) == 0 || len(label) > maxLabelLength {
+			return "", fmt.Errorf(errDomainLabel, name, label)
+		}
+	}
+	if len(d) > MaxDomainLength {
+		return "", fmt.Errorf(errDomainLength, name, MaxDomainLength)
+	}
+	return Domain(d), nil
+}
+
+// String return domain name
+func (d Domain) String() string {
+	return string(d)
+}
+
+// Registrable return registrable domain ("public suffix" plus one label) of domain, which Ads.txt file is posted
+// on. Domain of private or unknown suffix (e.g. localhost) is its own registrable domain
+func (d Domain) Registrable() Domain {
+	r, err := publicsuffix.EffectiveTLDPlusOne(string(d))
+	if err != nil {
+		s, _ := publicsuffix.PublicSuffix(string(d))
+		return Domain(s)
+	}
+	return Domain(r)
+}
+
+// Punycode return ASCII (A-label) form of domain, as sent in HTTP requests and DNS queries
+func (d Domain) Punycode() string {
+	return string(d)
+}
+
+// Unicode return Unicode (U-label) form of domain for display, domain is returned as is if it can't be decoded
+func (d Domain) Unicode() string {
+	u, err := idna.Lookup.ToUnicode(string(d))
+	if err != nil {
+		return string(d)
+	}
+	return u
+}
+
+// Equal check if domain is the same domain as name, once name is normalized
+func (d Domain) Equal(name string) bool {
+	other, err := ParseDomain(name)
+	return err == nil && other == d
+}
+
+// isASCII check if string holds ASCII characters only
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package adstxt
+
+import (
+	"testing"
+)
+
+// TestParseDomain test domain names are validated and normalized
+func TestParseDomain(t *testing.T) {
+	domains := map[string]Domain{
+		"example.com":            "example.com",
+		"  Example.COM. ":        "example.com",
+		"sub-domain.example.com": "sub-domain.example.com",
+		"bücher.de":              "xn--bcher-kva.de",
+		"BÜCHER.de":              "xn--bcher-kva.de",
+		"xn--bcher-kva.de":       "xn--bcher-kva.de",
+		"例え。テスト":                 "xn--r8jz45g.xn--zckzah",
+		"127.0.0.1":              "127.0.0.1",
+	}
+	for k, v := range domains {
+		if d, err := ParseDomain(k); err != nil || d != v {
+			t.Errorf("Expected domain [%s] to be normalized to [%s] and not [%s] [%v]", k, v, d, err)
+		}
+	}
+
+	invalid := []string{"", ".", "example..com", "-example.com", "example-.com", "exa_mple.com", "example.com/ads.txt", "xn--zz$.com", string(make([]byte, 64)) + ".com"}
+	for _, name := range invalid {
+		if d, err := ParseDomain(name); err == nil {
+			t.Errorf("Expected domain [%s] to be invalid and not [%s]", name, d)
+		}
+	}
+}
+
+// TestDomainForms test registrable, punycode and Unicode forms of domain
+func TestDomainForms(t *testing.T) {
+	domains := map[string][3]string{
+		"www.example.com":        {"example.com", "www.example.com", "www.example.com"},
+		"shop.bücher.de":         {"xn--bcher-kva.de", "shop.xn--bcher-kva.de", "shop.bücher.de"},
+		"news.example.co.uk":     {"example.co.uk", "news.example.co.uk", "news.example.co.uk"},
+		"xn--mnchen-3ya.de":      {"xn--mnchen-3ya.de", "xn--mnchen-3ya.de", "münchen.de"},
+		"xn--h1alffa9f.xn--p1ai": {"xn--h1alffa9f.xn--p1ai", "xn--h1alffa9f.xn--p1ai", "россия.рф"},
+		"localhost":              {"localhost", "localhost", "localhost"},
+	}
+	for k, v := range domains {
+		d, err := ParseDomain(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := d.Registrable(); r.String() != v[0] {
+			t.Errorf("Expected registrable domain of [%s] to be [%s] and not [%s]", k, v[0], r)
+		}
+		if p := d.Punycode(); p != v[1] {
+			t.Errorf("Expected punycode form of [%s] to be [%s] and not [%s]", k, v[1], p)
+		}
+		if u := d.Unicode(); u != v[2] {
+			t.Errorf("Expected Unicode form of [%s] to be [%s] and not [%s]", k, v[2], u)
+		}
+		if !d.Equal(v[2]) {
+			t.Errorf("Expected [%s] to be equal to [%s]", k, v[2])
+		}
+	}
+}
+
+// TestDomainNormalization test request and record domains are normalized, so they compare equal regardless of the
+// form they were declared in
+func TestDomainNormalization(t *testing.T) {
+	for _, host := range []string{"http://WWW.Bücher.de/", "xn--bcher-kva.de", "https://shop.BÜCHER.DE:8443"} {
+		req, err := NewRequest(host)
+		if err != nil || req.Domain != "xn--bcher-kva.de" {
+			t.Errorf("Expected request domain of [%s] to be normalized [%v] [%v]", host, req, err)
+		}
+	}
+	// hosts which are not valid domain names are crawled as is
+	if req, err := NewRequest("http://foo_bar.example.com/"); err != nil || req.Domain != "example.com" {
+		t.Errorf("Expected request of non strict host name to be accepted [%v] [%v]", req, err)
+	}
+
+	rec, err := ParseBody([]byte("GOOGLE.com,pub-1234,DIRECT"))
+	if err != nil || len(rec.DataRecords) != 1 {
+		t.Fatalf("Expected single data record [%v]", err)
+	}
+	if r := rec.DataRecords[0]; r.AdverterDomain != "google.com" || r.OriginalAdverterDomain != "GOOGLE.com" {
+		t.Errorf("Expected ad system domain to be normalized and original domain to be kept [%+v]", r)
+	}
+}
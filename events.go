@@ -0,0 +1,78 @@
+package adstxt
+
+import (
+	"time"
+)
+
+// Crawl event types
+const (
+	// EventRequestStarted HTTP request is sent (every redirect hop and retry is a separate request)
+	EventRequestStarted = "request-started"
+	// EventRequestFailed HTTP request failed before response was received (e.g. DNS, connection or timeout errors)
+	EventRequestFailed = "request-failed"
+	// EventResponseReceived HTTP response headers were received
+	EventResponseReceived = "response-received"
+	// EventRedirect redirect was handled, the event redirect holds the redirect policy decision
+	EventRedirect = "redirect"
+	// EventParseFinished Ads.txt file was parsed
+	EventParseFinished = "parse-finished"
+	// EventUnchanged Ads.txt file didn't change since the stored snapshot, and was not parsed (see WithDifferential)
+	EventUnchanged = "unchanged"
+	// EventValidationFinding Ads.txt file warning, single event per warning once the file is parsed
+	EventValidationFinding = "validation-finding"
+)
+
+// Event single typed crawl event, reported to crawler event sink (see WithEventSink)
+type Event struct {
+	Type     string         `json:"type"`               // Type of the event (see Event* types)
+	Time     time.Time      `json:"time"`               // Time the event occurred at
+	Domain   string         `json:"domain"`             // Domain request root domain
+	Tenant   string         `json:"tenant,omitempty"`   // Tenant on behalf of which the domain is crawled
+	URL      string         `json:"url"`                // URL of the request
+	Status   int            `json:"status,omitempty"`   // Status HTTP response status code (response-received)
+	Redirect *RedirectEvent `json:"redirect,omitempty"` // Redirect handled redirect (redirect)
+	Warning  *Warning       `json:"warning,omitempty"`  // Warning validation finding (validation-finding)
+	Lines    int            `json:"lines,omitempty"`    // Lines number of parsed lines (parse-finished)
+	Records  int            `json:"records,omitempty"`  // Records number of parsed data records (parse-finished)
+	Error    string         `json:"error,omitempty"`    // Error of failed request (request-failed)
+}
+
+// The EventSink interface is used to consume crawl events, e.g. to keep audit trail or report crawl progress.
+// Events of concurrent requests are reported concurrently, sink must be safe for concurrent use
+type EventSink interface {
+	Event(*Event)
+}
+
+// A EventSinkFunc is a function signature that implements the EventSink interface
+type EventSinkFunc func(*Event)
+
+// Event is the EventSink interface implementation for the EventSinkFunc type
+func (f EventSinkFunc) Event(e *Event) {
+	f(e)
+}
+
+// emit report event of request to crawler event sink, when set
+func (c *Crawler) emit(req *Request, e *Event) {
+	if c.events == nil {
+		return
+	}
+
+	e.Time = time.Now()
+	e.Domain, e.Tenant = req.Domain.String(), req.Tenant
+	if len(e.URL) == 0 {
+		e.URL = req.URL
+	}
+	c.events.Event(e)
+}
+
+// emitParsed report parse finished event and validation finding events of parsed Ads.txt file
+func (c *Crawler) emitParsed(req *Request, records *Records) {
+	if c.events == nil {
+		return
+	}
+
+	c.emit(req, &Event{Type: EventParseFinished, Lines: len(records.Body), Records: len(records.DataRecords)})
+	for _, w := range records.Warnings {
+		c.emit(req, &Event{Type: EventValidationFinding, Warning: w})
+	}
+}
@@ -0,0 +1,57 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestEventSink test crawl events are reported in order to event sink
+func TestEventSink(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ads.txt" {
+			http.Redirect(w, r, "/v2/ads.txt", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT\nunparsable line")
+	}))
+	defer ts.Close()
+
+	var lock sync.Mutex
+	events := []*Event{}
+	sink := EventSinkFunc(func(e *Event) {
+		lock.Lock()
+		defer lock.Unlock()
+		events = append(events, e)
+	})
+
+	if _, err := NewCrawler(WithEventSink(sink)).Get(&Request{URL: ts.URL + "/ads.txt", Domain: "127.0.0.1", Tenant: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		EventRequestStarted, EventResponseReceived, EventRedirect,
+		EventRequestStarted, EventResponseReceived, EventParseFinished, EventValidationFinding,
+	}
+	types := []string{}
+	for _, e := range events {
+		types = append(types, e.Type)
+		if e.Domain != "127.0.0.1" || e.Tenant != "acme" || e.Time.IsZero() {
+			t.Errorf("[%s] Unexpected event request details [%s] [%s]", e.Type, e.Domain, e.Tenant)
+		}
+	}
+	if strings.Join(types, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected events %v and not %v", expected, types)
+	}
+
+	if events[1].Status != http.StatusMovedPermanently || events[2].Redirect.Decision != RedirectFollowed {
+		t.Errorf("Unexpected redirect events [%d] [%s]", events[1].Status, events[2].Redirect.Decision)
+	}
+	if events[5].Lines != 2 || events[5].Records != 1 || events[6].Warning.Code != WarnUnparsableLine {
+		t.Errorf("Unexpected parse events [%d] [%d] [%s]", events[5].Lines, events[5].Records, events[6].Warning.Code)
+	}
+}
@@ -0,0 +1,102 @@
+package adstxt
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ads.txt file expiration date sources
+const (
+	// ExpiresSourceHeader expiration date was set by the response Expires header
+	ExpiresSourceHeader = "expires-header"
+	// ExpiresSourceCacheControl expiration date was set by the response Cache-Control max-age directive
+	ExpiresSourceCacheControl = "cache-control"
+	// ExpiresSourceDefault remote host did not set expiration date, specification default of 7 days is used
+	ExpiresSourceDefault = "default"
+	// ExpiresSourceOverride expiration date was overridden by the caller (e.g. crawl scheduler)
+	ExpiresSourceOverride = "override"
+)
+
+// defaultExpiration Ads.txt file default expiration (secion 3.6 EXPIRATION of IAB Ads.txt specification)
+const defaultExpiration = 7 * 24 * time.Hour
+
+// Expiration hold Ads.txt file expiration date and where it came from
+type Expiration struct {
+	Time      time.Time `json:"time"`      // Time Ads.txt file expiration date
+	Source    string    `json:"source"`    // Source of the expiration date (expires-header, cache-control, default or override)
+	RefreshAt time.Time `json:"refreshAt"` // RefreshAt date on which Ads.txt file should be crawled again
+}
+
+// newExpiration create new expiration date. Refresh date is the expiration date, unless it is already passed on fetch time
+func newExpiration(t time.Time, source string, fetched time.Time) Expiration {
+	e := Expiration{Time: t.UTC(), Source: source, RefreshAt: t.UTC()}
+	if e.RefreshAt.Before(fetched) {
+		e.RefreshAt = fetched.UTC()
+	}
+	return e
+}
+
+// Authoritative check if expiration date was set by the remote host (and not by default or override)
+func (e Expiration) Authoritative() bool {
+	return e.Source == ExpiresSourceHeader || e.Source == ExpiresSourceCacheControl
+}
+
+// Override set expiration and refresh date, the original source is replaced by override
+func (e *Expiration) Override(t time.Time) {
+	e.Time = t.UTC()
+	e.RefreshAt = t.UTC()
+	e.Source = ExpiresSourceOverride
+}
+
+// expiration return Ads.txt file expiration date from the response headers. Cache-Control max-age takes
+// precedence over Expires header (RFC 7234 section 5.3), else specification default is used
+func (c *Crawler) expiration(res *http.Response, fetched time.Time) (Expiration, *Warning) {
+	if maxAge, ok := parseMaxAge(res); ok {
+		return newExpiration(fetched.Add(maxAge), ExpiresSourceCacheControl, fetched), nil
+	}
+
+	expires, w, err := c.parseExpires(res)
+	if err == nil {
+		return newExpiration(expires, ExpiresSourceHeader, fetched), w
+	}
+
+	return newExpiration(fetched.Add(defaultExpiration), ExpiresSourceDefault, fetched), w
+}
+
+// maxDeltaSeconds largest delta-seconds value, greater values (and values out of int range) are clamped to it
+// (RFC 9111 section 1.2.2)
+const maxDeltaSeconds = 1 << 31
+
+// parseMaxAge parse max-age directive from the response Cache-Control header. When multiple directives are sent
+// the smallest one is used
+func parseMaxAge(res *http.Response) (time.Duration, bool) {
+	maxAge := -1
+	for _, v := range headerValues(res, "Cache-Control", true) {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 || strings.ToLower(strings.TrimSpace(kv[0])) != "max-age" {
+			continue
+		}
+
+		s, err := strconv.Atoi(strings.Trim(strings.TrimSpace(kv[1]), `"`))
+		if errors.Is(err, strconv.ErrRange) && s > 0 {
+			err = nil
+		}
+		if err != nil || s < 0 {
+			continue
+		}
+		if s > maxDeltaSeconds {
+			s = maxDeltaSeconds
+		}
+		if maxAge == -1 || s < maxAge {
+			maxAge = s
+		}
+	}
+
+	if maxAge == -1 {
+		return 0, false
+	}
+	return time.Duration(maxAge) * time.Second, true
+}
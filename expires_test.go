@@ -0,0 +1,78 @@
+package adstxt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestExpiration test Ads.txt file expiration date source attribution
+func TestExpiration(t *testing.T) {
+	fetched := time.Date(2044, 11, 1, 8, 49, 37, 0, time.UTC)
+
+	type expected struct {
+		source    string
+		time      time.Time
+		refreshAt time.Time
+	}
+
+	headers := map[string]struct {
+		header http.Header
+		expected
+	}{
+		"expires": {
+			http.Header{"Expires": {"Sat, 05 Nov 2044 08:49:37 GMT"}},
+			expected{ExpiresSourceHeader, fetched.Add(96 * time.Hour), fetched.Add(96 * time.Hour)},
+		},
+		"cache-control": {
+			http.Header{"Expires": {"Sat, 05 Nov 2044 08:49:37 GMT"}, "Cache-Control": {"public, max-age=3600"}},
+			expected{ExpiresSourceCacheControl, fetched.Add(time.Hour), fetched.Add(time.Hour)},
+		},
+		"expired": {
+			http.Header{"Expires": {"Sat, 05 Nov 2033 08:49:37 GMT"}},
+			expected{ExpiresSourceHeader, time.Date(2033, 11, 5, 8, 49, 37, 0, time.UTC), fetched},
+		},
+		"max-age-overflow": {
+			http.Header{"Cache-Control": {"max-age=99999999999999999999"}},
+			expected{ExpiresSourceCacheControl, fetched.Add(maxDeltaSeconds * time.Second), fetched.Add(maxDeltaSeconds * time.Second)},
+		},
+		"max-age-duration-overflow": {
+			http.Header{"Cache-Control": {"max-age=9999999999999"}},
+			expected{ExpiresSourceCacheControl, fetched.Add(maxDeltaSeconds * time.Second), fetched.Add(maxDeltaSeconds * time.Second)},
+		},
+		"invalid-expires": {
+			http.Header{"Expires": {"tomorrow"}},
+			expected{ExpiresSourceDefault, fetched.Add(defaultExpiration), fetched.Add(defaultExpiration)},
+		},
+		"default": {
+			http.Header{"Cache-Control": {"no-cache"}, "Expires": {"-1"}},
+			expected{ExpiresSourceDefault, fetched.Add(defaultExpiration), fetched.Add(defaultExpiration)},
+		},
+	}
+
+	c := NewCrawler()
+	for name, h := range headers {
+		res := &http.Response{Header: h.header, Request: httptest.NewRequest("GET", "http://example.com/ads.txt", nil)}
+		e, _ := c.expiration(res, fetched)
+
+		if e.Source != h.source || !e.Time.Equal(h.time) || !e.RefreshAt.Equal(h.refreshAt) {
+			t.Errorf("[%s] Expected expiration [%s] [%s] [%s] and not [%s] [%s] [%s]", name, h.source, h.time, h.refreshAt, e.Source, e.Time, e.RefreshAt)
+		}
+		if e.Authoritative() != (h.source != ExpiresSourceDefault) {
+			t.Errorf("[%s] Expected expiration from [%s] authoritative to be [%t]", name, e.Source, !e.Authoritative())
+		}
+	}
+}
+
+// TestExpirationOverride test overridden expiration is not authoritative
+func TestExpirationOverride(t *testing.T) {
+	now := time.Now()
+
+	e := newExpiration(now.Add(time.Hour), ExpiresSourceHeader, now)
+	e.Override(now.Add(time.Minute))
+
+	if e.Source != ExpiresSourceOverride || e.Authoritative() || !e.RefreshAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected overridden expiration [%s] refresh at [%s]", e.Source, e.RefreshAt)
+	}
+}
@@ -0,0 +1,142 @@
+package adstxt
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Exported record types
+const (
+	exportTypeData     = "data"
+	exportTypeVariable = "variable"
+)
+
+// ExportColumns holds the columns of the flattened export schema. Each exported row holds single Ads.txt record
+// (data record or variable) together with the crawl metadata of the response it was parsed from
+var ExportColumns = []string{
+	"domain",               // root domain of the remote host
+	"url",                  // URL the Ads.txt file was fetched from (after redirects)
+	"expires",              // Ads.txt file expiration date (RFC 3339, UTC)
+	"record_type",          // "data" or "variable"
+	"adsystem_domain",      // data record: domain name of the advertising system
+	"original_adsystem",    // data record: advertising system domain as declared in the file, when normalized
+	"publisher_account_id", // data record: publisher account ID
+	"account_type",         // data record: DIRECT or RESELLER
+	"cert_authority_id",    // data record: certification authority ID (optional)
+	"variable_type",        // variable record: variable type
+	"variable_value",       // variable record: variable value
+	"flags",                // record quality flags, comma separated
+	"tenant",               // tenant on behalf of which the file was crawled (see Tenants)
+	"score",                // Ads.txt file score, when scored (see WithScoring)
+}
+
+// ExportSchema SQL table definition matching the export columns, to be used with Postgres\Redshift COPY
+// command in CSV format (e.g. COPY adstxt_records FROM STDIN WITH (FORMAT csv, HEADER true)). There are no crawl
+// date and HTTP status columns as the response doesn't hold them: only successfully crawled files are exported, and
+// crawl date is recorded by store snapshots (see Snapshot)
+var ExportSchema = fmt.Sprintf(`CREATE TABLE adstxt_records (
+	domain               VARCHAR(255) NOT NULL,
+	url                  VARCHAR(2048) NOT NULL,
+	expires              TIMESTAMP,
+	record_type          VARCHAR(16) NOT NULL,
+	adsystem_domain      VARCHAR(255),
+	original_adsystem    VARCHAR(255),
+	publisher_account_id VARCHAR(%[1]d),
+	account_type         VARCHAR(16),
+	cert_authority_id    VARCHAR(%[1]d),
+	variable_type        VARCHAR(64),
+	variable_value       VARCHAR(2048),
+	flags                VARCHAR(255),
+	tenant               VARCHAR(255),
+	score                NUMERIC(5,2)
+);`, MaxAccountIDLength)
+
+// Exporter write Ads.txt responses as flattened CSV rows (see ExportColumns)
+type Exporter struct {
+	w  *csv.Writer
+	gz *gzip.Writer // compressed output, nil when rows are written uncompressed (see NewGzipExporter)
+}
+
+// NewExporter create new CSV exporter writing to w
+func NewExporter(w io.Writer) *Exporter {
+	return &Exporter{w: csv.NewWriter(w)}
+}
+
+// WriteHeader write columns header row
+func (e *Exporter) WriteHeader() error {
+	return e.w.Write(ExportColumns)
+}
+
+// Write write single row for each of the response records
+func (e *Exporter) Write(res *Response) error {
+	return e.w.WriteAll(ExportRows(res))
+}
+
+// Flush write any buffered rows to the underlying writer
+func (e *Exporter) Flush() error {
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return err
+	}
+	if e.gz != nil {
+		return e.gz.Flush()
+	}
+	return nil
+}
+
+// Close flush buffered rows, and write the end of compressed stream (the underlying writer is not closed)
+func (e *Exporter) Close() error {
+	if err := e.Flush(); err != nil {
+		return err
+	}
+	if e.gz != nil {
+		return e.gz.Close()
+	}
+	return nil
+}
+
+// ExportRows flatten Ads.txt response into rows matching the export columns
+func ExportRows(res *Response) [][]string {
+	rows := [][]string{}
+	if res == nil || res.Records == nil {
+		return rows
+	}
+
+	var domain, url, expires, tenant, score string
+	if res.Request != nil {
+		domain, url, tenant = res.Request.Domain.String(), res.Request.URL, res.Request.Tenant
+	}
+	if res.Score != nil {
+		score = strconv.FormatFloat(res.Score.Score, 'f', -1, 64)
+	}
+	if !res.Expires.Time.IsZero() {
+		expires = res.Expires.Time.UTC().Format(time.RFC3339)
+	}
+
+	for _, r := range res.DataRecords {
+		rows = append(rows, []string{
+			domain, url, expires, exportTypeData,
+			r.AdverterDomain.String(), r.OriginalAdverterDomain, r.PublisherAccountID, r.AccountType, r.CertAuthorityID,
+			"", "",
+			strings.Join(r.Flags, ","),
+			tenant, score,
+		})
+	}
+
+	for _, v := range res.Variables {
+		rows = append(rows, []string{
+			domain, url, expires, exportTypeVariable,
+			"", "", "", "", "",
+			v.Type, v.Value,
+			strings.Join(v.Flags, ","),
+			tenant, score,
+		})
+	}
+
+	return rows
+}
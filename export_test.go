@@ -0,0 +1,56 @@
+package adstxt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExport test flattening Ads.txt response into CSV rows
+func TestExport(t *testing.T) {
+	rec, err := ParseBody([]byte("greenadexchange.com,XF7342,direct,5jyxf8k54\nsubdomain=dev.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := &Response{
+		Request: &Request{Domain: "example.com", URL: "http://example.com/ads.txt", Tenant: "acme"},
+		Records: rec,
+		Expires: newExpiration(time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC), ExpiresSourceHeader, time.Time{}),
+		Score:   &Score{Score: 97.5},
+	}
+
+	var b bytes.Buffer
+	e := NewExporter(&b)
+	e.WriteHeader()
+	e.Write(res)
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		strings.Join(ExportColumns, ","),
+		"example.com,http://example.com/ads.txt,2044-11-05T08:49:37Z,data,greenadexchange.com,,XF7342,DIRECT,5jyxf8k54,,,normalized,acme,97.5",
+		"example.com,http://example.com/ads.txt,2044-11-05T08:49:37Z,variable,,,,,,subdomain,dev.example.com,,acme,97.5",
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected [%d] exported lines and not [%d]", len(expected), len(lines))
+	}
+
+	for index, l := range lines {
+		if l != expected[index] {
+			t.Errorf("Expected exported line #%d to be [%s] and not [%s]", index, expected[index], l)
+		}
+	}
+
+	if columns := strings.Count(ExportSchema, "\n\t"); columns != len(ExportColumns) {
+		t.Errorf("Expected export schema of [%d] columns and not [%d]", len(ExportColumns), columns)
+	}
+	if id := fmt.Sprintf("publisher_account_id VARCHAR(%d)", MaxAccountIDLength); !strings.Contains(ExportSchema, id) {
+		t.Errorf("Expected export schema to include [%s]", id)
+	}
+}
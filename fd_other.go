@@ -0,0 +1,8 @@
+//go:build !unix
+
+package adstxt
+
+// openFiles open file descriptors usage is not available on the platform
+func openFiles() (int, int, bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package adstxt
+
+import (
+	"os"
+	"syscall"
+)
+
+// openFiles return number of open file descriptors of the process and their soft limit
+func openFiles() (int, int, bool) {
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return 0, 0, false
+	}
+
+	fds, err := os.ReadDir("/dev/fd")
+	if err != nil {
+		return 0, 0, false
+	}
+	return len(fds), int(rl.Cur), true
+}
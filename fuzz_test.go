@@ -0,0 +1,74 @@
+package adstxt
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// FuzzParseBody fuzz Ads.txt parser with untrusted file content (go test -fuzz=FuzzParseBody)
+func FuzzParseBody(f *testing.F) {
+	seeds := []string{
+		"greenadexchange.com,XF7342,DIRECT,5jyxf8k54\nsubdomain=dev.example.com",
+		"greenadexchange.com, XF7342, reseller # comment\r\ncontact=ads@example.com\r",
+		"\ufeffgreenadexchange.com,\u200bXF7342,DIRECT\n\n#\n=\n,,,\n",
+		"<html><body>404</body></html>",
+		strings.Repeat("x", MaxLineLength+1) + "\r\ngreenadexchange.com,XF7342,DIRECT",
+	}
+	for _, s := range seeds {
+		f.Add([]byte(s))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		rec, err := ParseBody(b)
+		if err != nil {
+			t.Fatalf("Unexpected parse error [%s]", err)
+		}
+
+		for i, l := range rec.Body {
+			if len(l) > MaxLineLength {
+				t.Errorf("Line #%d is longer than [%d] bytes", i+1, MaxLineLength)
+			}
+		}
+		if len(rec.DataRecords)+len(rec.Variables)+len(rec.Skipped) != len(rec.Body) {
+			t.Errorf("Expected every line to be either parsed into single record or skipped")
+		}
+		for _, w := range rec.Warnings {
+			if w.Index < 1 || w.Index > len(rec.Body) {
+				t.Errorf("Warning line index [%d] is out of body range [%d]", w.Index, len(rec.Body))
+			}
+		}
+	})
+}
+
+// FuzzHandleRedirect fuzz redirect handler with untrusted Location header values
+func FuzzHandleRedirect(f *testing.F) {
+	seeds := []string{
+		"https://example.com/ads.txt",
+		"/v2/ads.txt",
+		"//other.com/ads.txt",
+		"https://example.com/",
+		"https://example.com/ADS.TXT?v=1",
+		"http://[::1]:namedport",
+		"%zz",
+	}
+	for _, s := range seeds {
+		f.Add(s, false)
+	}
+
+	c := NewCrawler()
+	f.Fuzz(func(t *testing.T, location string, lenient bool) {
+		req := &Request{URL: "http://example.com/ads.txt", Domain: "example.com", Lenient: lenient}
+		res := &http.Response{StatusCode: http.StatusMovedPermanently, Header: http.Header{"Location": {location}}}
+
+		redirect, _, err := c.handleRedirect(req, res)
+		if err != nil {
+			return
+		}
+		if u, err := url.Parse(redirect); err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			t.Errorf("Expected followed redirect [%s] to be absolute URL", redirect)
+		}
+		newRedirectEvent(req, res, redirect, nil, nil)
+	})
+}
@@ -0,0 +1,133 @@
+package adstxt
+
+import (
+	"sort"
+	"strings"
+)
+
+// Publisher group consistency finding types
+const (
+	// GroupMissingDirect DIRECT seat declared by some group domains is not declared by other group domains
+	GroupMissingDirect = "missing-direct"
+	// GroupContradictory seat is declared as DIRECT by some group domains, and only as RESELLER by others
+	GroupContradictory = "contradictory-relationship"
+)
+
+// GroupFinding single divergence between Ads.txt files of publisher group domains
+type GroupFinding struct {
+	Type      string   `json:"type"`      // Type of the finding: missing-direct or contradictory-relationship
+	AdSystem  string   `json:"adSystem"`  // AdSystem ad system domain of the seat
+	AccountID string   `json:"accountId"` // AccountID publisher account ID of the seat
+	Declaring []string `json:"declaring"` // Declaring domains declaring the seat as DIRECT
+	Diverging []string `json:"diverging"` // Diverging domains missing the DIRECT seat, or declaring it as RESELLER only
+}
+
+// GroupReport consistency report of publisher group: domains owned by a single entity (shared OWNERDOMAIN)
+type GroupReport struct {
+	OwnerDomain string          `json:"ownerDomain"` // OwnerDomain shared owner domain of the group
+	Domains     []string        `json:"domains"`     // Domains group publisher domains (sorted)
+	Findings    []*GroupFinding `json:"findings"`    // Findings divergences between group domains, none when consistent
+}
+
+// Consistent check if all group domains Ads.txt files are mutually consistent
+func (g *GroupReport) Consistent() bool {
+	return len(g.Findings) == 0
+}
+
+// Groups group Ads.txt responses by declared OWNERDOMAIN, and check each group (of at least two domains) for
+// consistency. Reports are sorted by owner domain
+func Groups(responses []*Response) []*GroupReport {
+	groups := map[string][]*Response{}
+	for _, res := range responses {
+		if res == nil || res.Request == nil || res.Records == nil {
+			continue
+		}
+
+		owners := map[string]bool{}
+		for _, v := range res.Variables {
+			if v.Type == varTypeOwnerDomain {
+				owners[strings.ToLower(removeComment(v.Value))] = true
+			}
+		}
+		for o := range owners {
+			groups[o] = append(groups[o], res)
+		}
+	}
+
+	reports := []*GroupReport{}
+	for owner, group := range groups {
+		if len(group) > 1 {
+			reports = append(reports, CheckGroup(owner, group))
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].OwnerDomain < reports[j].OwnerDomain
+	})
+	return reports
+}
+
+// CheckGroup check Ads.txt responses of publisher group domains are mutually consistent: every group domain declares
+// the same DIRECT seats per ad system, and no seat is declared as DIRECT by one domain and as RESELLER by another
+func CheckGroup(owner string, responses []*Response) *GroupReport {
+	report := &GroupReport{OwnerDomain: owner, Domains: []string{}, Findings: []*GroupFinding{}}
+
+	// account types declared for each seat by each domain
+	seats := map[sellerKey]map[string]map[string]bool{}
+	for _, res := range responses {
+		if res == nil || res.Request == nil || res.Records == nil {
+			continue
+		}
+		domain := res.Request.Domain.String()
+		report.Domains = appendFlag(report.Domains, domain)
+
+		for _, dr := range res.DataRecords {
+			k := newSellerKey(dr.AdverterDomain.String(), strings.ToLower(dr.PublisherAccountID))
+			if seats[k] == nil {
+				seats[k] = map[string]map[string]bool{}
+			}
+			if seats[k][domain] == nil {
+				seats[k][domain] = map[string]bool{}
+			}
+			seats[k][domain][dr.AccountType] = true
+		}
+	}
+	sort.Strings(report.Domains)
+
+	for k, domains := range seats {
+		declaring, missing, reseller := []string{}, []string{}, []string{}
+		for _, d := range report.Domains {
+			types, ok := domains[d]
+			switch {
+			case !ok:
+				missing = append(missing, d)
+			case types[accountTypeDirect]:
+				declaring = append(declaring, d)
+			default:
+				reseller = append(reseller, d)
+			}
+		}
+
+		// reseller only seats are not expected to be shared by all group domains
+		if len(declaring) == 0 {
+			continue
+		}
+		if len(reseller) > 0 {
+			report.Findings = append(report.Findings, &GroupFinding{Type: GroupContradictory, AdSystem: k.adSystem, AccountID: k.accountID, Declaring: declaring, Diverging: reseller})
+		}
+		if len(missing) > 0 {
+			report.Findings = append(report.Findings, &GroupFinding{Type: GroupMissingDirect, AdSystem: k.adSystem, AccountID: k.accountID, Declaring: declaring, Diverging: missing})
+		}
+	}
+
+	sort.Slice(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.AdSystem != b.AdSystem {
+			return a.AdSystem < b.AdSystem
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		return a.Type < b.Type
+	})
+	return report
+}
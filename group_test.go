@@ -0,0 +1,47 @@
+package adstxt
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGroups test publisher group domains sharing owner domain are checked for consistency
+func TestGroups(t *testing.T) {
+	files := map[string]string{
+		"news.com":   "ownerdomain=media.com\ngreenadexchange.com,XF7342,DIRECT\nappnexus.com,1234,DIRECT",
+		"sports.com": "OWNERDOMAIN=Media.com\ngreenadexchange.com,xf7342,DIRECT\nappnexus.com,1234,RESELLER",
+		"food.com":   "ownerdomain=media.com\nappnexus.com,1234,DIRECT\nappnexus.com,1234,RESELLER",
+		"other.com":  "ownerdomain=other.com\ngreenadexchange.com,XF7342,DIRECT",
+	}
+
+	responses := []*Response{}
+	for domain, body := range files {
+		records, err := ParseBody([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, &Response{Request: &Request{Domain: Domain(domain)}, Records: records})
+	}
+
+	reports := Groups(responses)
+	if len(reports) != 1 || reports[0].OwnerDomain != "media.com" || len(reports[0].Domains) != 3 {
+		t.Fatalf("Expected single group of [3] domains owned by [media.com]")
+	}
+
+	// account ID letter case is not a divergence, food.com is missing the greenadexchange.com seat and sports.com
+	// declares appnexus.com seat as RESELLER only
+	expected := map[string]string{
+		"appnexus.com 1234 " + GroupContradictory:          "sports.com",
+		"greenadexchange.com xf7342 " + GroupMissingDirect: "food.com",
+	}
+	g := reports[0]
+	if g.Consistent() || len(g.Findings) != len(expected) {
+		t.Fatalf("Expected [%d] group findings and not [%d]", len(expected), len(g.Findings))
+	}
+	for _, f := range g.Findings {
+		key := strings.Join([]string{f.AdSystem, f.AccountID, f.Type}, " ")
+		if d, ok := expected[key]; !ok || strings.Join(f.Diverging, ",") != d {
+			t.Errorf("[%s] Unexpected group finding diverging domains %v", key, f.Diverging)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package adstxt
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// healthLatencyWeight weight of the latest crawl in domain average latency (exponential moving average)
+const healthLatencyWeight = 0.2
+
+// DomainHealth health ledger entry of single domain, updated on every crawl of the domain
+type DomainHealth struct {
+	Domain         string        `json:"domain"`                // Domain publisher root domain
+	Tenant         string        `json:"tenant,omitempty"`      // Tenant on behalf of which the domain was crawled
+	Crawls         int           `json:"crawls"`                // Crawls total number of crawls
+	Failures       int           `json:"failures"`              // Failures number of consecutive failed crawls since the last successful crawl
+	LastFailure    string        `json:"lastFailure,omitempty"` // LastFailure failure class of the last failed crawl (see FailureClass)
+	LastFailureAt  time.Time     `json:"lastFailureAt"`         // LastFailureAt date of the last failed crawl, zero when the domain never failed
+	LastSuccess    time.Time     `json:"lastSuccess"`           // LastSuccess date of the last successful crawl, zero when the domain never succeeded
+	AverageLatency time.Duration `json:"averageLatency"`        // AverageLatency moving average of crawl duration, recent crawls weight more
+	Blocked        bool          `json:"blocked"`               // Blocked remote host refuses the crawler (401, 403 or 429 responses, backoff)
+	BlockedSince   time.Time     `json:"blockedSince"`          // BlockedSince date of the first crawl refused since the last successful crawl, zero when not blocked
+}
+
+// Healthy check if domain is worth scheduling: it is not blocked and failed less than maxFailures times in a row
+func (h *DomainHealth) Healthy(maxFailures int) bool {
+	return !h.Blocked && h.Failures < maxFailures
+}
+
+// observe record crawl result of the domain, latency is not recorded for crawls refused before any request was sent
+func (h *DomainHealth) observe(err error, latency time.Duration, at time.Time) {
+	h.Crawls++
+
+	var backoffErr *BackoffError
+	if !errors.As(err, &backoffErr) {
+		if h.AverageLatency == 0 {
+			h.AverageLatency = latency
+		} else {
+			h.AverageLatency += time.Duration(healthLatencyWeight * float64(latency-h.AverageLatency))
+		}
+	}
+
+	if err == nil {
+		h.Failures = 0
+		h.LastSuccess = at
+		h.Blocked = false
+		h.BlockedSince = time.Time{}
+		return
+	}
+
+	h.Failures++
+	h.LastFailure = FailureClass(err)
+	h.LastFailureAt = at
+	if blocked(err) && !h.Blocked {
+		h.Blocked = true
+		h.BlockedSince = at
+	}
+}
+
+// blocked check if crawl error indicates that remote host refuses the crawler
+func blocked(err error) bool {
+	var backoffErr *BackoffError
+	if errors.As(err, &backoffErr) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return true
+	}
+	return false
+}
+
+// HealthStore persist domain health ledger (see WithHealthLedger). Entries are keyed by domain, scoped by tenant
+// the same way as snapshots. Implemented by MemoryStore, FileStore and TenantStore
+type HealthStore interface {
+	Health(domain string) (*DomainHealth, error)                    // Health return domain ledger entry, nil if domain was never crawled
+	UpdateHealth(domain string, update func(h *DomainHealth)) error // UpdateHealth atomically update (or create) domain ledger entry
+	HealthDomains() ([]string, error)                               // HealthDomains return sorted list of domains with ledger entry
+}
+
+// Ledger return health ledger entries of all store domains, sorted by domain
+func Ledger(s HealthStore) ([]*DomainHealth, error) {
+	domains, err := s.HealthDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := make([]*DomainHealth, 0, len(domains))
+	for _, d := range domains {
+		h, err := s.Health(d)
+		if err != nil {
+			return nil, err
+		}
+		if h != nil {
+			ledger = append(ledger, h)
+		}
+	}
+	return ledger, nil
+}
+
+// observeHealth record crawl result in crawler health ledger. Ledger is best effort, store errors don't fail the crawl
+func (c *Crawler) observeHealth(req *Request, err error, latency time.Duration) {
+	at := time.Now().UTC()
+	c.health.UpdateHealth(tenantKey(req.Tenant, req.Domain.String()), func(h *DomainHealth) {
+		h.Domain = req.Domain.String()
+		h.Tenant = req.Tenant
+		h.observe(err, latency, at)
+	})
+}
+
+// HealthColumns holds the columns of the domain health ledger export (see ExportHealthRows)
+var HealthColumns = []string{
+	"domain",          // root domain of the remote host
+	"tenant",          // tenant on behalf of which the domain was crawled
+	"crawls",          // total number of crawls
+	"failures",        // number of consecutive failed crawls since the last successful crawl
+	"last_failure",    // failure class of the last failed crawl
+	"last_failure_at", // date of the last failed crawl (RFC 3339, UTC)
+	"last_success",    // date of the last successful crawl (RFC 3339, UTC)
+	"avg_latency_ms",  // moving average of crawl duration, in milliseconds
+	"blocked",         // remote host refuses the crawler
+	"blocked_since",   // date of the first refused crawl since the last successful crawl (RFC 3339, UTC)
+}
+
+// HealthSchema SQL table definition matching the health ledger columns
+const HealthSchema = `CREATE TABLE adstxt_domain_health (
+	domain          VARCHAR(255) NOT NULL,
+	tenant          VARCHAR(255),
+	crawls          INTEGER NOT NULL,
+	failures        INTEGER NOT NULL,
+	last_failure    VARCHAR(32),
+	last_failure_at TIMESTAMP,
+	last_success    TIMESTAMP,
+	avg_latency_ms  BIGINT NOT NULL,
+	blocked         BOOLEAN NOT NULL,
+	blocked_since   TIMESTAMP
+);`
+
+// ExportHealthRows flatten domain health ledger into rows matching the health ledger columns
+func ExportHealthRows(ledger []*DomainHealth) [][]string {
+	rows := make([][]string, 0, len(ledger))
+	for _, h := range ledger {
+		rows = append(rows, []string{
+			h.Domain, h.Tenant, strconv.Itoa(h.Crawls), strconv.Itoa(h.Failures), h.LastFailure,
+			exportTime(h.LastFailureAt), exportTime(h.LastSuccess), strconv.FormatInt(h.AverageLatency.Milliseconds(), 10),
+			strconv.FormatBool(h.Blocked), exportTime(h.BlockedSince),
+		})
+	}
+	return rows
+}
+
+// WriteHealth write single row for each of the domain health ledger entries (see HealthColumns)
+func (e *Exporter) WriteHealth(ledger []*DomainHealth) error {
+	return e.w.WriteAll(ExportHealthRows(ledger))
+}
+
+// exportTime format exported date (RFC 3339, UTC), empty when not set
+func exportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,120 @@
+package adstxt
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHealthLedger test crawler record consecutive failures, last success, latency and block status of domains in
+// health ledger of the store
+func TestHealthLedger(t *testing.T) {
+	status := map[string]int{"example.com": http.StatusOK, "blocked.com": http.StatusForbidden, "failing.com": http.StatusServiceUnavailable}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status[r.Host])
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, nil)
+
+	fileStore, err := NewFileStore(t.TempDir(), JSONCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]HealthStore{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+		"tenant": NewTenantStore(NewMemoryStore(), "acme"),
+	}
+
+	for name, store := range stores {
+		c := NewCrawler(route, WithHealthLedger(store))
+		for i := 0; i < 2; i++ {
+			for domain := range status {
+				req, _ := NewRequest(domain)
+				c.Get(req)
+			}
+		}
+
+		ledger, err := Ledger(store)
+		if err != nil || len(ledger) != len(status) {
+			t.Fatalf("[%s] Expected [%d] ledger entries and not [%d] [%v]", name, len(status), len(ledger), err)
+		}
+
+		expected := map[string][2]int{"blocked.com": {2, 1}, "example.com": {0, 0}, "failing.com": {2, 0}}
+		for _, h := range ledger {
+			e := expected[h.Domain]
+			blocked := 0
+			if h.Blocked {
+				blocked = 1
+			}
+			if h.Crawls != 2 || h.Failures != e[0] || blocked != e[1] {
+				t.Errorf("[%s] Expected [%s] to have [%d] failures and blocked [%d] and not [%d] [%t]", name, h.Domain, e[0], e[1], h.Failures, h.Blocked)
+			}
+			if h.AverageLatency <= 0 {
+				t.Errorf("[%s] Expected [%s] average latency to be recorded", name, h.Domain)
+			}
+			if (h.Failures == 0) == h.LastSuccess.IsZero() {
+				t.Errorf("[%s] Expected [%s] last success only when crawl succeeded and not [%s]", name, h.Domain, h.LastSuccess)
+			}
+			if h.Healthy(2) != (h.Domain == "example.com") {
+				t.Errorf("[%s] Expected only example.com to be healthy and not [%s]", name, h.Domain)
+			}
+		}
+
+		// failing domain recovered
+		status["failing.com"] = http.StatusOK
+		req, _ := NewRequest("failing.com")
+		c.Get(req)
+		status["failing.com"] = http.StatusServiceUnavailable
+
+		h, err := store.Health("failing.com")
+		if err != nil || h == nil || h.Failures != 0 || h.LastFailure != FailureOrigin || h.LastSuccess.IsZero() {
+			t.Errorf("[%s] Expected failures to be reset on successful crawl and not [%+v] [%v]", name, h, err)
+		}
+	}
+
+	// tenant view scope entries
+	tenant := stores["tenant"].(*TenantStore)
+	if domains, _ := tenant.Store.(HealthStore).HealthDomains(); len(domains) != len(status) || !strings.HasPrefix(domains[0], "acme/") {
+		t.Errorf("Expected tenant ledger entries to be scoped and not [%v]", domains)
+	}
+	if _, err := NewTenantStore(&TenantStore{}, "acme").Health("example.com"); err == nil {
+		t.Errorf("Expected error on store without health ledger support")
+	}
+}
+
+// TestExportHealth test health ledger export rows match the health columns
+func TestExportHealth(t *testing.T) {
+	store := NewMemoryStore()
+	store.UpdateHealth("example.com", func(h *DomainHealth) {
+		h.Domain = "example.com"
+		h.observe(nil, 120*time.Millisecond, time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC))
+	})
+
+	ledger, err := Ledger(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	e := NewExporter(&buf)
+	if err := e.WriteHealth(ledger); err != nil {
+		t.Fatal(err)
+	}
+	e.Flush()
+
+	expected := "example.com,,1,0,,,2044-11-05T08:49:37Z,120,false,\n"
+	if buf.String() != expected {
+		t.Errorf("Expected health export [%s] and not [%s]", expected, buf.String())
+	}
+	if rows := ExportHealthRows(ledger); len(rows[0]) != len(HealthColumns) {
+		t.Errorf("Expected [%d] columns and not [%d]", len(HealthColumns), len(rows[0]))
+	}
+}
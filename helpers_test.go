@@ -0,0 +1,61 @@
+package adstxt
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// hostRouter send all requests to test server, keeping the original Host header
+type hostRouter struct {
+	addr string
+	rt   http.RoundTripper
+}
+
+func (h *hostRouter) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Host = h.addr
+	r.Host = req.URL.Host
+	return h.rt.RoundTrip(r)
+}
+
+// routeTo route all crawler requests to test server, keeping the original Host header. Requests are sent using
+// base round tripper (e.g. TLS test server client transport), or the crawler transport when base is nil
+func routeTo(ts *httptest.Server, base http.RoundTripper) Option {
+	return func(c *Crawler) {
+		c.wrappers = append(c.wrappers, func(rt http.RoundTripper) http.RoundTripper {
+			if base != nil {
+				rt = base
+			}
+			return &hostRouter{addr: ts.Listener.Addr().String(), rt: rt}
+		})
+	}
+}
+
+// schemeRouter send plaintext requests to HTTP test server, and HTTPS requests to TLS test server
+type schemeRouter struct {
+	plain   *httptest.Server
+	secure  *httptest.Server
+	trusted bool // trust TLS test server certificate
+}
+
+func (s *schemeRouter) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if req.URL.Scheme == "https" {
+		r.URL.Host = s.secure.Listener.Addr().String()
+		if s.trusted {
+			return s.secure.Client().Transport.RoundTrip(r)
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	}
+	r.URL.Host = s.plain.Listener.Addr().String()
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// routeSchemes route crawler requests to plaintext and TLS test servers by URL scheme (see schemeRouter)
+func routeSchemes(plain, secure *httptest.Server, trusted bool) Option {
+	return func(c *Crawler) {
+		c.wrappers = append(c.wrappers, func(http.RoundTripper) http.RoundTripper {
+			return &schemeRouter{plain: plain, secure: secure, trusted: trusted}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package adstxt
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecordSeen crawl history of single data record (ad system, account ID and account type) of a domain
+type RecordSeen struct {
+	Domain      string    `json:"domain"`      // Domain publisher root domain
+	AdSystem    string    `json:"adSystem"`    // AdSystem domain name of the advertising system
+	AccountID   string    `json:"accountId"`   // AccountID publisher account ID
+	AccountType string    `json:"accountType"` // AccountType DIRECT or RESELLER
+	FirstSeen   time.Time `json:"firstSeen"`   // FirstSeen crawl date of the first snapshot declaring the record
+	LastSeen    time.Time `json:"lastSeen"`    // LastSeen crawl date of the last snapshot declaring the record
+	Since       time.Time `json:"since"`       // Since first crawl date of the latest uninterrupted run of snapshots declaring the record
+	Present     bool      `json:"present"`     // Present record is declared in the latest snapshot
+}
+
+// RecordHistory crawl history of domain data records, sorted by ad system, account ID and account type
+type RecordHistory []*RecordSeen
+
+// History build first seen\last seen history of all data records declared in domain snapshots (including
+// compacted snapshots). Domain is the store key of the file: the root domain for its ads.txt file, see FileKey for
+// other files of the domain
+func History(s Store, domain string) (RecordHistory, error) {
+	snapshots, err := s.Snapshots(domain)
+	if err != nil {
+		return nil, err
+	}
+	if i := strings.Index(domain, fileSeparator); i >= 0 {
+		domain = domain[:i]
+	}
+	return snapshotsHistory(domain, snapshots)
+}
+
+// snapshotsHistory build data records history of domain snapshots (oldest first)
+func snapshotsHistory(domain string, snapshots []*Snapshot) (RecordHistory, error) {
+	bodies, err := Bodies(snapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	type recordKey struct {
+		sellerKey
+		accountType string
+	}
+
+	seen := make(map[recordKey]*RecordSeen)
+	for i, body := range bodies {
+		crawledAt := snapshots[i].CrawledAt
+		rec, err := ParseBody([]byte(strings.Join(body, "\n")))
+		if err != nil {
+			return nil, err
+		}
+
+		declared := make(map[recordKey]bool)
+		for _, dr := range rec.DataRecords {
+			k := recordKey{newSellerKey(dr.AdverterDomain.String(), dr.PublisherAccountID), dr.AccountType}
+			if declared[k] {
+				continue
+			}
+			declared[k] = true
+
+			r, ok := seen[k]
+			if !ok {
+				r = &RecordSeen{Domain: domain, AdSystem: k.adSystem, AccountID: k.accountID, AccountType: k.accountType, FirstSeen: crawledAt}
+				seen[k] = r
+			}
+			if !r.Present {
+				r.Since = crawledAt
+			}
+			r.LastSeen = crawledAt
+		}
+
+		// records missing from this snapshot break their presence run
+		for k, r := range seen {
+			r.Present = declared[k]
+		}
+	}
+
+	h := make(RecordHistory, 0, len(seen))
+	for _, r := range seen {
+		h = append(h, r)
+	}
+	sort.Slice(h, func(i, j int) bool {
+		if h[i].AdSystem != h[j].AdSystem {
+			return h[i].AdSystem < h[j].AdSystem
+		}
+		if h[i].AccountID != h[j].AccountID {
+			return h[i].AccountID < h[j].AccountID
+		}
+		return h[i].AccountType < h[j].AccountType
+	})
+	return h, nil
+}
+
+// Find return history of seller account records (DIRECT and\or RESELLER) on ad system
+func (h RecordHistory) Find(adSystem string, accountID string) []*RecordSeen {
+	key := newSellerKey(canonicalAdSystemDomain(strings.TrimSpace(adSystem)), accountID)
+
+	found := []*RecordSeen{}
+	for _, r := range h {
+		if newSellerKey(r.AdSystem, r.AccountID) == key {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// HistoryColumns holds the columns of the records history export (see ExportHistoryRows)
+var HistoryColumns = []string{
+	"domain",               // root domain of the remote host
+	"adsystem_domain",      // domain name of the advertising system
+	"publisher_account_id", // publisher account ID
+	"account_type",         // DIRECT or RESELLER
+	"first_seen",           // crawl date of the first snapshot declaring the record (RFC 3339, UTC)
+	"last_seen",            // crawl date of the last snapshot declaring the record (RFC 3339, UTC)
+	"since",                // first crawl date of the latest uninterrupted declaration run (RFC 3339, UTC)
+	"present",              // record is declared in the latest snapshot
+}
+
+// HistorySchema SQL table definition matching the history columns
+var HistorySchema = fmt.Sprintf(`CREATE TABLE adstxt_records_history (
+	domain               VARCHAR(255) NOT NULL,
+	adsystem_domain      VARCHAR(255) NOT NULL,
+	publisher_account_id VARCHAR(%d) NOT NULL,
+	account_type         VARCHAR(16) NOT NULL,
+	first_seen           TIMESTAMP NOT NULL,
+	last_seen            TIMESTAMP NOT NULL,
+	since                TIMESTAMP NOT NULL,
+	present              BOOLEAN NOT NULL
+);`, MaxAccountIDLength)
+
+// ExportHistoryRows flatten records history into rows matching the history columns
+func ExportHistoryRows(h RecordHistory) [][]string {
+	rows := make([][]string, 0, len(h))
+	for _, r := range h {
+		rows = append(rows, []string{
+			r.Domain, r.AdSystem, r.AccountID, r.AccountType,
+			r.FirstSeen.UTC().Format(time.RFC3339), r.LastSeen.UTC().Format(time.RFC3339), r.Since.UTC().Format(time.RFC3339),
+			strconv.FormatBool(r.Present),
+		})
+	}
+	return rows
+}
+
+// WriteHistory write single row for each of the records history entries (see HistoryColumns)
+func (e *Exporter) WriteHistory(h RecordHistory) error {
+	return e.w.WriteAll(ExportHistoryRows(h))
+}
@@ -0,0 +1,74 @@
+package adstxt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHistory test first seen, last seen and presence run of records across domain snapshots
+func TestHistory(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC)
+
+	bodies := []string{
+		"greenadexchange.com,XF7342,DIRECT\nappnexus.com,1234,RESELLER",
+		"greenadexchange.com,XF7342,DIRECT",
+		"greenadexchange.com,XF7342,DIRECT\nappnexus.com,1234,RESELLER\nGreenAdExchange.com,XF7342,RESELLER",
+		"greenadexchange.com,XF7342,DIRECT\nappnexus.com,1234,RESELLER",
+	}
+
+	s := NewMemoryStore()
+	for i, body := range bodies {
+		rec, _ := ParseBody([]byte(body))
+		res := &Response{Request: &Request{Domain: "example.com"}, Records: rec}
+		s.Put(NewSnapshot(res, now.Add(time.Duration(i)*day)))
+	}
+
+	// history is restored from compacted snapshots as well
+	if _, err := Maintain(s, RetentionPolicy{KeepSnapshots: 1, Compact: true}, now.Add(10*day)); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := History(s, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 3 {
+		t.Fatalf("Expected [3] records history and not [%d]", len(h))
+	}
+
+	expected := map[string]RecordSeen{
+		"greenadexchange.com,XF7342,DIRECT":   {FirstSeen: now, LastSeen: now.Add(3 * day), Since: now, Present: true},
+		"greenadexchange.com,XF7342,RESELLER": {FirstSeen: now.Add(2 * day), LastSeen: now.Add(2 * day), Since: now.Add(2 * day), Present: false},
+		"appnexus.com,1234,RESELLER":          {FirstSeen: now, LastSeen: now.Add(3 * day), Since: now.Add(2 * day), Present: true},
+	}
+	for _, r := range h {
+		key := strings.Join([]string{r.AdSystem, r.AccountID, r.AccountType}, ",")
+		e, ok := expected[key]
+		if !ok {
+			t.Errorf("Unexpected record history [%s]", key)
+			continue
+		}
+		if !r.FirstSeen.Equal(e.FirstSeen) || !r.LastSeen.Equal(e.LastSeen) || !r.Since.Equal(e.Since) || r.Present != e.Present {
+			t.Errorf("[%s] Expected history %+v and not %+v", key, e, *r)
+		}
+	}
+
+	if found := h.Find("GreenAdExchange.com", "XF7342"); len(found) != 2 {
+		t.Errorf("Expected DIRECT and RESELLER history of seller account and not [%d]", len(found))
+	}
+
+	var b bytes.Buffer
+	e := NewExporter(&b)
+	e.WriteHistory(h.Find("appnexus.com", "1234"))
+	e.Flush()
+	if expected := "example.com,appnexus.com,1234,RESELLER,2044-11-05T08:49:37Z,2044-11-08T08:49:37Z,2044-11-07T08:49:37Z,true\n"; b.String() != expected {
+		t.Errorf("Expected history export [%s] and not [%s]", expected, b.String())
+	}
+	if id := fmt.Sprintf("publisher_account_id VARCHAR(%d)", MaxAccountIDLength); !strings.Contains(HistorySchema, id) {
+		t.Errorf("Expected history schema to include [%s]", id)
+	}
+}
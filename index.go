@@ -0,0 +1,102 @@
+package adstxt
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sellerKey identify a single seller account: ad system domain and publisher account ID
+type sellerKey struct {
+	adSystem  string
+	accountID string
+}
+
+// SellerIndex is an inverted index from (ad system, publisher account ID) to the publisher domains that
+// authorize it. The index is safe for concurrent use and can be updated incrementally as new crawls arrive
+type SellerIndex struct {
+	lock    sync.RWMutex
+	sellers map[sellerKey]map[string]bool // publisher domains authorizing each seller account
+	domains map[string][]sellerKey        // seller accounts currently indexed for each publisher domain
+}
+
+// NewSellerIndex create new empty seller index
+func NewSellerIndex() *SellerIndex {
+	return &SellerIndex{
+		sellers: make(map[sellerKey]map[string]bool),
+		domains: make(map[string][]sellerKey),
+	}
+}
+
+// Add index Ads.txt response data records. Previous entries for the same publisher domain are replaced so
+// the index always reflect the latest crawl of each publisher
+func (i *SellerIndex) Add(res *Response) {
+	if res == nil || res.Request == nil || res.Records == nil {
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	domain := res.Request.Domain.String()
+	i.remove(domain)
+
+	keys := []sellerKey{}
+	for _, r := range res.DataRecords {
+		k := newSellerKey(r.AdverterDomain.String(), r.PublisherAccountID)
+		publishers, ok := i.sellers[k]
+		if !ok {
+			publishers = make(map[string]bool)
+			i.sellers[k] = publishers
+		}
+		if !publishers[domain] {
+			publishers[domain] = true
+			keys = append(keys, k)
+		}
+	}
+
+	if len(keys) > 0 {
+		i.domains[domain] = keys
+	}
+}
+
+// Remove all index entries of the specified publisher domain
+func (i *SellerIndex) Remove(domain string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.remove(domain)
+}
+
+// Lookup return sorted list of publisher domains authorizing the specified seller account ID on ad system
+func (i *SellerIndex) Lookup(adSystem string, accountID string) []string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	domains := []string{}
+	for d := range i.sellers[newSellerKey(adSystem, accountID)] {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+
+	return domains
+}
+
+// remove publisher domain entries, caller must hold the index lock
+func (i *SellerIndex) remove(domain string) {
+	for _, k := range i.domains[domain] {
+		delete(i.sellers[k], domain)
+		if len(i.sellers[k]) == 0 {
+			delete(i.sellers, k)
+		}
+	}
+	delete(i.domains, domain)
+}
+
+// newSellerKey normalize ad system domain (case insensitive) and account ID before using them as index key
+func newSellerKey(adSystem string, accountID string) sellerKey {
+	return sellerKey{
+		adSystem:  strings.ToLower(strings.TrimSpace(adSystem)),
+		accountID: strings.TrimSpace(accountID),
+	}
+}
@@ -0,0 +1,40 @@
+package adstxt
+
+import (
+	"testing"
+)
+
+// TestSellerIndex test reverse lookup of publisher domains by ad system and account ID
+func TestSellerIndex(t *testing.T) {
+	newResponse := func(domain string, body string) *Response {
+		rec, err := ParseBody([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &Response{Request: &Request{Domain: Domain(domain)}, Records: rec}
+	}
+
+	i := NewSellerIndex()
+	i.Add(newResponse("example.com", "greenadexchange.com,XF7342,DIRECT\ngreenadexchange.com,XF7343,RESELLER"))
+	i.Add(newResponse("test.com", "greenadexchange.com,XF7342,RESELLER"))
+
+	d := i.Lookup("GreenAdExchange.com", "XF7342")
+	if len(d) != 2 || d[0] != "example.com" || d[1] != "test.com" {
+		t.Errorf("Expected seller [XF7342] to be authorized by [example.com test.com] and not %v", d)
+	}
+
+	// new crawl of the same publisher replace previous entries
+	i.Add(newResponse("example.com", "greenadexchange.com,XF7343,RESELLER"))
+
+	d = i.Lookup("greenadexchange.com", "XF7342")
+	if len(d) != 1 || d[0] != "test.com" {
+		t.Errorf("Expected seller [XF7342] to be authorized only by [test.com] and not %v", d)
+	}
+
+	i.Remove("example.com")
+
+	d = i.Lookup("greenadexchange.com", "XF7343")
+	if len(d) != 0 {
+		t.Errorf("Expected seller [XF7343] not to be authorized after removing publisher but found %v", d)
+	}
+}
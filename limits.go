@@ -0,0 +1,24 @@
+package adstxt
+
+// Crawler and parser hard limits. Ads.txt files are untrusted internet content: input beyond these limits is reported with
+// high severity warning instead of being parsed, so memory use and parsing time stay linear in the file size
+const (
+	// MaxFileSize maximum size of Ads.txt file (bytes). Download of larger file fails, so a huge or endless response
+	// can't exhaust memory
+	MaxFileSize = 16 << 20
+	// MaxLineLength maximum length of Ads.txt line (bytes). Longer lines are truncated to this length in the file
+	// body, and are not parsed
+	MaxLineLength = 8192
+	// MaxDomainLength maximum length of ad system domain name (DNS name limit)
+	MaxDomainLength = 253
+	// MaxAccountIDLength maximum length of publisher account ID and certification authority ID
+	MaxAccountIDLength = 256
+	// MaxVariableValueLength maximum length of variable record value
+	MaxVariableValueLength = 1024
+)
+
+// Parser limits warnings
+const (
+	warnLineTooLong  = "line is longer than [%d] bytes and was not parsed"
+	warnFieldTooLong = "%s is longer than [%d] bytes"
+)
@@ -0,0 +1,124 @@
+package adstxt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Local Ads.txt file URL schemes (see WithLocalSchemes)
+const (
+	schemeFile = "file"
+	schemeData = "data"
+)
+
+// LocalDomain root domain of local Ads.txt file requests (file: and data: URLs). Callers may set the request Domain
+// of the publisher the local file belongs to
+const LocalDomain = "localhost"
+
+// errInvalidDataURL data: URL couldn't be parsed
+const errInvalidDataURL = "invalid data URL [%s]: %s"
+
+// WithLocalSchemes accept file:///path/ads.txt and data: URLs, so test suites and offline pipelines can run the full
+// Ads.txt request\response pipeline (expiration defaults, content type checks and validation) without HTTP
+func WithLocalSchemes() RequestOption {
+	return func(c *requestConfig) {
+		c.local = true
+	}
+}
+
+// isLocalURL check if URL uses local Ads.txt file scheme
+func isLocalURL(rawurl string) bool {
+	s := strings.ToLower(rawurl)
+	return strings.HasPrefix(s, schemeFile+"://") || strings.HasPrefix(s, schemeData+":")
+}
+
+// newLocalRequest create request of local Ads.txt file, URL is used as is
+func newLocalRequest(rawurl string, cfg *requestConfig) (*Request, error) {
+	if _, err := url.Parse(rawurl); err != nil {
+		return nil, err
+	}
+	return &Request{URL: rawurl, Domain: LocalDomain, Tenant: cfg.tenant, local: true}, nil
+}
+
+// localResponse serve local Ads.txt file request as HTTP response: file content (404 on missing file) or data URL
+// content, without any HTTP caching headers
+func localResponse(req *Request, httpRequest *http.Request) (*http.Response, error) {
+	res := &http.Response{
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     make(http.Header),
+		Request:    httpRequest,
+	}
+
+	switch strings.ToLower(httpRequest.URL.Scheme) {
+	case schemeFile:
+		b, err := os.ReadFile(httpRequest.URL.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			res.StatusCode, res.Status = http.StatusNotFound, "404 Not Found"
+			b = []byte{}
+		} else if err != nil {
+			return nil, err
+		}
+		res.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		res.Body = io.NopCloser(bytes.NewReader(b))
+		res.ContentLength = int64(len(b))
+	case schemeData:
+		mediaType, b, err := parseDataURL(req.URL)
+		if err != nil {
+			return nil, err
+		}
+		res.Header.Set("Content-Type", mediaType)
+		res.Body = io.NopCloser(bytes.NewReader(b))
+		res.ContentLength = int64(len(b))
+	default:
+		return nil, fmt.Errorf("unsupported local URL scheme [%s]", httpRequest.URL.Scheme)
+	}
+
+	return res, nil
+}
+
+// parseDataURL parse data URL (RFC 2397) media type and content: data:[<mediatype>][;base64],<data>
+func parseDataURL(rawurl string) (string, []byte, error) {
+	s := rawurl[len(schemeData)+1:]
+	i := strings.Index(s, ",")
+	if i < 0 {
+		return "", nil, fmt.Errorf(errInvalidDataURL, rawurl, "missing comma")
+	}
+
+	mediaType, data := s[:i], s[i+1:]
+	encoded := strings.HasSuffix(strings.ToLower(mediaType), ";base64")
+	if encoded {
+		mediaType = mediaType[:len(mediaType)-len(";base64")]
+	}
+	if len(mediaType) == 0 || strings.HasPrefix(mediaType, ";") {
+		mediaType = "text/plain" + mediaType
+		if !strings.Contains(mediaType, "charset=") {
+			mediaType += ";charset=US-ASCII"
+		}
+	}
+
+	data, err := url.PathUnescape(data)
+	if err != nil {
+		return "", nil, fmt.Errorf(errInvalidDataURL, rawurl, err.Error())
+	}
+	if !encoded {
+		return mediaType, []byte(data), nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", nil, fmt.Errorf(errInvalidDataURL, rawurl, err.Error())
+	}
+	return mediaType, b, nil
+}
@@ -0,0 +1,61 @@
+package adstxt
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLocalSchemes test file: and data: Ads.txt requests run through the crawler pipeline
+func TestLocalSchemes(t *testing.T) {
+	const body = "greenadexchange.com,XF7342,DIRECT\ncontact=ads@example.com"
+
+	path := filepath.Join(t.TempDir(), "ads.txt")
+	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	urls := []string{
+		"file://" + path,
+		"data:,greenadexchange.com%2CXF7342%2CDIRECT%0Acontact=ads@example.com",
+		"data:text/plain;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(body)),
+	}
+
+	for _, rawurl := range urls {
+		req, err := NewRequest(rawurl, WithLocalSchemes())
+		if err != nil {
+			t.Fatalf("[%s] %s", rawurl, err)
+		}
+		if req.URL != rawurl || req.Domain != LocalDomain {
+			t.Errorf("[%s] Expected local request URL to be kept and not [%s] [%s]", rawurl, req.URL, req.Domain)
+		}
+
+		res, err := Get(req)
+		if err != nil {
+			t.Errorf("[%s] %s", rawurl, err)
+			continue
+		}
+		if len(res.DataRecords) != 1 || len(res.Variables) != 1 || len(res.Flags) != 0 {
+			t.Errorf("[%s] Expected single data record and variable and not %d %d %v", rawurl, len(res.DataRecords), len(res.Variables), res.Flags)
+		}
+		if res.Expires.Source != ExpiresSourceDefault {
+			t.Errorf("[%s] Expected default expiration and not [%s]", rawurl, res.Expires.Source)
+		}
+	}
+
+	// missing file
+	req, _ := NewRequest("file://"+filepath.Join(t.TempDir(), "missing.txt"), WithLocalSchemes())
+	_, err := Get(req)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected missing file to be not found and not [%v]", err)
+	}
+
+	// local schemes are not accepted by default
+	if req, err := NewRequest("file://" + path); err == nil && req.local {
+		t.Errorf("Expected file URL not to be accepted without option")
+	}
+}
@@ -0,0 +1,114 @@
+package adstxt
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+)
+
+// CrawlerStats snapshot of crawler internal gauges (see Crawler.Stats)
+type CrawlerStats struct {
+	InFlight    int64 `json:"inFlight"`    // InFlight number of Ads.txt fetches in progress
+	Queued      int64 `json:"queued"`      // Queued number of GetMultiple requests waiting to be crawled
+	Dialing     int64 `json:"dialing"`     // Dialing number of connections being dialed (including dials waiting for connection budget)
+	OpenConns   int64 `json:"openConns"`   // OpenConns number of open connections
+	ActiveConns int64 `json:"activeConns"` // ActiveConns number of connections serving HTTP response
+	IdleConns   int64 `json:"idleConns"`   // IdleConns number of open connections not serving HTTP response (kept alive)
+}
+
+// crawlerMetrics crawler internal gauges, updated atomically
+type crawlerMetrics struct {
+	inFlight int64
+	queued   int64
+	dialing  int64
+	open     int64
+	active   int64
+}
+
+// Stats return snapshot of crawler internal gauges. Gauges are sampled one by one, so they may be slightly
+// inconsistent with each other under load
+func (c *Crawler) Stats() CrawlerStats {
+	m := c.metrics
+	s := CrawlerStats{
+		InFlight:    atomic.LoadInt64(&m.inFlight),
+		Queued:      atomic.LoadInt64(&m.queued),
+		Dialing:     atomic.LoadInt64(&m.dialing),
+		OpenConns:   atomic.LoadInt64(&m.open),
+		ActiveConns: atomic.LoadInt64(&m.active),
+	}
+	if s.IdleConns = s.OpenConns - s.ActiveConns; s.IdleConns < 0 {
+		s.IdleConns = 0
+	}
+	return s
+}
+
+// countDial wrap dial function to count dials and open connections
+func (m *crawlerMetrics) countDial(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		atomic.AddInt64(&m.dialing, 1)
+		conn, err := dial(ctx, network, addr)
+		atomic.AddInt64(&m.dialing, -1)
+		if err != nil {
+			return nil, err
+		}
+
+		atomic.AddInt64(&m.open, 1)
+		return &countedConn{Conn: conn, closed: func() { atomic.AddInt64(&m.open, -1) }}, nil
+	}
+}
+
+// countedConn connection which updates open connections gauge when closed (only once)
+type countedConn struct {
+	net.Conn
+	once   sync.Once
+	closed func()
+}
+
+func (c *countedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.closed)
+	return err
+}
+
+// countActive count connection serving HTTP response of request: from the moment request got connection until
+// response body is closed
+func (m *crawlerMetrics) countActive(req *http.Request) (*http.Request, func(*http.Response, error)) {
+	var got int32
+	done := func() {
+		if atomic.CompareAndSwapInt32(&got, 1, 2) {
+			atomic.AddInt64(&m.active, -1)
+		}
+	}
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) {
+			if atomic.CompareAndSwapInt32(&got, 0, 1) {
+				atomic.AddInt64(&m.active, 1)
+			}
+		},
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), func(res *http.Response, err error) {
+		if err != nil {
+			done()
+			return
+		}
+		res.Body = &countedBody{ReadCloser: res.Body, closed: done}
+	}
+}
+
+// countedBody response body which updates active connections gauge when closed
+type countedBody struct {
+	io.ReadCloser
+	closed func()
+}
+
+func (b *countedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.closed()
+	return err
+}
@@ -0,0 +1,70 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCrawlerStats test shared crawler gauges while requests are crawled concurrently
+func TestCrawlerStats(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	c := NewCrawler(WithConnectionBudget(BulkBudget))
+
+	// wait until stats match condition
+	waitStats := func(name string, ok func(s CrawlerStats) bool) {
+		deadline := time.Now().Add(5 * time.Second)
+		for !ok(c.Stats()) {
+			if time.Now().After(deadline) {
+				t.Fatalf("[%s] Unexpected crawler stats %+v", name, c.Stats())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	const n = 4
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := NewRequest(ts.URL)
+			if _, err := c.Get(req); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	waitStats("in-flight", func(s CrawlerStats) bool {
+		return s.InFlight == n && s.ActiveConns == n && s.OpenConns == n && s.IdleConns == 0
+	})
+
+	close(release)
+	wg.Wait()
+
+	waitStats("idle", func(s CrawlerStats) bool {
+		return s.InFlight == 0 && s.ActiveConns == 0 && s.OpenConns > 0 && s.IdleConns == s.OpenConns
+	})
+
+	c.CloseIdleConnections()
+	waitStats("closed", func(s CrawlerStats) bool {
+		return s.OpenConns == 0 && s.IdleConns == 0
+	})
+
+	// queued requests are drained once crawled
+	req, _ := NewRequest(ts.URL)
+	c.GetMultiple([]*Request{req}, HandlerFunc(func(*Request, *Response, error) {}))
+	if s := c.Stats(); s.Queued != 0 || s.InFlight != 0 {
+		t.Errorf("Expected no queued or in-flight requests after crawl and not %+v", s)
+	}
+}
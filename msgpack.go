@@ -0,0 +1,520 @@
+package adstxt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// msgpackCodec MessagePack encoding, structs are encoded as arrays of field values (see codecFields)
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string {
+	return "msgpack"
+}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := msgpackEncode(&b, reflect.ValueOf(v), 0); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	target, err := decodeTarget("msgpack", v)
+	if err != nil {
+		return err
+	}
+
+	d := &msgpackDecoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return err
+	}
+	if d.pos != len(data) {
+		return fmt.Errorf("msgpack: [%d] unexpected trailing bytes", len(data)-d.pos)
+	}
+	return msgpackAssign(target, value)
+}
+
+// msgpackEncode write MessagePack encoding of value
+func msgpackEncode(b *bytes.Buffer, v reflect.Value, depth int) error {
+	if depth > maxCodecDepth {
+		return errCodecDepth
+	}
+	if !v.IsValid() {
+		b.WriteByte(0xc0)
+		return nil
+	}
+
+	// marshaler values (e.g. time.Time) are encoded as bin
+	if bytesEncoding(v.Type()) != bytesNone {
+		data, err := marshalBytes(v)
+		if err != nil {
+			return err
+		}
+		msgpackEncodeHeader(b, len(data), 0, 0, 0xc4, 0xc5, 0xc6)
+		b.Write(data)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			b.WriteByte(0xc0)
+			return nil
+		}
+		return msgpackEncode(b, v.Elem(), depth+1)
+	case reflect.Bool:
+		if v.Bool() {
+			b.WriteByte(0xc3)
+		} else {
+			b.WriteByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		msgpackEncodeInt(b, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u > math.MaxInt64 {
+			b.WriteByte(0xcf)
+			binary.Write(b, binary.BigEndian, u)
+		} else {
+			msgpackEncodeInt(b, int64(u))
+		}
+	case reflect.Float32:
+		b.WriteByte(0xca)
+		binary.Write(b, binary.BigEndian, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		b.WriteByte(0xcb)
+		binary.Write(b, binary.BigEndian, math.Float64bits(v.Float()))
+	case reflect.String:
+		msgpackEncodeHeader(b, v.Len(), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		b.WriteString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			b.WriteByte(0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			msgpackEncodeHeader(b, v.Len(), 0, 0, 0xc4, 0xc5, 0xc6)
+			b.Write(v.Bytes())
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		msgpackEncodeHeader(b, v.Len(), 0x90, 16, 0, 0xdc, 0xdd)
+		for i := 0; i < v.Len(); i++ {
+			if err := msgpackEncode(b, v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteByte(0xc0)
+			return nil
+		}
+		// entries are sorted by key encoding, so encoding output is deterministic
+		entries := make([][2][]byte, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			var k, e bytes.Buffer
+			if err := msgpackEncode(&k, iter.Key(), depth+1); err != nil {
+				return err
+			}
+			if err := msgpackEncode(&e, iter.Value(), depth+1); err != nil {
+				return err
+			}
+			entries = append(entries, [2][]byte{k.Bytes(), e.Bytes()})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i][0], entries[j][0]) < 0
+		})
+
+		msgpackEncodeHeader(b, len(entries), 0x80, 16, 0, 0xde, 0xdf)
+		for _, e := range entries {
+			b.Write(e[0])
+			b.Write(e[1])
+		}
+	case reflect.Struct:
+		fields := codecFields(v.Type())
+		msgpackEncodeHeader(b, len(fields), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, i := range fields {
+			if err := msgpackEncode(b, v.Field(i), depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// msgpackEncodeInt write the most compact MessagePack encoding of integer
+func msgpackEncodeInt(b *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 0x7f:
+		b.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		b.WriteByte(byte(i))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		b.WriteByte(0xd0)
+		b.WriteByte(byte(i))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		b.WriteByte(0xd1)
+		binary.Write(b, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		b.WriteByte(0xd2)
+		binary.Write(b, binary.BigEndian, int32(i))
+	default:
+		b.WriteByte(0xd3)
+		binary.Write(b, binary.BigEndian, i)
+	}
+}
+
+// msgpackEncodeHeader write string\array\map header: fix format (when length is below fixMax) or 8\16\32 bit length
+func msgpackEncodeHeader(b *bytes.Buffer, n int, fix byte, fixMax int, f8 byte, f16 byte, f32 byte) {
+	switch {
+	case n < fixMax:
+		b.WriteByte(fix | byte(n))
+	case f8 != 0 && n <= math.MaxUint8:
+		b.WriteByte(f8)
+		b.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		b.WriteByte(f16)
+		binary.Write(b, binary.BigEndian, uint16(n))
+	default:
+		b.WriteByte(f32)
+		binary.Write(b, binary.BigEndian, uint32(n))
+	}
+}
+
+// msgpackMap decoded MessagePack map, keys may be of any type
+type msgpackMap struct {
+	keys   []interface{}
+	values []interface{}
+}
+
+// msgpackDecoder decode MessagePack data into generic values: nil, bool, int64, uint64, float64, string, []byte,
+// []interface{} and *msgpackMap
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+// next return next n bytes of data
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// uint read n bytes big endian unsigned integer
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// decode single MessagePack value at nesting depth
+func (d *msgpackDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCodecDepth {
+		return nil, errCodecDepth
+	}
+
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c&0x0f), depth)
+	case c&0xf0 == 0x80:
+		return d.object(int(c&0x0f), depth)
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(1 << (c - 0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n := 1 << (c - 0xd0)
+		u, err := d.uint(n)
+		if err != nil {
+			return nil, err
+		}
+		// sign extend n bytes integer
+		shift := uint(64 - 8*n)
+		return int64(u<<shift) >> shift, nil
+	case 0xca:
+		u, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(int(n))
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(int(n), depth)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.object(int(n), depth)
+	}
+
+	return nil, fmt.Errorf("msgpack: unsupported format 0x%x", c)
+}
+
+func (d *msgpackDecoder) str(n int) (interface{}, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) array(n int, depth int) (interface{}, error) {
+	// each array item is at least single byte, avoid allocation based on corrupted length
+	if n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+
+	a := make([]interface{}, n)
+	for i := range a {
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		a[i] = v
+	}
+	return a, nil
+}
+
+func (d *msgpackDecoder) object(n int, depth int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+
+	m := &msgpackMap{keys: make([]interface{}, n), values: make([]interface{}, n)}
+	for i := 0; i < n; i++ {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		m.keys[i], m.values[i] = k, v
+	}
+	return m, nil
+}
+
+// msgpackAssign store decoded generic value in v
+func msgpackAssign(v reflect.Value, x interface{}) error {
+	if x == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	mismatch := fmt.Errorf("msgpack: cannot decode %T into %s", x, v.Type())
+	if bytesEncoding(v.Type()) != bytesNone {
+		b, ok := x.([]byte)
+		if !ok {
+			return mismatch
+		}
+		return unmarshalBytes(v, b)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return msgpackAssign(v.Elem(), x)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return mismatch
+		}
+		v.Set(reflect.ValueOf(msgpackGeneric(x)))
+	case reflect.Bool:
+		b, ok := x.(bool)
+		if !ok {
+			return mismatch
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch n := x.(type) {
+		case int64:
+			i = n
+		case uint64:
+			if n > math.MaxInt64 {
+				return mismatch
+			}
+			i = int64(n)
+		default:
+			return mismatch
+		}
+		if v.OverflowInt(i) {
+			return mismatch
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		switch n := x.(type) {
+		case uint64:
+			u = n
+		case int64:
+			if n < 0 {
+				return mismatch
+			}
+			u = uint64(n)
+		default:
+			return mismatch
+		}
+		if v.OverflowUint(u) {
+			return mismatch
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		switch n := x.(type) {
+		case float64:
+			v.SetFloat(n)
+		case int64:
+			v.SetFloat(float64(n))
+		case uint64:
+			v.SetFloat(float64(n))
+		default:
+			return mismatch
+		}
+	case reflect.String:
+		s, ok := x.(string)
+		if !ok {
+			return mismatch
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		if b, ok := x.([]byte); ok && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(b)
+			return nil
+		}
+		a, ok := x.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		s := reflect.MakeSlice(v.Type(), len(a), len(a))
+		for i := range a {
+			if err := msgpackAssign(s.Index(i), a[i]); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		a, ok := x.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		for i := 0; i < v.Len() && i < len(a); i++ {
+			if err := msgpackAssign(v.Index(i), a[i]); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		m, ok := x.(*msgpackMap)
+		if !ok {
+			return mismatch
+		}
+		mv := reflect.MakeMapWithSize(v.Type(), len(m.keys))
+		for i := range m.keys {
+			k, e := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+			if err := msgpackAssign(k, m.keys[i]); err != nil {
+				return err
+			}
+			if err := msgpackAssign(e, m.values[i]); err != nil {
+				return err
+			}
+			mv.SetMapIndex(k, e)
+		}
+		v.Set(mv)
+	case reflect.Struct:
+		a, ok := x.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		// fields encoded by newer struct version are ignored, missing fields are left as is
+		for i, f := range codecFields(v.Type()) {
+			if i >= len(a) {
+				break
+			}
+			if err := msgpackAssign(v.Field(f), a[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// msgpackGeneric convert decoded value to interface value: maps are converted to map[string]interface{}
+func msgpackGeneric(x interface{}) interface{} {
+	switch t := x.(type) {
+	case []interface{}:
+		a := make([]interface{}, len(t))
+		for i := range t {
+			a[i] = msgpackGeneric(t[i])
+		}
+		return a
+	case *msgpackMap:
+		m := make(map[string]interface{}, len(t.keys))
+		for i := range t.keys {
+			m[fmt.Sprint(t.keys[i])] = msgpackGeneric(t.values[i])
+		}
+		return m
+	}
+	return x
+}
@@ -0,0 +1,207 @@
+package adstxt
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configure Crawler, see NewCrawler
+type Option func(*Crawler)
+
+// WithConnectionBudget set crawler transport level connection limits (see DefaultBudget and BulkBudget)
+func WithConnectionBudget(b ConnectionBudget) Option {
+	return func(c *Crawler) {
+		c.budget = b
+	}
+}
+
+// WithConcurrency set the maximum number of requests crawled in parallel by GetMultiple (default is 5 requests
+// per CPU)
+func WithConcurrency(n int) Option {
+	return func(c *Crawler) {
+		c.concurrency = n
+	}
+}
+
+// WithAutoTuning adjust GetMultiple concurrency at runtime using tuner, based on observed error rate, latency and
+// open file descriptors usage (see NewTuner). Static concurrency (see WithConcurrency) is ignored
+func WithAutoTuning(t *Tuner) Option {
+	return func(c *Crawler) {
+		c.tuner = t
+	}
+}
+
+// WithTimeout set HTTP request timeout, including reading the response body (default 30 seconds)
+func WithTimeout(d time.Duration) Option {
+	return func(c *Crawler) {
+		c.timeout = d
+	}
+}
+
+// WithEarlyAbort parse Ads.txt file while it is downloaded, and abort the fetch once the number of high sevirity
+// warnings reaches threshold (e.g. remote host returned HTML page). Saves bandwidth when scanning large number of hosts
+func WithEarlyAbort(threshold int) Option {
+	return func(c *Crawler) {
+		c.abortAfter = threshold
+	}
+}
+
+// WithProfileLimits set the maximum number of SUBDOMAIN and INVENTORYPARTNERDOMAIN declarations followed when
+// building publisher profile (see Profile)
+func WithProfileLimits(l ProfileLimits) Option {
+	return func(c *Crawler) {
+		c.profileLimits = l
+	}
+}
+
+// WithCapturedHeaders capture the specified HTTP response headers (e.g. Server, CF-Cache-Status, X-Cache) onto the
+// Ads.txt response, for infrastructure analytics
+func WithCapturedHeaders(names ...string) Option {
+	return func(c *Crawler) {
+		for _, n := range names {
+			c.headers = append(c.headers, http.CanonicalHeaderKey(n))
+		}
+	}
+}
+
+// WithPreResolve resolve hosts of all GetMultiple requests up front, using up to concurrency parallel DNS lookups.
+// Requests of unresolvable hosts are reported to the handler before crawling starts, saving crawl slots for live hosts
+func WithPreResolve(concurrency int) Option {
+	return func(c *Crawler) {
+		c.resolveConcurrency = concurrency
+	}
+}
+
+// WithSchemeRacing fetch Ads.txt file over both https:// and http:// concurrently (and www. or apex host variant
+// when www is set), the first valid Ads.txt file wins and other fetches are cancelled. Reduce tail latency of online
+// checks, at the cost of additional requests to the remote host
+func WithSchemeRacing(www bool) Option {
+	return func(c *Crawler) {
+		c.race = true
+		c.raceWWW = www
+	}
+}
+
+// WithPreprocessors transform Ads.txt file body before parsing (see StripBOM, StripNullBytes and HTMLUnescape), applied
+// transformations are recorded on the response. The whole body is read before parsing, even with early abort set
+func WithPreprocessors(p ...Preprocessor) Option {
+	return func(c *Crawler) {
+		c.preprocessors = append(c.preprocessors, p...)
+	}
+}
+
+// WithTransformers transform Ads.txt responses (e.g. enrich or redact fields) before they are returned to caller and
+// reported to handlers. Transformers are applied in order, after any previously set transformers
+func WithTransformers(t ...Transformer) Option {
+	return func(c *Crawler) {
+		c.transformers = append(c.transformers, t...)
+	}
+}
+
+// WithDifferential crawl Ads.txt files differentially against the latest stored snapshot of each domain: requests
+// are conditional (If-None-Match, If-Modified-Since), and a file which was not modified (304) or whose body digest
+// matches the stored one, is not parsed. Such response is flagged as Unchanged and holds the stored records, so
+// it doesn't have to be stored again. The whole body is read before parsing, even with early abort set
+func WithDifferential(s Store) Option {
+	return func(c *Crawler) {
+		c.differential = s
+	}
+}
+
+// WithDefaultPath fetch files from path instead of /ads.txt (e.g. /.well-known/ads.txt), unless request path was
+// set explicitly (see WithPath)
+func WithDefaultPath(path string) Option {
+	return func(c *Crawler) {
+		c.path = "/" + strings.TrimPrefix(path, "/")
+	}
+}
+
+// WithWellKnownRedirects set treatment of redirects to another well-known file (e.g. ads.txt request redirected to
+// app-ads.txt or sellers.json): WellKnownFollow, WellKnownWarn (default) or WellKnownReject
+func WithWellKnownRedirects(policy string) Option {
+	return func(c *Crawler) {
+		c.wellKnownRedirects = policy
+	}
+}
+
+// WithEventSink report typed crawl events (request started, redirect, response received, parse finished,
+// validation findings etc.) to sink
+func WithEventSink(s EventSink) Option {
+	return func(c *Crawler) {
+		c.events = s
+	}
+}
+
+// WithValidationProfile validate Ads.txt files against spec version profile (see AdsTxt102, AdsTxt11 and
+// AppAdsTxt10), in addition to parser rules. Profile findings are labeled with the profile name
+func WithValidationProfile(p ValidationProfile) Option {
+	return func(c *Crawler) {
+		c.profile = &p
+	}
+}
+
+// WithRangeResume resume interrupted (truncated) Ads.txt downloads using HTTP range requests, up to attempts times
+// per download, when origin supports byte ranges. Downloads parsed while read (early abort) are not resumed
+func WithRangeResume(attempts int) Option {
+	return func(c *Crawler) {
+		c.rangeResumes = attempts
+	}
+}
+
+// WithSecurityReport attach fetch-time security report to Ads.txt responses: TLS protocol version, certificate
+// issuer\expiry and chain validity. Files served over plaintext HTTP are fetched again over HTTPS, to report
+// whether they are only reachable over HTTP
+func WithSecurityReport() Option {
+	return func(c *Crawler) {
+		c.security = true
+	}
+}
+
+// WithRetry retry fetches failed with origin error (5xx) up to retries times, with exponential backoff starting
+// with the specified delay. Longer remote host Retry-After delay is honored (up to 5 minutes)
+func WithRetry(retries int, backoff time.Duration) Option {
+	return func(c *Crawler) {
+		c.retries = retries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithScoring score Ads.txt responses findings using weights (see DefaultScoreWeights), so publishers can be
+// compared by a single number and letter grade
+func WithScoring(w ScoreWeights) Option {
+	return func(c *Crawler) {
+		c.scoring = &w
+	}
+}
+
+// WithSellersRegistry locate ad systems sellers.json files using registry (instead of DefaultSellersRegistry)
+func WithSellersRegistry(r *SellersRegistry) Option {
+	return func(c *Crawler) {
+		c.sellersRegistry = r
+	}
+}
+
+// WithHealthLedger record result of every crawl in per domain health ledger of store (consecutive failures, last
+// success, average latency and block status), see Ledger and Exporter.WriteHealth
+func WithHealthLedger(s HealthStore) Option {
+	return func(c *Crawler) {
+		c.health = s
+	}
+}
+
+// WithDeadLetters send requests which failed to be crawled (after all retries) to dead-letter sink, with history
+// of their failed attempts (see DeadLetterFile and DeadLetterQueue). Requests interrupted by context are not sent
+func WithDeadLetters(s DeadLetterSink) Option {
+	return func(c *Crawler) {
+		c.deadLetters = s
+	}
+}
+
+// WithPoliteness track per host politeness state: hosts which throttled the crawler (429, Retry-After) are backed
+// off, and requests are spaced by host crawl delay. State can be persisted between crawl runs (see LoadPoliteness)
+func WithPoliteness(p *Politeness) Option {
+	return func(c *Crawler) {
+		c.politeness = p
+	}
+}
@@ -0,0 +1,174 @@
+package adstxt
+
+import (
+	"crypto/sha256"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PlanEntry crawl history and next planned crawl of single domain
+type PlanEntry struct {
+	Domain       string        `json:"domain"`       // Domain publisher root domain
+	Digest       [32]byte      `json:"-"`            // Digest of the last crawled Ads.txt file content
+	Observations int           `json:"observations"` // Observations number of crawls observed
+	Changes      int           `json:"changes"`      // Changes number of crawls in which the file content changed
+	LastCrawl    time.Time     `json:"lastCrawl"`    // LastCrawl date of the last observed crawl
+	LastChange   time.Time     `json:"lastChange"`   // LastChange date of the last observed content change
+	Interval     time.Duration `json:"interval"`     // Interval current recrawl interval
+	Next         time.Time     `json:"next"`         // Next planned crawl date
+	Failures     int           `json:"failures"`     // Failures number of consecutive failed crawls since the last successful crawl
+	LastFailure  string        `json:"lastFailure"`  // LastFailure failure class of the last failed crawl (see FailureClass)
+}
+
+// Planner derive adaptive per domain recrawl intervals from historical change frequency: interval is halved
+// every time a file changes and doubled every time it didn't, within caller set bounds. The planner is safe for
+// concurrent use
+type Planner struct {
+	Min          time.Duration // Min recrawl interval (e.g. daily)
+	Max          time.Duration // Max recrawl interval (e.g. weekly)
+	RetryBackoff time.Duration // RetryBackoff delay of the first retry of retryable failure, doubled on every failure (up to Min)
+
+	lock    sync.Mutex
+	entries map[string]*PlanEntry
+}
+
+// NewPlanner create new crawl planner with recrawl interval bounds
+func NewPlanner(min time.Duration, max time.Duration) *Planner {
+	if max < min {
+		max = min
+	}
+	return &Planner{Min: min, Max: max, RetryBackoff: defaultRetryBackoff, entries: make(map[string]*PlanEntry)}
+}
+
+// defaultRetryBackoff planner default delay of the first retry of retryable failure
+const defaultRetryBackoff = 5 * time.Minute
+
+// Observe record crawl result of domain and return the next planned crawl date. First crawl of a domain is planned
+// using the Ads.txt file default expiration, clamped to planner bounds
+func (p *Planner) Observe(res *Response, crawledAt time.Time) time.Time {
+	if res == nil || res.Request == nil || res.Records == nil {
+		return time.Time{}
+	}
+
+	digest := sha256.Sum256([]byte(strings.Join(res.Body, "\n")))
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	e, ok := p.entries[res.Request.Domain.String()]
+	switch {
+	case !ok:
+		e = &PlanEntry{Domain: res.Request.Domain.String(), Interval: p.clamp(defaultExpiration), LastChange: crawledAt}
+		p.entries[e.Domain] = e
+	case e.Digest != digest:
+		e.Changes++
+		e.LastChange = crawledAt
+		e.Interval = p.clamp(e.Interval / 2)
+	default:
+		e.Interval = p.clamp(e.Interval * 2)
+	}
+
+	e.Digest = digest
+	e.Failures = 0
+	e.LastFailure = ""
+	e.Observations++
+	e.LastCrawl = crawledAt
+	e.Next = crawledAt.Add(e.Interval)
+
+	return e.Next
+}
+
+// ObserveError record failed crawl of domain and return the next planned crawl date. Retryable failures (origin
+// errors) are retried with exponential backoff up to min interval, other failures are recrawled on the current
+// interval
+func (p *Planner) ObserveError(req *Request, err error, crawledAt time.Time) time.Time {
+	if req == nil || err == nil {
+		return time.Time{}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	e, ok := p.entries[req.Domain.String()]
+	if !ok {
+		e = &PlanEntry{Domain: req.Domain.String(), Interval: p.clamp(defaultExpiration)}
+		p.entries[e.Domain] = e
+	}
+
+	e.Failures++
+	e.LastFailure = FailureClass(err)
+	e.LastCrawl = crawledAt
+
+	delay := e.Interval
+	if Retryable(err) {
+		delay = p.RetryBackoff
+		for i := 1; i < e.Failures && delay < p.Min; i++ {
+			delay *= 2
+		}
+		if delay > p.Min || delay <= 0 {
+			delay = p.Min
+		}
+	}
+	e.Next = crawledAt.Add(delay)
+
+	return e.Next
+}
+
+// Entry return copy of domain plan entry, nil if domain was never observed
+func (p *Planner) Entry(domain string) *PlanEntry {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	e, ok := p.entries[domain]
+	if !ok {
+		return nil
+	}
+	entry := *e
+	return &entry
+}
+
+// Due return domains planned to be crawled on or before now, most overdue first
+func (p *Planner) Due(now time.Time) []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	due := []*PlanEntry{}
+	for _, e := range p.entries {
+		if !e.Next.After(now) {
+			due = append(due, e)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].Next.Equal(due[j].Next) {
+			return due[i].Next.Before(due[j].Next)
+		}
+		return due[i].Domain < due[j].Domain
+	})
+
+	domains := make([]string, len(due))
+	for i, e := range due {
+		domains[i] = e.Domain
+	}
+	return domains
+}
+
+// Remove domain crawl history
+func (p *Planner) Remove(domain string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.entries, domain)
+}
+
+// clamp recrawl interval to planner bounds
+func (p *Planner) clamp(d time.Duration) time.Duration {
+	if d < p.Min {
+		return p.Min
+	}
+	if d > p.Max {
+		return p.Max
+	}
+	return d
+}
@@ -0,0 +1,84 @@
+package adstxt
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPlanner test recrawl interval adapts to file change frequency within planner bounds
+func TestPlanner(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC)
+
+	p := NewPlanner(day, 4*day)
+
+	crawl := func(domain string, body string) time.Time {
+		res := &Response{Request: &Request{Domain: Domain(domain)}, Records: &Records{Body: []string{body}}}
+		return p.Observe(res, now)
+	}
+
+	// first crawl is planned by default expiration clamped to max interval
+	if next := crawl("example.com", "a"); !next.Equal(now.Add(4 * day)) {
+		t.Errorf("Expected first crawl to be planned in [%s] and not [%s]", 4*day, next.Sub(now))
+	}
+
+	// changing file interval is halved down to min interval, stable file is doubled up to max
+	expected := []struct {
+		body     string
+		interval time.Duration
+	}{
+		{"b", 2 * day},
+		{"c", day},
+		{"d", day},
+		{"d", 2 * day},
+		{"d", 4 * day},
+		{"d", 4 * day},
+	}
+	for i, e := range expected {
+		crawl("example.com", e.body)
+		if entry := p.Entry("example.com"); entry.Interval != e.interval {
+			t.Errorf("[%d] Expected recrawl interval [%s] and not [%s]", i, e.interval, entry.Interval)
+		}
+	}
+	if entry := p.Entry("example.com"); entry.Observations != 7 || entry.Changes != 3 {
+		t.Errorf("Expected [7] observations and [3] changes and not [%d] [%d]", entry.Observations, entry.Changes)
+	}
+
+	crawl("test.com", "a")
+	crawl("test.com", "b")
+	if due := p.Due(now.Add(2 * day)); len(due) != 1 || due[0] != "test.com" {
+		t.Errorf("Expected only [test.com] to be due and not %v", due)
+	}
+	if due := p.Due(now.Add(4 * day)); len(due) != 2 || due[0] != "test.com" {
+		t.Errorf("Expected [test.com] and [example.com] to be due and not %v", due)
+	}
+}
+
+// TestPlannerObserveError test retryable failures are recrawled with backoff, other failures on current interval
+func TestPlannerObserveError(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC)
+
+	p := NewPlanner(day, 4*day)
+	p.RetryBackoff = time.Hour
+	req := &Request{Domain: "example.com"}
+
+	origin := &StatusError{StatusCode: 503}
+	for _, expected := range []time.Duration{time.Hour, 2 * time.Hour, 4 * time.Hour, 8 * time.Hour, 16 * time.Hour, day, day} {
+		if next := p.ObserveError(req, origin, now); !next.Equal(now.Add(expected)) {
+			t.Errorf("Expected origin error to be retried in [%s] and not [%s]", expected, next.Sub(now))
+		}
+	}
+	if e := p.Entry("example.com"); e.Failures != 7 || e.LastFailure != FailureOrigin {
+		t.Errorf("Expected [7] origin failures and not [%d] [%s]", e.Failures, e.LastFailure)
+	}
+
+	if next := p.ObserveError(req, &StatusError{StatusCode: 404}, now); !next.Equal(now.Add(4 * day)) {
+		t.Errorf("Expected client error to be recrawled on current interval and not [%s]", next.Sub(now))
+	}
+
+	p.Observe(&Response{Request: req, Records: &Records{Body: []string{"a"}}}, now)
+	if e := p.Entry("example.com"); e.Failures != 0 || len(e.LastFailure) != 0 {
+		t.Errorf("Expected failures to be reset on successful crawl")
+	}
+}
@@ -0,0 +1,183 @@
+package adstxt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Politeness backoff bounds of throttled hosts (429 responses without Retry-After hint)
+const (
+	minThrottleBackoff = time.Minute
+	maxThrottleBackoff = 24 * time.Hour
+)
+
+// errHostBackoff request was not sent, since host is backed off after throttling the crawler
+const errHostBackoff = "[%s] host [%s] is backed off until [%s] after throttling the crawler"
+
+// HostState politeness state of single host
+type HostState struct {
+	BackoffUntil  time.Time     `json:"backoffUntil"`  // BackoffUntil host is not crawled before this date
+	RetryAfter    time.Duration `json:"retryAfter"`    // RetryAfter last Retry-After delay requested by host
+	Throttled     int           `json:"throttled"`     // Throttled total number of 429 (Too Many Requests) responses
+	Consecutive   int           `json:"consecutive"`   // Consecutive number of 429 responses since the last successful response
+	LastThrottled time.Time     `json:"lastThrottled"` // LastThrottled date of the last 429 response
+	CrawlDelay    time.Duration `json:"crawlDelay"`    // CrawlDelay minimum delay between requests to host (e.g. robots.txt Crawl-delay)
+	LastRequest   time.Time     `json:"lastRequest"`   // LastRequest date of the last request sent to host
+}
+
+// BackoffError is returned when request was not sent, since its host is backed off after throttling the crawler
+type BackoffError struct {
+	Domain string    // Domain request root domain
+	Host   string    // Host backed off host
+	Until  time.Time // Until host is backed off
+}
+
+func (e *BackoffError) Error() string {
+	return fmt.Sprintf(errHostBackoff, e.Domain, e.Host, e.Until.UTC().Format(time.RFC3339))
+}
+
+// Politeness per host politeness state: backoff of throttled hosts, Retry-After hints and crawl delays. State can
+// be saved and loaded between crawl runs, so new runs don't re-offend hosts which throttled previous runs.
+// Politeness is safe for concurrent use
+type Politeness struct {
+	DefaultCrawlDelay time.Duration // DefaultCrawlDelay minimum delay between requests to hosts without crawl delay of their own
+
+	lock  sync.Mutex
+	hosts map[string]*HostState
+	now   func() time.Time
+}
+
+// NewPoliteness create new empty politeness state
+func NewPoliteness() *Politeness {
+	return &Politeness{hosts: make(map[string]*HostState), now: time.Now}
+}
+
+// LoadPoliteness load politeness state saved by Save, missing file is loaded as empty state
+func LoadPoliteness(path string, codec Codec) (*Politeness, error) {
+	p := NewPoliteness()
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := codec.Unmarshal(b, &p.hosts); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Save politeness state to file atomically
+func (p *Politeness) Save(path string, codec Codec) error {
+	p.lock.Lock()
+	b, err := codec.Marshal(p.hosts)
+	p.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b)
+}
+
+// Host return copy of host state, nil if host has no state
+func (p *Politeness) Host(host string) *HostState {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	s, ok := p.hosts[host]
+	if !ok {
+		return nil
+	}
+	state := *s
+	return &state
+}
+
+// SetCrawlDelay set minimum delay between requests to host (e.g. robots.txt Crawl-delay)
+func (p *Politeness) SetCrawlDelay(host string, d time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.state(host).CrawlDelay = d
+}
+
+// state return host state, created when missing. Caller must hold the lock
+func (p *Politeness) state(host string) *HostState {
+	s, ok := p.hosts[host]
+	if !ok {
+		s = &HostState{}
+		p.hosts[host] = s
+	}
+	return s
+}
+
+// admit check if request to host can be sent: backed off hosts are refused, and requests are spaced by host crawl
+// delay. Returns the delay to wait before sending the request (the request slot is reserved)
+func (p *Politeness) admit(req *Request, host string) (time.Duration, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	s := p.state(host)
+	now := p.now()
+	if now.Before(s.BackoffUntil) {
+		return 0, &BackoffError{Domain: req.Domain.String(), Host: host, Until: s.BackoffUntil}
+	}
+
+	delay := s.CrawlDelay
+	if delay == 0 {
+		delay = p.DefaultCrawlDelay
+	}
+
+	at := now
+	if next := s.LastRequest.Add(delay); delay > 0 && next.After(now) {
+		at = next
+	}
+	s.LastRequest = at
+	return at.Sub(now), nil
+}
+
+// observe record host response: 429 responses back the host off using its Retry-After hint (or exponential
+// backoff), origin errors with Retry-After hint back the host off for the hinted delay
+func (p *Politeness) observe(host string, res *http.Response) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	s := p.state(host)
+	now := p.now()
+	hint := retryAfter(res.Header, now)
+	if hint > 0 {
+		s.RetryAfter = hint
+	}
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		s.Throttled++
+		s.Consecutive++
+		s.LastThrottled = now
+
+		backoff := hint
+		if backoff == 0 {
+			backoff = minThrottleBackoff
+			for i := 1; i < s.Consecutive && backoff < maxThrottleBackoff; i++ {
+				backoff *= 2
+			}
+		}
+		if backoff > maxThrottleBackoff {
+			backoff = maxThrottleBackoff
+		}
+		s.BackoffUntil = now.Add(backoff)
+	case res.StatusCode >= 500 && hint > 0:
+		// server errors are retried (see Retryable), host is not backed off longer than retries wait for it
+		if hint > maxRetryAfter {
+			hint = maxRetryAfter
+		}
+		s.BackoffUntil = now.Add(hint)
+	case res.StatusCode < 400:
+		s.Consecutive = 0
+	}
+}
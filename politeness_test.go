@@ -0,0 +1,116 @@
+package adstxt
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestPoliteness test throttling hosts are backed off, and state is kept between runs
+func TestPoliteness(t *testing.T) {
+	throttle := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if throttle {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	now := time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC)
+	p := NewPoliteness()
+	p.now = func() time.Time { return now }
+
+	req, _ := NewRequest(ts.URL)
+	if _, err := NewCrawler(WithPoliteness(p)).Get(req); err == nil {
+		t.Fatal("Expected throttled request to fail")
+	}
+
+	host := ts.Listener.Addr().String()
+	if s := p.Host(host); s == nil || s.Throttled != 1 || s.RetryAfter != 2*time.Minute || !s.BackoffUntil.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("Expected host to be backed off by Retry-After and not %+v", s)
+	}
+
+	// state is kept between runs
+	path := filepath.Join(t.TempDir(), "politeness.json")
+	if err := p.Save(path, JSONCodec); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadPoliteness(path, JSONCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.now = func() time.Time { return now.Add(time.Minute) }
+
+	throttle = false
+	req, _ = NewRequest(ts.URL)
+	_, err = NewCrawler(WithPoliteness(loaded)).Get(req)
+
+	var backoffErr *BackoffError
+	if !errors.As(err, &backoffErr) || backoffErr.Host != host {
+		t.Fatalf("Expected backed off host not to be crawled and not [%v]", err)
+	}
+
+	loaded.now = func() time.Time { return now.Add(3 * time.Minute) }
+	req, _ = NewRequest(ts.URL)
+	if _, err := NewCrawler(WithPoliteness(loaded)).Get(req); err != nil {
+		t.Errorf("Expected host to be crawled after backoff and not [%s]", err)
+	}
+	if s := loaded.Host(host); s.Consecutive != 0 || s.Throttled != 1 {
+		t.Errorf("Expected consecutive throttling to be reset and not %+v", s)
+	}
+
+	// missing state file
+	if p, err := LoadPoliteness(filepath.Join(t.TempDir(), "missing.json"), JSONCodec); err != nil || p.Host(host) != nil {
+		t.Errorf("Expected missing state file to be loaded as empty state")
+	}
+}
+
+// TestPolitenessBackoff test 429 responses without Retry-After are backed off exponentially, and requests are
+// spaced by crawl delay
+func TestPolitenessBackoff(t *testing.T) {
+	now := time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC)
+	p := NewPoliteness()
+	p.now = func() time.Time { return now }
+
+	res := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		p.observe("example.com", res)
+		if s := p.Host("example.com"); !s.BackoffUntil.Equal(now.Add(expected)) {
+			t.Errorf("Expected host to be backed off for [%s] and not [%s]", expected, s.BackoffUntil.Sub(now))
+		}
+	}
+
+	// server error Retry-After is capped to retry delay, so the request is still retried
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": {"3600"}}}
+	p.observe("unavailable.com", unavailable)
+	if s := p.Host("unavailable.com"); !s.BackoffUntil.Equal(now.Add(maxRetryAfter)) {
+		t.Errorf("Expected host to be backed off for [%s] and not [%s]", maxRetryAfter, s.BackoffUntil.Sub(now))
+	}
+
+	p.SetCrawlDelay("delay.com", 10*time.Second)
+	req := &Request{Domain: "delay.com"}
+	for _, expected := range []time.Duration{0, 10 * time.Second, 20 * time.Second} {
+		if d, err := p.admit(req, "delay.com"); err != nil || d != expected {
+			t.Errorf("Expected request to be delayed [%s] and not [%s] [%v]", expected, d, err)
+		}
+	}
+
+	// default crawl delay apply to hosts without crawl delay of their own
+	p.DefaultCrawlDelay = time.Second
+	for host, expected := range map[string]time.Duration{"delay.com": 30 * time.Second, "other.com": 0} {
+		if d, err := p.admit(req, host); err != nil || d != expected {
+			t.Errorf("Expected [%s] request to be delayed [%s] and not [%s] [%v]", host, expected, d, err)
+		}
+	}
+	if d, _ := p.admit(req, "other.com"); d != time.Second {
+		t.Errorf("Expected request to be delayed by default crawl delay and not [%s]", d)
+	}
+}
@@ -0,0 +1,45 @@
+package adstxt
+
+import (
+	"bytes"
+	"html"
+)
+
+// Preprocessor transform Ads.txt file body before it is parsed (e.g. sanitize content produced by a broken CMS)
+type Preprocessor struct {
+	Name    string              // Name of the transformation, recorded on Records.Preprocessed when applied
+	Process func([]byte) []byte // Process return transformed body
+}
+
+// Built in preprocessors
+var (
+	// StripBOM remove UTF-8 byte order mark from the beginning of the body
+	StripBOM = Preprocessor{Name: "strip-bom", Process: func(b []byte) []byte {
+		return bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	}}
+	// StripNullBytes remove null bytes (e.g. UTF-16 encoded files)
+	StripNullBytes = Preprocessor{Name: "strip-null-bytes", Process: func(b []byte) []byte {
+		return bytes.ReplaceAll(b, []byte{0}, nil)
+	}}
+	// HTMLUnescape unescape HTML entities (e.g. &amp; or &#44;) of files edited through HTML editors
+	HTMLUnescape = Preprocessor{Name: "html-unescape", Process: func(b []byte) []byte {
+		if bytes.IndexByte(b, '&') == -1 {
+			return b
+		}
+		return []byte(html.UnescapeString(string(b)))
+	}}
+)
+
+// Preprocess apply preprocessors to body in order, and return transformed body with names of the preprocessors
+// that changed it
+func Preprocess(b []byte, preprocessors ...Preprocessor) ([]byte, []string) {
+	applied := []string{}
+	for _, p := range preprocessors {
+		processed := p.Process(b)
+		if !bytes.Equal(processed, b) {
+			applied = append(applied, p.Name)
+		}
+		b = processed
+	}
+	return b, applied
+}
@@ -0,0 +1,56 @@
+package adstxt
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestPreprocess test built in preprocessors and applied transformations
+func TestPreprocess(t *testing.T) {
+	bodies := map[string]struct {
+		expected string
+		applied  int
+	}{
+		"\xef\xbb\xbfgoogle.com, pub-1, DIRECT":        {"google.com, pub-1, DIRECT", 1},
+		"g\x00o\x00o\x00g\x00l\x00e\x00.com, pub-1":    {"google.com, pub-1", 1},
+		"google.com&#44; pub-1&#44; DIRECT &amp; more": {"google.com, pub-1, DIRECT & more", 1},
+		"\xef\xbb\xbfgoogle.com&#44; pub-1":            {"google.com, pub-1", 2},
+		"google.com, pub-1, DIRECT":                    {"google.com, pub-1, DIRECT", 0},
+	}
+
+	for body, e := range bodies {
+		b, applied := Preprocess([]byte(body), StripBOM, StripNullBytes, HTMLUnescape)
+		if string(b) != e.expected || len(applied) != e.applied {
+			t.Errorf("[%q] Expected [%q] with [%d] transformations and not [%q] %v", body, e.expected, e.applied, b, applied)
+		}
+	}
+}
+
+// TestGetPreprocessors test crawler preprocessors are applied before parsing and recorded on response
+func TestGetPreprocessors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "\xef\xbb\xbfgoogle.com, pub-1, DIRECT\nCORP-SECRET google.com, pub-2, DIRECT")
+	}))
+	defer ts.Close()
+
+	sanitize := Preprocessor{Name: "corp-sanitizer", Process: func(b []byte) []byte {
+		return bytes.ReplaceAll(b, []byte("CORP-SECRET "), nil)
+	}}
+
+	req, _ := NewRequest(ts.URL)
+	res, err := NewCrawler(WithPreprocessors(StripBOM, HTMLUnescape, sanitize)).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.DataRecords) != 2 || len(res.Warnings) != 0 {
+		t.Errorf("Expected [2] records and no warnings and not [%d] [%d]", len(res.DataRecords), len(res.Warnings))
+	}
+	if len(res.Preprocessed) != 2 || res.Preprocessed[0] != "strip-bom" || res.Preprocessed[1] != "corp-sanitizer" {
+		t.Errorf("Expected strip-bom and corp-sanitizer to be recorded and not %v", res.Preprocessed)
+	}
+}
@@ -0,0 +1,59 @@
+package adstxt
+
+import (
+	"time"
+)
+
+// Preset combine options into single named crawler configuration, options are applied in order. Options passed to
+// NewCrawler after the preset override its settings
+func Preset(opts ...Option) Option {
+	return func(c *Crawler) {
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
+
+// Crawler configuration presets, see BenchmarkPresets for their throughput on bulk crawl
+var (
+	// PresetOnlineCheck fetch single Ads.txt file with low latency (e.g. on seller authorization check): https:// and
+	// http:// are raced, requests time out quickly and origin errors (5xx) are retried once
+	PresetOnlineCheck = Preset(
+		WithConnectionBudget(DefaultBudget),
+		WithSchemeRacing(false),
+		WithTimeout(10*time.Second),
+		WithRetry(1, 250*time.Millisecond),
+	)
+
+	// PresetBulkScan crawl large list of domains for throughput: keep-alives are bounded by BulkBudget, hosts are
+	// resolved upfront, non Ads.txt bodies (e.g. HTML pages) are aborted early and origin errors are not retried
+	PresetBulkScan = Preset(
+		WithConnectionBudget(BulkBudget),
+		WithConcurrency(256),
+		WithPreResolve(64),
+		WithEarlyAbort(20),
+		WithTimeout(15*time.Second),
+		WithRetry(0, 0),
+	)
+
+	// PresetPoliteMonitor periodically re-crawl the same domains: single connection per host, at most one
+	// request per host each second (unless host crawl delay is longer) and origin errors are retried with backoff
+	PresetPoliteMonitor = Preset(
+		WithConnectionBudget(ConnectionBudget{
+			MaxIdleConns:        256,
+			MaxIdleConnsPerHost: 1,
+			MaxConnsPerHost:     1,
+			MaxConns:            512,
+			IdleConnTimeout:     90 * time.Second,
+		}),
+		WithConcurrency(16),
+		WithTimeout(30*time.Second),
+		WithRetry(3, 5*time.Second),
+		func(c *Crawler) {
+			// politeness state is created per crawler, since it tracks hosts requested by the crawler
+			p := NewPoliteness()
+			p.DefaultCrawlDelay = time.Second
+			c.politeness = p
+		},
+	)
+)
@@ -0,0 +1,91 @@
+package adstxt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPresets test presets configure crawler, and options passed after preset override its settings
+func TestPresets(t *testing.T) {
+	c := NewCrawler(PresetOnlineCheck)
+	if !c.race || c.timeout != 10*time.Second || c.retries != 1 {
+		t.Errorf("Expected online check preset to race schemes with short timeout and not [%t] [%s] [%d]", c.race, c.timeout, c.retries)
+	}
+
+	c = NewCrawler(PresetBulkScan)
+	if c.concurrency != 256 || c.resolveConcurrency == 0 || c.abortAfter == 0 || c.retries != 0 {
+		t.Errorf("Expected bulk scan preset to tune concurrency and not [%d] [%d] [%d] [%d]", c.concurrency, c.resolveConcurrency, c.abortAfter, c.retries)
+	}
+	if tr := c.client.Transport.(*http.Transport); tr.MaxConnsPerHost != BulkBudget.MaxConnsPerHost {
+		t.Errorf("Expected bulk scan preset to use bulk connection budget and not [%d] connections per host", tr.MaxConnsPerHost)
+	}
+
+	c = NewCrawler(PresetPoliteMonitor, WithConcurrency(4))
+	if c.politeness == nil || c.politeness.DefaultCrawlDelay != time.Second {
+		t.Errorf("Expected polite monitor preset to set default crawl delay")
+	}
+	if c.concurrency != 4 {
+		t.Errorf("Expected option to override preset concurrency and not [%d]", c.concurrency)
+	}
+	if other := NewCrawler(PresetPoliteMonitor); other.politeness == c.politeness {
+		t.Errorf("Expected each crawler to have its own politeness state")
+	}
+}
+
+// BenchmarkPresets benchmark bulk crawl of distinct hosts using each preset, against test server adding fixed latency
+// to each response
+func BenchmarkPresets(b *testing.B) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT\ngreenadexchange.com,XF7343,RESELLER\ncontact=ads@example.com")
+	}))
+	defer ts.Close()
+
+	// route all hosts to test server, pre-resolution resolve all hosts to loopback
+	route := func(c *Crawler) {
+		routeTo(ts, nil)(c)
+		c.lookup = func(ctx context.Context, host string) ([]net.IP, error) {
+			return []net.IP{net.IPv4(127, 0, 0, 1)}, nil
+		}
+	}
+
+	presets := map[string]Option{
+		"OnlineCheck":   PresetOnlineCheck,
+		"BulkScan":      PresetBulkScan,
+		"PoliteMonitor": PresetPoliteMonitor,
+	}
+
+	for name, preset := range presets {
+		b.Run(name, func(b *testing.B) {
+			c := NewCrawler(preset, route)
+			for i := 0; i < b.N; i++ {
+				req := make([]*Request, 0, 100)
+				for j := 0; j < cap(req); j++ {
+					r, err := NewRequest(fmt.Sprintf("pub%d-%d.com", i, j))
+					if err != nil {
+						b.Fatal(err)
+					}
+					req = append(req, r)
+				}
+
+				var failed int32
+				c.GetMultiple(req, HandlerFunc(func(req *Request, res *Response, err error) {
+					if err != nil {
+						atomic.AddInt32(&failed, 1)
+					}
+				}))
+				if failed > 0 {
+					b.Fatalf("[%d] requests failed", failed)
+				}
+			}
+		})
+	}
+}
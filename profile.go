@@ -0,0 +1,242 @@
+package adstxt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Publisher profile file kinds
+const (
+	// FileAdsTxt web inventory Ads.txt file
+	FileAdsTxt = "ads.txt"
+	// FileAppAdsTxt mobile and CTV apps inventory app-ads.txt file
+	FileAppAdsTxt = "app-ads.txt"
+)
+
+// Publisher profile file relations to the publisher domain
+const (
+	// RelationRoot file is posted on the publisher root domain
+	RelationRoot = "root"
+	// RelationSubdomain file is posted on a subdomain declared by SUBDOMAIN variable
+	RelationSubdomain = "subdomain"
+	// RelationInventoryPartner file is posted on an inventory partner domain declared by INVENTORYPARTNERDOMAIN variable
+	RelationInventoryPartner = "inventorypartnerdomain"
+)
+
+// Profile record source file types, combining file kind and relation. Consumers may apply inventory type
+// specific authorization logic based on the source type of the files declaring a record
+const (
+	// SourceAdsTxt root domain ads.txt file
+	SourceAdsTxt = FileAdsTxt
+	// SourceAppAdsTxt root domain app-ads.txt file
+	SourceAppAdsTxt = FileAppAdsTxt
+	// SourceSubdomainAdsTxt ads.txt file of a declared subdomain
+	SourceSubdomainAdsTxt = "subdomain-" + FileAdsTxt
+	// SourceSubdomainAppAdsTxt app-ads.txt file of a declared subdomain
+	SourceSubdomainAppAdsTxt = "subdomain-" + FileAppAdsTxt
+	// SourceInventoryPartnerAdsTxt ads.txt file of a declared inventory partner domain
+	SourceInventoryPartnerAdsTxt = "inventorypartner-" + FileAdsTxt
+	// SourceInventoryPartnerAppAdsTxt app-ads.txt file of a declared inventory partner domain
+	SourceInventoryPartnerAppAdsTxt = "inventorypartner-" + FileAppAdsTxt
+)
+
+// sourceType return source file type of file kind and relation
+func sourceType(kind string, relation string) string {
+	switch relation {
+	case RelationSubdomain:
+		return "subdomain-" + kind
+	case RelationInventoryPartner:
+		return "inventorypartner-" + kind
+	}
+	return kind
+}
+
+// Default publisher profile limits
+const (
+	// DefaultMaxSubdomains maximum number of followed SUBDOMAIN declarations
+	DefaultMaxSubdomains = 10
+	// DefaultMaxInventoryPartners maximum number of followed INVENTORYPARTNERDOMAIN declarations
+	DefaultMaxInventoryPartners = 10
+)
+
+// publisher profile errors
+const (
+	errProfileNotFound    = "[%s] failed to fetch both ads.txt and app-ads.txt files"
+	errProfileNotInDomain = "[%s] declared subdomain is not within root domain [%s]"
+	errProfileLimit       = "[%s] not followed, reached the maximum number [%d] of followed [%s] declarations"
+)
+
+// ProfileLimits hold the maximum number of declarations followed when building publisher profile
+type ProfileLimits struct {
+	MaxSubdomains        int // MaxSubdomains maximum number of followed SUBDOMAIN declarations
+	MaxInventoryPartners int // MaxInventoryPartners maximum number of followed INVENTORYPARTNERDOMAIN declarations
+}
+
+// ProfileFile single file fetched while building publisher profile
+type ProfileFile struct {
+	Kind     string    `json:"kind"`               // Kind of the file: ads.txt or app-ads.txt
+	Relation string    `json:"relation"`           // Relation of the file host to the publisher: root, subdomain or inventorypartnerdomain
+	Source   string    `json:"source"`             // Source file type (see SourceAdsTxt etc.)
+	URL      string    `json:"url"`                // URL of the file
+	Response *Response `json:"response,omitempty"` // Response parsed file, nil on error
+	Error    string    `json:"error,omitempty"`    // Error fetching the file
+}
+
+// ProfileRecord data record consolidated from all publisher files
+type ProfileRecord struct {
+	*DataRecord
+	Sources     []string `json:"sources"`     // Sources URLs of the files declaring the record
+	SourceTypes []string `json:"sourceTypes"` // SourceTypes distinct source file types of the files declaring the record
+}
+
+// PublisherProfile consolidated view of all the Ads.txt files of a publisher
+type PublisherProfile struct {
+	Domain         string           `json:"domain"`         // Domain publisher root domain
+	Files          []*ProfileFile   `json:"files"`          // Files fetched for the publisher, root files first
+	DataRecords    []*ProfileRecord `json:"dataRecords"`    // DataRecords unique data records across all files
+	Contacts       []string         `json:"contacts"`       // Contacts declared in all files
+	OwnerDomains   []string         `json:"ownerDomains"`   // OwnerDomains declared in all files
+	ManagerDomains []string         `json:"managerDomains"` // ManagerDomains declared in all files
+}
+
+// Profile fetch publisher ads.txt and app-ads.txt files, follow SUBDOMAIN and INVENTORYPARTNERDOMAIN declarations
+// and return consolidated publisher profile
+func Profile(domain string) (*PublisherProfile, error) {
+	return NewCrawler().Profile(domain)
+}
+
+// Profile fetch publisher ads.txt and app-ads.txt files using crawler, see Profile. Declarations are followed
+// once (declarations of followed files are ignored) up to crawler profile limits
+func (c *Crawler) Profile(domain string) (*PublisherProfile, error) {
+	root, err := NewRequest(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PublisherProfile{
+		Domain:         root.Domain.String(),
+		Files:          []*ProfileFile{},
+		DataRecords:    []*ProfileRecord{},
+		Contacts:       []string{},
+		OwnerDomains:   []string{},
+		ManagerDomains: []string{},
+	}
+
+	// root domain files
+	roots := []*ProfileFile{}
+	for _, kind := range []string{FileAdsTxt, FileAppAdsTxt} {
+		f := c.profileFile(kind, RelationRoot, domain)
+		p.Files = append(p.Files, f)
+		if f.Response != nil {
+			roots = append(roots, f)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf(errProfileNotFound, root.Domain)
+	}
+
+	// follow root files declarations, using the same file kind as the declaring file
+	followed := map[string]bool{}
+	counts := map[string]int{}
+	for _, r := range roots {
+		for _, v := range r.Response.Variables {
+			relation, limit := "", 0
+			switch v.Type {
+			case varTypeSubdomain:
+				relation, limit = RelationSubdomain, c.profileLimits.MaxSubdomains
+			case varTypeInventoryPartnerDomain:
+				relation, limit = RelationInventoryPartner, c.profileLimits.MaxInventoryPartners
+			default:
+				continue
+			}
+
+			host := strings.ToLower(removeComment(v.Value))
+			key := r.Kind + " " + host
+			if len(host) == 0 || followed[key] {
+				continue
+			}
+			followed[key] = true
+
+			if counts[relation] >= limit {
+				p.Files = append(p.Files, &ProfileFile{Kind: r.Kind, Relation: relation, Source: sourceType(r.Kind, relation), URL: host, Error: fmt.Sprintf(errProfileLimit, host, limit, v.Type)})
+				continue
+			}
+			counts[relation]++
+
+			// subdomain declaration is valid only within the publisher root domain
+			if relation == RelationSubdomain {
+				if d, err := RootDomain(host); err != nil || d != p.Domain || host == p.Domain {
+					p.Files = append(p.Files, &ProfileFile{Kind: r.Kind, Relation: relation, Source: sourceType(r.Kind, relation), URL: host, Error: fmt.Sprintf(errProfileNotInDomain, host, p.Domain)})
+					continue
+				}
+			}
+
+			p.Files = append(p.Files, c.profileFile(r.Kind, relation, host))
+		}
+	}
+
+	p.consolidate()
+	return p, nil
+}
+
+// profileFile fetch and parse single publisher file from host
+func (c *Crawler) profileFile(kind string, relation string, host string) *ProfileFile {
+	f := &ProfileFile{Kind: kind, Relation: relation, Source: sourceType(kind, relation), URL: host}
+
+	// app-ads.txt path is explicit, so crawler default path (see WithDefaultPath) only replaces ads.txt path
+	opts := []RequestOption{}
+	if kind == FileAppAdsTxt {
+		opts = append(opts, WithPath("/"+FileAppAdsTxt))
+	}
+	req, err := NewRequest(host, opts...)
+	if err != nil {
+		f.Error = err.Error()
+		return f
+	}
+	if len(c.path) > 0 {
+		req.defaultPath(c.path)
+	}
+	f.URL = req.URL
+
+	res, err := c.Get(req)
+	if err != nil {
+		f.Error = err.Error()
+		return f
+	}
+	f.Response = res
+	return f
+}
+
+// consolidate merge data records and variables of all fetched files
+func (p *PublisherProfile) consolidate() {
+	records := map[string]*ProfileRecord{}
+	for _, f := range p.Files {
+		if f.Response == nil {
+			continue
+		}
+
+		for _, dr := range f.Response.DataRecords {
+			key := strings.Join([]string{dr.AdverterDomain.String(), strings.ToLower(dr.PublisherAccountID), dr.AccountType}, ",")
+			if r, ok := records[key]; ok {
+				r.Sources = appendFlag(r.Sources, f.URL)
+				r.SourceTypes = appendFlag(r.SourceTypes, f.Source)
+				continue
+			}
+			r := &ProfileRecord{DataRecord: dr, Sources: []string{f.URL}, SourceTypes: []string{f.Source}}
+			records[key] = r
+			p.DataRecords = append(p.DataRecords, r)
+		}
+
+		for _, v := range f.Response.Variables {
+			value := removeComment(v.Value)
+			switch v.Type {
+			case varTypeContact:
+				p.Contacts = appendFlag(p.Contacts, value)
+			case varTypeOwnerDomain:
+				p.OwnerDomains = appendFlag(p.OwnerDomains, strings.ToLower(value))
+			case varTypeManagerDomain:
+				p.ManagerDomains = appendFlag(p.ManagerDomains, strings.ToLower(value))
+			}
+		}
+	}
+}
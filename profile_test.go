@@ -0,0 +1,137 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestProfile test publisher profile consolidate root, subdomain and inventory partner files
+func TestProfile(t *testing.T) {
+	files := map[string]string{
+		"example.com/ads.txt":      "greenadexchange.com,XF7342,DIRECT\nsubdomain=news.example.com\nsubdomain=other.com\ninventorypartnerdomain=partner.com\ncontact=ads@example.com\nOWNERDOMAIN=example.com",
+		"news.example.com/ads.txt": "greenadexchange.com,XF7342,DIRECT\ngreenadexchange.com,XF7343,RESELLER\ncontact=ads@example.com",
+		"partner.com/ads.txt":      "greenadexchange.com,1234,DIRECT\nsubdomain=ctv.partner.com\nmanagerdomain=partner.com",
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.Host+r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, body)
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, nil)
+
+	p, err := NewCrawler(route).Profile("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// root ads.txt and app-ads.txt, news subdomain, other.com (not in domain) and partner
+	expected := map[string]string{
+		"http://example.com/ads.txt":      "",
+		"http://example.com/app-ads.txt":  "error",
+		"http://news.example.com/ads.txt": "",
+		"other.com":                       "error",
+		"http://partner.com/ads.txt":      "",
+	}
+	if len(p.Files) != len(expected) {
+		t.Errorf("Expected [%d] profile files and not [%d]", len(expected), len(p.Files))
+	}
+	for _, f := range p.Files {
+		e, ok := expected[f.URL]
+		if !ok {
+			t.Errorf("Unexpected profile file [%s]", f.URL)
+		}
+		if (len(e) > 0) != (len(f.Error) > 0) {
+			t.Errorf("[%s] Unexpected profile file error [%s]", f.URL, f.Error)
+		}
+	}
+
+	if len(p.DataRecords) != 3 {
+		t.Errorf("Expected [3] consolidated data records and not [%d]", len(p.DataRecords))
+	}
+	if len(p.DataRecords) > 0 && len(p.DataRecords[0].Sources) != 2 {
+		t.Errorf("Expected record [%s] to be declared by [2] files and not %v", p.DataRecords[0].PublisherAccountID, p.DataRecords[0].Sources)
+	}
+
+	// records are labeled with the source file type of each declaring file
+	types := map[string][]string{
+		"XF7342": {SourceAdsTxt, SourceSubdomainAdsTxt},
+		"XF7343": {SourceSubdomainAdsTxt},
+		"1234":   {SourceInventoryPartnerAdsTxt},
+	}
+	for _, r := range p.DataRecords {
+		if e := types[r.PublisherAccountID]; strings.Join(e, ",") != strings.Join(r.SourceTypes, ",") {
+			t.Errorf("[%s] Expected record source types %v and not %v", r.PublisherAccountID, e, r.SourceTypes)
+		}
+	}
+	if p.Files[1].Source != SourceAppAdsTxt {
+		t.Errorf("Expected root app-ads.txt file source type [%s] and not [%s]", SourceAppAdsTxt, p.Files[1].Source)
+	}
+
+	if len(p.Contacts) != 1 || len(p.OwnerDomains) != 1 || len(p.ManagerDomains) != 1 {
+		t.Errorf("Expected [1] contact, owner and manager domain and not %v %v %v", p.Contacts, p.OwnerDomains, p.ManagerDomains)
+	}
+}
+
+// TestProfileLimits test publisher profile declarations are followed up to crawler limits
+func TestProfileLimits(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "subdomain=a.example.com\nsubdomain=b.example.com\nsubdomain=c.example.com")
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, nil)
+
+	p, err := NewCrawler(route, WithProfileLimits(ProfileLimits{MaxSubdomains: 1})).Profile("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ads.txt and app-ads.txt root files both declare the 3 subdomains, only first one is followed
+	fetched, skipped := 0, 0
+	for _, f := range p.Files {
+		if f.Relation == RelationSubdomain && f.Response != nil {
+			fetched++
+		}
+		if f.Relation == RelationSubdomain && f.Response == nil {
+			skipped++
+		}
+	}
+	if fetched != 1 || skipped != 5 {
+		t.Errorf("Expected [1] followed and [5] skipped subdomains and not [%d] [%d]", fetched, skipped)
+	}
+}
+
+// TestProfileDefaultPath test crawler default path replace ads.txt path only, app-ads.txt is still fetched
+func TestProfileDefaultPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	p, err := NewCrawler(routeTo(ts, nil), WithDefaultPath("/.well-known/ads.txt")).Profile("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"http://example.com/.well-known/ads.txt", "http://example.com/app-ads.txt"}
+	if len(p.Files) != len(expected) {
+		t.Fatalf("Expected [%d] profile files and not [%d]", len(expected), len(p.Files))
+	}
+	for i, f := range p.Files {
+		if f.URL != expected[i] || f.Response == nil || f.Response.Request.URL != expected[i] {
+			t.Errorf("Expected profile file [%s] to be fetched and not [%s] [%s]", expected[i], f.URL, f.Error)
+		}
+	}
+}
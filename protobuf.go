@@ -0,0 +1,586 @@
+package adstxt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// protocol buffers wire types
+const (
+	pbVarint  = 0
+	pbFixed64 = 1
+	pbBytes   = 2
+	pbFixed32 = 5
+)
+
+// google.protobuf.Value fields (struct.proto well known type)
+const (
+	pbValueNull   = 1
+	pbValueNumber = 2
+	pbValueString = 3
+	pbValueBool   = 4
+	pbValueStruct = 5
+	pbValueList   = 6
+)
+
+// protobufCodec protocol buffers wire encoding without schema (see ProtobufCodec): structs are messages with field number of each field being its position
+// (starting at 1), slices are repeated fields and maps are repeated key (1) and value (2) entries. Other top level
+// values are encoded as field 1 of a message
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string {
+	return "protobuf"
+}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.IsValid() && pbIsMessage(rv.Type()) {
+		return pbEncodeStruct(rv, 0)
+	}
+	return pbEncodeField(nil, 1, rv, true, 0)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	target, err := decodeTarget("protobuf", v)
+	if err != nil {
+		return err
+	}
+	for target.Kind() == reflect.Pointer {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+	if pbIsMessage(target.Type()) {
+		return pbDecodeStruct(target, data, 0)
+	}
+
+	fields, err := pbFields(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.num == 1 {
+			if err := pbAssign(target, f, 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pbIsMessage check if type values are encoded as message fields
+func pbIsMessage(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && bytesEncoding(t) == bytesNone
+}
+
+// pbIsWrapped check if repeated field item or map value of type is wrapped in a message (as field 1), slices and
+// maps can't be repeated as is
+func pbIsWrapped(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if bytesEncoding(t) != bytesNone {
+		return false
+	}
+	return t.Kind() == reflect.Map || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8)
+}
+
+// pbEncodeStruct return message encoding of struct fields
+func pbEncodeStruct(v reflect.Value, depth int) ([]byte, error) {
+	b := []byte{}
+	for i, f := range codecFields(v.Type()) {
+		var err error
+		if b, err = pbEncodeField(b, i+1, v.Field(f), false, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// pbEncodeField append field encoding of value, zero value is omitted unless forced (repeated items and map entries)
+func pbEncodeField(b []byte, num int, v reflect.Value, force bool, depth int) ([]byte, error) {
+	if depth > maxCodecDepth {
+		return nil, errCodecDepth
+	}
+	if !v.IsValid() {
+		return b, nil
+	}
+
+	// marshaler values (e.g. time.Time) are encoded as bytes
+	if bytesEncoding(v.Type()) != bytesNone {
+		data, err := marshalBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 && !force {
+			return b, nil
+		}
+		return pbAppendBytes(b, num, data), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			if !force {
+				return b, nil
+			}
+			return pbEncodeField(b, num, reflect.Zero(v.Type().Elem()), true, depth+1)
+		}
+		return pbEncodeField(b, num, v.Elem(), true, depth+1)
+	case reflect.Interface:
+		if v.IsNil() && !force {
+			return b, nil
+		}
+		doc, err := toDocument(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		value, err := pbEncodeValue(nil, doc, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return pbAppendBytes(b, num, value), nil
+	case reflect.Bool:
+		if !v.Bool() && !force {
+			return b, nil
+		}
+		b = pbAppendTag(b, num, pbVarint)
+		if v.Bool() {
+			return binary.AppendUvarint(b, 1), nil
+		}
+		return binary.AppendUvarint(b, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if i == 0 && !force {
+			return b, nil
+		}
+		// zigzag encoding (sint64), so small negative numbers are encoded in few bytes
+		b = pbAppendTag(b, num, pbVarint)
+		return binary.AppendUvarint(b, uint64(i<<1)^uint64(i>>63)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() == 0 && !force {
+			return b, nil
+		}
+		b = pbAppendTag(b, num, pbVarint)
+		return binary.AppendUvarint(b, v.Uint()), nil
+	case reflect.Float32:
+		if v.Float() == 0 && !force {
+			return b, nil
+		}
+		b = pbAppendTag(b, num, pbFixed32)
+		return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		if v.Float() == 0 && !force {
+			return b, nil
+		}
+		b = pbAppendTag(b, num, pbFixed64)
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(v.Float())), nil
+	case reflect.String:
+		if v.Len() == 0 && !force {
+			return b, nil
+		}
+		return pbAppendBytes(b, num, []byte(v.String())), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Len() == 0 && !force {
+				return b, nil
+			}
+			return pbAppendBytes(b, num, v.Bytes()), nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			var err error
+			if b, err = pbEncodeItem(b, num, v.Index(i), depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Map:
+		// entries are sorted by their encoding, so encoding output is deterministic
+		entries := make([][]byte, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			entry, err := pbEncodeField(nil, 1, iter.Key(), true, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if entry, err = pbEncodeItem(entry, 2, iter.Value(), depth+1); err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i], entries[j]) < 0
+		})
+
+		for _, entry := range entries {
+			b = pbAppendBytes(b, num, entry)
+		}
+		return b, nil
+	case reflect.Struct:
+		msg, err := pbEncodeStruct(v, depth)
+		if err != nil {
+			return nil, err
+		}
+		if len(msg) == 0 && !force {
+			return b, nil
+		}
+		return pbAppendBytes(b, num, msg), nil
+	}
+	return nil, fmt.Errorf("protobuf: unsupported type %s", v.Type())
+}
+
+// pbEncodeItem append repeated field item or map value encoding
+func pbEncodeItem(b []byte, num int, v reflect.Value, depth int) ([]byte, error) {
+	if !pbIsWrapped(v.Type()) {
+		return pbEncodeField(b, num, v, true, depth)
+	}
+	msg, err := pbEncodeField(nil, 1, v, true, depth)
+	if err != nil {
+		return nil, err
+	}
+	return pbAppendBytes(b, num, msg), nil
+}
+
+// pbDecodeStruct decode message fields into struct, unknown fields (e.g. encoded by newer struct version) are ignored
+func pbDecodeStruct(v reflect.Value, data []byte, depth int) error {
+	if depth > maxCodecDepth {
+		return errCodecDepth
+	}
+
+	fields, err := pbFields(data)
+	if err != nil {
+		return err
+	}
+	index := codecFields(v.Type())
+	for _, f := range fields {
+		if f.num < 1 || f.num > len(index) {
+			continue
+		}
+		if err := pbAssign(v.Field(index[f.num-1]), f, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pbAssign store decoded field in v: repeated field items are appended and map entries are added
+func pbAssign(v reflect.Value, f pbField, depth int) error {
+	if depth > maxCodecDepth {
+		return errCodecDepth
+	}
+
+	mismatch := fmt.Errorf("protobuf: cannot decode field [%d] of wire type [%d] into %s", f.num, f.wireType, v.Type())
+	wireType := pbBytes
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		wireType = pbVarint
+	case reflect.Float32:
+		wireType = pbFixed32
+	case reflect.Float64:
+		wireType = pbFixed64
+	}
+	if bytesEncoding(v.Type()) != bytesNone {
+		if f.wireType != pbBytes {
+			return mismatch
+		}
+		return unmarshalBytes(v, f.data)
+	}
+	repeated := v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8
+	if v.Kind() != reflect.Pointer && !repeated && f.wireType != wireType {
+		return mismatch
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return pbAssign(v.Elem(), f, depth+1)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return mismatch
+		}
+		doc, err := pbDecodeValue(f.data, depth+1)
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(doc))
+		}
+	case reflect.Bool:
+		v.SetBool(f.varint != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := int64(f.varint>>1) ^ -int64(f.varint&1)
+		if v.OverflowInt(i) {
+			return mismatch
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.OverflowUint(f.varint) {
+			return mismatch
+		}
+		v.SetUint(f.varint)
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(f.data))))
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(f.data)))
+	case reflect.String:
+		v.SetString(string(f.data))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte{}, f.data...))
+			return nil
+		}
+		item := reflect.New(v.Type().Elem()).Elem()
+		if err := pbAssignItem(item, f, depth+1); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, item))
+	case reflect.Map:
+		entry, err := pbFields(f.data)
+		if err != nil {
+			return err
+		}
+		key, value := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		for _, e := range entry {
+			switch e.num {
+			case 1:
+				err = pbAssign(key, e, depth+1)
+			case 2:
+				err = pbAssignItem(value, e, depth+1)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(key, value)
+	case reflect.Struct:
+		return pbDecodeStruct(v, f.data, depth)
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// pbAssignItem store decoded repeated field item or map value in v
+func pbAssignItem(v reflect.Value, f pbField, depth int) error {
+	if !pbIsWrapped(v.Type()) {
+		return pbAssign(v, f, depth)
+	}
+	if f.wireType != pbBytes {
+		return fmt.Errorf("protobuf: cannot decode field [%d] of wire type [%d] into %s", f.num, f.wireType, v.Type())
+	}
+
+	fields, err := pbFields(f.data)
+	if err != nil {
+		return err
+	}
+	for _, i := range fields {
+		if i.num == 1 {
+			if err := pbAssign(v, i, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pbEncodeValue append google.protobuf.Value message encoding of generic document value
+func pbEncodeValue(b []byte, v interface{}, depth int) ([]byte, error) {
+	if depth > maxCodecDepth {
+		return nil, errCodecDepth
+	}
+
+	switch t := v.(type) {
+	case nil:
+		b = pbAppendTag(b, pbValueNull, pbVarint)
+		b = binary.AppendUvarint(b, 0)
+	case bool:
+		b = pbAppendTag(b, pbValueBool, pbVarint)
+		if t {
+			b = binary.AppendUvarint(b, 1)
+		} else {
+			b = binary.AppendUvarint(b, 0)
+		}
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return nil, err
+		}
+		b = pbAppendTag(b, pbValueNumber, pbFixed64)
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(f))
+	case string:
+		b = pbAppendBytes(b, pbValueString, []byte(t))
+	case []interface{}:
+		// ListValue: repeated Value values = 1
+		list := []byte{}
+		for _, i := range t {
+			item, err := pbEncodeValue(nil, i, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list = pbAppendBytes(list, 1, item)
+		}
+		b = pbAppendBytes(b, pbValueList, list)
+	case map[string]interface{}:
+		// Struct: map<string, Value> fields = 1, each map entry is a message of key = 1 and value = 2
+		fields := []byte{}
+		for _, k := range sortedKeys(t) {
+			value, err := pbEncodeValue(nil, t[k], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			entry := pbAppendBytes(nil, 1, []byte(k))
+			entry = pbAppendBytes(entry, 2, value)
+			fields = pbAppendBytes(fields, 1, entry)
+		}
+		b = pbAppendBytes(b, pbValueStruct, fields)
+	default:
+		return nil, fmt.Errorf("protobuf: unsupported type %T", v)
+	}
+	return b, nil
+}
+
+// pbAppendTag append field tag (field number and wire type)
+func pbAppendTag(b []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+// pbAppendBytes append length delimited field
+func pbAppendBytes(b []byte, field int, data []byte) []byte {
+	b = pbAppendTag(b, field, pbBytes)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// pbField single decoded protocol buffers field
+type pbField struct {
+	num      int
+	wireType int
+	varint   uint64
+	data     []byte
+}
+
+// pbFields decode all fields of protocol buffers message
+func pbFields(data []byte) ([]pbField, error) {
+	fields := []pbField{}
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("protobuf: invalid field tag")
+		}
+		data = data[n:]
+
+		f := pbField{num: int(tag >> 3), wireType: int(tag & 7)}
+		switch f.wireType {
+		case pbVarint:
+			f.varint, n = binary.Uvarint(data)
+			if n <= 0 {
+				return nil, fmt.Errorf("protobuf: invalid varint")
+			}
+			data = data[n:]
+		case pbFixed64, pbFixed32:
+			size := 8
+			if f.wireType == pbFixed32 {
+				size = 4
+			}
+			if len(data) < size {
+				return nil, fmt.Errorf("protobuf: unexpected end of data")
+			}
+			f.data, data = data[:size], data[size:]
+		case pbBytes:
+			l, n := binary.Uvarint(data)
+			if n <= 0 || l > uint64(len(data)-n) {
+				return nil, fmt.Errorf("protobuf: invalid length delimited field")
+			}
+			data = data[n:]
+			f.data, data = data[:l], data[l:]
+		default:
+			return nil, fmt.Errorf("protobuf: unsupported wire type [%d]", f.wireType)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// pbDecodeValue decode google.protobuf.Value message into generic document value
+func pbDecodeValue(data []byte, depth int) (interface{}, error) {
+	if depth > maxCodecDepth {
+		return nil, errCodecDepth
+	}
+
+	fields, err := pbFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Value is a oneof message, last field wins
+	var v interface{}
+	for _, f := range fields {
+		switch {
+		case f.num == pbValueNull && f.wireType == pbVarint:
+			v = nil
+		case f.num == pbValueBool && f.wireType == pbVarint:
+			v = f.varint != 0
+		case f.num == pbValueNumber && f.wireType == pbFixed64:
+			v = math.Float64frombits(binary.LittleEndian.Uint64(f.data))
+		case f.num == pbValueString && f.wireType == pbBytes:
+			v = string(f.data)
+		case f.num == pbValueList && f.wireType == pbBytes:
+			items, err := pbFields(f.data)
+			if err != nil {
+				return nil, err
+			}
+			list := []interface{}{}
+			for _, i := range items {
+				item, err := pbDecodeValue(i.data, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+			v = list
+		case f.num == pbValueStruct && f.wireType == pbBytes:
+			entries, err := pbFields(f.data)
+			if err != nil {
+				return nil, err
+			}
+			m := make(map[string]interface{}, len(entries))
+			for _, e := range entries {
+				kv, err := pbFields(e.data)
+				if err != nil {
+					return nil, err
+				}
+				var key string
+				var value interface{}
+				for _, i := range kv {
+					switch i.num {
+					case 1:
+						key = string(i.data)
+					case 2:
+						if value, err = pbDecodeValue(i.data, depth+1); err != nil {
+							return nil, err
+						}
+					}
+				}
+				m[key] = value
+			}
+			v = m
+		}
+	}
+	return v, nil
+}
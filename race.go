@@ -0,0 +1,91 @@
+package adstxt
+
+import (
+	"context"
+	"net/url"
+)
+
+// raceResult result of single raced Ads.txt fetch
+type raceResult struct {
+	index int
+	res   *Response
+	err   error
+}
+
+// raceGet fetch all request URL variants concurrently, first valid Ads.txt file wins and other fetches are cancelled.
+// When all fetches fail, error of the original request URL is returned
+func (c *Crawler) raceGet(req *Request) (*Response, error) {
+	variants := raceVariants(req, c.raceWWW)
+	if len(variants) < 2 {
+		return c.get(req)
+	}
+
+	parent := req.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	results := make(chan raceResult, len(variants))
+	for i, u := range variants {
+		// each fetch use copy of the request, since fetch updates request URL on redirects
+		r := *req
+		r.URL = u
+		r.ctx = ctx
+		go func(i int, r *Request) {
+			res, err := c.get(r)
+			results <- raceResult{index: i, res: res, err: err}
+		}(i, &r)
+	}
+
+	errs := make([]error, len(variants))
+	for range variants {
+		result := <-results
+		if result.err == nil {
+			cancel()
+			req.URL = result.res.Request.URL
+			result.res.Request = req
+			return result.res, nil
+		}
+		errs[result.index] = result.err
+	}
+
+	return nil, errs[0]
+}
+
+// raceVariants return request URL variants to race: both schemes, and www. or apex host variant when www is set
+// and the request host is the root domain (or its www. subdomain). Request URL is always the first variant
+func raceVariants(req *Request, www bool) []string {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return []string{req.URL}
+	}
+
+	hosts := []string{u.Host}
+	if www {
+		host, _ := ParseDomain(u.Hostname())
+		switch host {
+		case req.Domain:
+			hosts = append(hosts, "www."+u.Host)
+		case "www." + req.Domain:
+			hosts = append(hosts, u.Host[len("www."):])
+		}
+	}
+
+	schemes := []string{u.Scheme, "https"}
+	if u.Scheme == "https" {
+		schemes[1] = "http"
+	}
+
+	variants := []string{}
+	for _, h := range hosts {
+		for _, s := range schemes {
+			v := *u
+			v.Scheme = s
+			v.Host = h
+			variants = append(variants, v.String())
+		}
+	}
+	return variants
+}
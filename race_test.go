@@ -0,0 +1,70 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestRaceVariants test raced URL variants of request
+func TestRaceVariants(t *testing.T) {
+	requests := map[string][]string{
+		"http://example.com/ads.txt":      {"http://example.com/ads.txt", "https://example.com/ads.txt", "http://www.example.com/ads.txt", "https://www.example.com/ads.txt"},
+		"https://www.example.com/ads.txt": {"https://www.example.com/ads.txt", "http://www.example.com/ads.txt", "https://example.com/ads.txt", "http://example.com/ads.txt"},
+		"http://news.example.com/ads.txt": {"http://news.example.com/ads.txt", "https://news.example.com/ads.txt"},
+		"http://example.com:8080/ads.txt": {"http://example.com:8080/ads.txt", "https://example.com:8080/ads.txt", "http://www.example.com:8080/ads.txt", "https://www.example.com:8080/ads.txt"},
+	}
+
+	for rawurl, expected := range requests {
+		req, _ := NewRequest(rawurl)
+		variants := raceVariants(req, true)
+		if len(variants) != len(expected) {
+			t.Errorf("[%s] Expected variants %v and not %v", rawurl, expected, variants)
+			continue
+		}
+		for i := range variants {
+			if variants[i] != expected[i] {
+				t.Errorf("[%s] Expected variants %v and not %v", rawurl, expected, variants)
+				break
+			}
+		}
+	}
+}
+
+// TestSchemeRacing test first valid Ads.txt file wins and slower fetches are cancelled
+func TestSchemeRacing(t *testing.T) {
+	cancelled := make(chan struct{}, 4)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// apex host is slow, wait until fetch is cancelled
+		if r.Host == "example.com" {
+			select {
+			case <-r.Context().Done():
+				cancelled <- struct{}{}
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, nil)
+
+	req, _ := NewRequest("example.com")
+	res, err := NewCrawler(route, WithSchemeRacing(true)).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL != "http://www.example.com/ads.txt" || res.Request != req {
+		t.Errorf("Expected www variant to win and not [%s]", req.URL)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected slower fetch to be cancelled")
+	}
+}
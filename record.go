@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 // Ads.txt comment
@@ -26,22 +27,48 @@ const (
 	varTypeSubdomain = "subdomain"
 	// Contact information for the owner of the Ads.txt file
 	varTypeContact = "contact"
+	// Domain of an inventory partner (e.g. CTV app developer) whose Ads.txt file should also be consulted
+	varTypeInventoryPartnerDomain = "inventorypartnerdomain"
+	// Business domain of the owner of the Ads.txt file
+	varTypeOwnerDomain = "ownerdomain"
+	// Business domain of the primary or exclusive monetization partner of the publisher
+	varTypeManagerDomain = "managerdomain"
+)
+
+// Ads.txt record quality flags: record was parsed successfully, but consumers may want to weight it by trust
+const (
+	// FlagCrossDomainRedirect record was fetched via redirect outside of the request root domain
+	FlagCrossDomainRedirect = "cross-domain-redirect"
+	// FlagLenientContentType record was read from Ads.txt file with wrong content type, accepted in lenient mode
+	FlagLenientContentType = "lenient-content-type"
+	// FlagLenientRedirectPath record was fetched via redirect to non canonical ads.txt path in lenient mode
+	FlagLenientRedirectPath = "lenient-redirect-path"
+	// FlagNormalized record line needed normalization (letter case or whitespace) before it could be parsed
+	FlagNormalized = "normalized"
 )
 
 // DataRecord hold single Ads.txt data record
 type DataRecord struct {
-	AdverterDomain     string `json:"adverterdomain"`            // AdverterDomain Domain name of the advertising system (required)
-	PublisherAccountID string `json:"publisheraccountid"`        // PublisherAccountID the identifier associated with the seller (required)
-	AccountType        string `json:"accountype"`                // AccountType enumeration of the type of account: DIRECT or RESELLER (required)
-	CertAuthorityID    string `json:"certauthorityid,omitempty"` // CertAuthorityID An ID that uniquely identifies the advertising system within a certification authority (optional)
+	AdverterDomain     Domain   `json:"adverterdomain"`            // AdverterDomain Domain name of the advertising system (required)
+	PublisherAccountID string   `json:"publisheraccountid"`        // PublisherAccountID the identifier associated with the seller (required)
+	AccountType        string   `json:"accountype"`                // AccountType enumeration of the type of account: DIRECT or RESELLER (required)
+	CertAuthorityID    string   `json:"certauthorityid,omitempty"` // CertAuthorityID An ID that uniquely identifies the advertising system within a certification authority (optional)
+	Flags              []string `json:"flags,omitempty"`           // Flags record quality flags
+
+	OriginalAdverterDomain     string `json:"originaladverterdomain,omitempty"`     // OriginalAdverterDomain ad system domain as declared in the file, when normalized
+	OriginalPublisherAccountID string `json:"originalpublisheraccountid,omitempty"` // OriginalPublisherAccountID account ID as declared in the file, when trimmed in lenient mode
 }
 
 // Variable hold single of Ads.txt variable record
 type Variable struct {
-	Type  string `json:"type"`  // Type of variable record. Supported types are subdomain and contact
-	Value string `json:"value"` // Value of variable record
+	Type  string   `json:"type"`            // Type of variable record. Supported types are subdomain, contact, inventorypartnerdomain, ownerdomain and managerdomain
+	Value string   `json:"value"`           // Value of variable record
+	Flags []string `json:"flags,omitempty"` // Flags record quality flags
 }
 
+// certAuthorityIDPattern alphanumeric certification authority ID
+var certAuthorityIDPattern = regexp.MustCompile("^[a-zA-Z0-9]*$")
+
 // parseDataRecord return new DataRecord parsed from single Ads.txt line
 func parseDataRecord(line string) (*DataRecord, *Warning) {
 	// Data record declaraion: <FIELD #1>, <FIELD #2>, <FIELD #3>, <FIELD #4> (optional)
@@ -49,56 +76,84 @@ func parseDataRecord(line string) (*DataRecord, *Warning) {
 
 	filedsLen := len(fields)
 	if filedsLen < 3 || filedsLen > 4 {
-		return nil, &Warning{Level: HighSevirity, Message: fmt.Sprintf("Data record must be declared as <FIELD #1>, <FIELD #2>, <FIELD #3>, <FIELD #4> (optional) pattern")}
+		return nil, &Warning{Code: WarnInvalidFieldsCount, Level: HighSevirity, Message: fmt.Sprintf("Data record must be declared as <FIELD #1>, <FIELD #2>, <FIELD #3>, <FIELD #4> (optional) pattern")}
 	}
 
 	// make sure required fields are not empty
 	adverterDomain := strings.TrimSpace(fields[0])
 	if len(adverterDomain) == 0 {
-		return nil, &Warning{Level: HighSevirity, Message: fmt.Sprintf("Missing domain name of the advertising system (required)")}
+		return nil, &Warning{Code: WarnMissingAdSystemDomain, Level: HighSevirity, Message: fmt.Sprintf("Missing domain name of the advertising system (required)")}
+	}
+
+	if len(adverterDomain) > MaxDomainLength {
+		return nil, &Warning{Code: WarnFieldTooLong, Level: HighSevirity, Message: fmt.Sprintf(warnFieldTooLong, "Ad system domain", MaxDomainLength)}
 	}
 
 	if !validateDomainName(adverterDomain) {
-		return nil, &Warning{Level: HighSevirity, Message: fmt.Sprintf("%s is not a valid Ad system domain", adverterDomain)}
+		return nil, &Warning{Code: WarnInvalidAdSystemDomain, Level: HighSevirity, Message: fmt.Sprintf("%s is not a valid Ad system domain", adverterDomain)}
 	}
 
-	// check that advertiser domain is a valid DNS name
+	// valid domain is normalized (lower case, punycode), and www and mobile subdomain variants are normalized to the
+	// registrable domain (sellers.json uses registrable domains)
+	originalDomain := adverterDomain
+	if domain, err := ParseDomain(adverterDomain); err == nil {
+		adverterDomain = domain.String()
+	}
+	adverterDomain = canonicalAdSystemDomain(adverterDomain)
+
+	// check that advertiser domain is a valid DNS name (either normalized or original form is a known ad system)
 	err := vaidateAdSystemCName(adverterDomain)
+	if err != nil && adverterDomain != originalDomain && vaidateAdSystemCName(originalDomain) == nil {
+		err = nil
+	}
 	if err != nil {
-		return nil, &Warning{Level: LowSevirity, Message: err.Error()}
+		return nil, &Warning{Code: WarnUnverifiedAdSystemDomain, Level: LowSevirity, Message: err.Error()}
 	}
 
 	publisherAccountID := strings.TrimSpace(fields[1])
 	if len(publisherAccountID) == 0 {
-		return nil, &Warning{Level: HighSevirity, Message: fmt.Sprintf("Missing publisher's Account ID (required)")}
+		return nil, &Warning{Code: WarnMissingAccountID, Level: HighSevirity, Message: fmt.Sprintf("Missing publisher's Account ID (required)")}
+	}
+	if len(publisherAccountID) > MaxAccountIDLength || (filedsLen > 3 && len(strings.TrimSpace(fields[3])) > MaxAccountIDLength) {
+		return nil, &Warning{Code: WarnFieldTooLong, Level: HighSevirity, Message: fmt.Sprintf(warnFieldTooLong, "Account ID", MaxAccountIDLength)}
 	}
 
 	accountType := strings.TrimSpace(fields[2])
 	if len(accountType) == 0 {
-		return nil, &Warning{Level: HighSevirity, Message: fmt.Sprintf("Missing type of account/relationship (required)")}
+		return nil, &Warning{Code: WarnMissingAccountType, Level: HighSevirity, Message: fmt.Sprintf("Missing type of account/relationship (required)")}
 	}
 
 	// make sure account type is suppoted (case insensitive)
 	if strings.ToUpper(accountType) != accountTypeReseller && strings.ToUpper(accountType) != accountTypeDirect {
-		return nil, &Warning{Level: HighSevirity, Message: fmt.Sprintf("[%s] is not a valid account type. Account type must be [%s] or [%s]",
+		return nil, &Warning{Code: WarnInvalidAccountType, Level: HighSevirity, Message: fmt.Sprintf("[%s] is not a valid account type. Account type must be [%s] or [%s]",
 			accountType, accountTypeDirect, accountTypeReseller)}
 	}
 
 	r := DataRecord{
-		AdverterDomain:     adverterDomain,
+		AdverterDomain:     Domain(adverterDomain),
 		PublisherAccountID: publisherAccountID,
 		AccountType:        strings.ToUpper(accountType),
 	}
 
+	// original ad system domain is preserved when normalized
+	if adverterDomain != originalDomain {
+		r.OriginalAdverterDomain = originalDomain
+	}
+
+	// account type letter case, ad system domain or whitespace other than plain spaces around fields required normalization
+	if accountType != r.AccountType || adverterDomain != originalDomain || needNormalization(fields) {
+		r.addFlag(FlagNormalized)
+	}
+
 	// optional value
 	if filedsLen > 3 {
 		certAuthorityID := strings.TrimSpace(fields[3])
 		r.CertAuthorityID = certAuthorityID
 
 		// check if cert authority id is alphanumeric (if not, it might indicate an error also it is not part of Ads.txt specification)
-		re := regexp.MustCompile("^[a-zA-Z0-9]*$")
-		if !re.MatchString(r.CertAuthorityID) {
+		if !certAuthorityIDPattern.MatchString(r.CertAuthorityID) {
 			return &r, &Warning{
+				Code:    WarnInvalidCertAuthorityID,
 				Level:   LowSevirity,
 				Message: fmt.Sprintf("Certification Authority ID %s may not be correct as it is not alphanumeric", r.CertAuthorityID),
 			}
@@ -115,20 +170,127 @@ func parseVarialbe(line string) (*Variable, *Warning) {
 
 	// check that record type is supported, and return new varialbe of that type
 	t := fields[0]
+
+	var v *Variable
 	switch strings.ToLower(t) {
-	case varTypeSubdomain:
-		return &Variable{
-			Type:  varTypeSubdomain,
-			Value: fields[1],
-		}, nil
-	case varTypeContact:
-		return &Variable{
-			Type:  varTypeContact,
-			Value: fields[1],
-		}, nil
+	case varTypeSubdomain, varTypeContact, varTypeInventoryPartnerDomain, varTypeOwnerDomain, varTypeManagerDomain:
+		v = &Variable{
+			Type:  strings.ToLower(t),
+			Value: strings.TrimSpace(fields[1]),
+		}
 	default:
-		return nil, &Warning{Level: HighSevirity, Message: fmt.Sprintf("[%s] is not a valid Variable type", t)}
+		return nil, &Warning{Code: WarnInvalidVariableType, Level: HighSevirity, Message: fmt.Sprintf("[%s] is not a valid Variable type", t)}
+	}
+
+	if len(v.Value) > MaxVariableValueLength {
+		return nil, &Warning{Code: WarnFieldTooLong, Level: HighSevirity, Message: fmt.Sprintf(warnFieldTooLong, "Variable value", MaxVariableValueLength)}
+	}
+
+	// variable type is case insensitive, but expected to be declared in lower case
+	if t != v.Type {
+		v.addFlag(FlagNormalized)
+	}
+
+	return v, nil
+}
+
+// malformedVariablePattern variable declared with wrong separator: colon, whitespace or whitespace around "="
+var malformedVariablePattern = regexp.MustCompile(`(?i)^(subdomain|contact|inventorypartnerdomain|ownerdomain|managerdomain)(\s*[:=]\s*|\s+)(\S.*)$`)
+
+// malformedVariable return warning with corrected declaration if line is variable declared with wrong separator
+// (e.g. "subdomain: news.example.com" or "contact = ads@example.com"), nil otherwise
+func malformedVariable(line string) *Warning {
+	m := malformedVariablePattern.FindStringSubmatch(line)
+	if m == nil || m[2] == "=" {
+		return nil
+	}
+
+	return &Warning{
+		Code:       WarnMalformedVariable,
+		Level:      HighSevirity,
+		Message:    fmt.Sprintf("[%s] variable is ignored, variables must be declared as <VARIABLE>=<VALUE>", m[1]),
+		Suggestion: m[1] + "=" + strings.TrimSpace(m[3]),
+	}
+}
+
+// addFlag add quality flag to data record (flag is added only once)
+func (r *DataRecord) addFlag(flag string) {
+	r.Flags = appendFlag(r.Flags, flag)
+}
+
+// addFlag add quality flag to variable record (flag is added only once)
+func (v *Variable) addFlag(flag string) {
+	v.Flags = appendFlag(v.Flags, flag)
+}
+
+// appendFlag append flag to flags collection unless it already exists
+func appendFlag(flags []string, flag string) []string {
+	for _, f := range flags {
+		if f == flag {
+			return flags
+		}
+	}
+	return append(flags, flag)
+}
+
+// needNormalization check if any of the fields is padded with whitespace other than plain spaces (tabs etc)
+func needNormalization(fields []string) bool {
+	for _, f := range fields {
+		if strings.TrimSpace(f) != strings.Trim(f, " ") {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalAdSystemDomain normalize www and mobile subdomain (m. or mobile.) variants of ad system domain to its
+// registrable domain. Other subdomains are kept as is, since some ad systems declare a subdomain as canonical
+func canonicalAdSystemDomain(domain string) string {
+	lcDomain := strings.ToLower(domain)
+	for _, prefix := range []string{"www.", "m.", "mobile."} {
+		if !strings.HasPrefix(lcDomain, prefix) {
+			continue
+		}
+
+		stripped := lcDomain[len(prefix):]
+		if d, err := RootDomain(stripped); err == nil && d == stripped {
+			return stripped
+		}
+	}
+	return domain
+}
+
+// normalizeAccountID remove invisible characters from publisher account ID: zero-width and other format characters
+// anywhere in the ID, and surrounding whitespace (including non-breaking spaces hidden by those characters)
+func normalizeAccountID(id string) string {
+	id = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, id)
+	return strings.TrimSpace(id)
+}
+
+// diagnoseAccountID check publisher account ID field as declared (before trimming) for invisible characters, which
+// break exact match joins. Plain spaces around the field are allowed, no suggestion is offered when removing the
+// invisible characters doesn't fix the ID (e.g. internal space)
+func diagnoseAccountID(field string) *Warning {
+	id := strings.Trim(field, " ")
+	normalized := normalizeAccountID(id)
+	if normalized == id && strings.IndexFunc(id, unicode.IsSpace) == -1 {
+		return nil
+	}
+
+	w := &Warning{
+		Code:    WarnAccountIDInvisibleChars,
+		Level:   LowSevirity,
+		Message: fmt.Sprintf("Publisher account ID %q contains invisible or whitespace characters", id),
+	}
+	if normalized != id {
+		w.Suggestion = normalized
 	}
+	return w
 }
 
 // removeComment removes any comment from Ads.txt line before parsing
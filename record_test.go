@@ -293,3 +293,150 @@ func TestRemoveComment(t *testing.T) {
 	}
 
 }
+
+// TestParseRecordNormalizedFlag test records which needed normalization are flagged
+func TestParseRecordNormalizedFlag(t *testing.T) {
+	lines := map[string]bool{
+		"greenadexchange.com, XF7342, DIRECT":  false,
+		"greenadexchange.com, XF7342, direct":  true,
+		"greenadexchange.com,\tXF7342, DIRECT": true,
+	}
+
+	for line, flagged := range lines {
+		r, w := parseDataRecord(line)
+		if w != nil {
+			t.Errorf("Expected no parse warning when parsing [%s] [%v]", line, w)
+			continue
+		}
+		if (len(r.Flags) == 1 && r.Flags[0] == FlagNormalized) != flagged {
+			t.Errorf("Expected normalized flag for [%s] to be [%t] but found %v", line, flagged, r.Flags)
+		}
+	}
+
+	v, _ := parseVarialbe("SUBDOMAIN=dev.example.com")
+	if len(v.Flags) != 1 || v.Flags[0] != FlagNormalized {
+		t.Errorf("Expected upper case variable type to be flagged as normalized but found %v", v.Flags)
+	}
+}
+
+// TestCanonicalAdSystemDomain test www and mobile variants of ad system domain are normalized to registrable domain
+func TestCanonicalAdSystemDomain(t *testing.T) {
+	domains := map[string]string{
+		"greenadexchange.com":          "greenadexchange.com",
+		"www.greenadexchange.com":      "greenadexchange.com",
+		"WWW.GreenAdExchange.com":      "greenadexchange.com",
+		"m.greenadexchange.com":        "greenadexchange.com",
+		"mobile.greenadexchange.co.uk": "greenadexchange.co.uk",
+		"ads.greenadexchange.com":      "ads.greenadexchange.com",
+		"www.ads.greenadexchange.com":  "www.ads.greenadexchange.com",
+	}
+
+	for k, v := range domains {
+		if d := canonicalAdSystemDomain(k); d != v {
+			t.Errorf("Expected [%s] canonical ad system domain to be [%s] and not [%s]", k, v, d)
+		}
+	}
+
+	line := "www.greenadexchange.com, XF7342, DIRECT"
+	r, w := parseDataRecord(line)
+	if w != nil {
+		t.Fatalf("Expected no parse warning when parsing [%s] [%v]", line, w)
+	}
+	if r.AdverterDomain != "greenadexchange.com" || r.OriginalAdverterDomain != "www.greenadexchange.com" {
+		t.Errorf("Expected [%s] ad system domain to be normalized with original domain preserved", line)
+	}
+}
+
+// TestParseSpecVariables test parsing inventorypartnerdomain, ownerdomain and managerdomain Variable types
+func TestParseSpecVariables(t *testing.T) {
+	variables := map[string]string{
+		"inventorypartnerdomain=partner.com": varTypeInventoryPartnerDomain,
+		"OWNERDOMAIN=example.com":            varTypeOwnerDomain,
+		"managerdomain=manager.com":          varTypeManagerDomain,
+	}
+
+	for line, expected := range variables {
+		v, w := parseVarialbe(line)
+		if w != nil {
+			t.Errorf("Expected no errors when parsing [%s] [%v]", line, w)
+			continue
+		}
+		if v.Type != expected {
+			t.Errorf("Expected variable type for [%s] to be [%s] but recieved [%s]", line, expected, v.Type)
+		}
+	}
+}
+
+// TestDiagnoseAccountID test publisher account IDs with invisible characters are reported with normalized value
+func TestDiagnoseAccountID(t *testing.T) {
+	// expected suggestion of reported IDs, nil when not reported
+	suggestion := func(s string) *string { return &s }
+	ids := map[string]*string{
+		"pub-123":              nil,
+		" pub-123 ":            nil,
+		"pub-123\u200b":        suggestion("pub-123"),
+		"\ufeffpub-123":        suggestion("pub-123"),
+		"pub-123 \u2060":       suggestion("pub-123"),
+		"pub-\u200d123":        suggestion("pub-123"),
+		"pub-123\u00a0 \u200c": suggestion("pub-123"),
+		" pub-123\t":           suggestion("pub-123"),
+		" pub-123\u00a0":       suggestion("pub-123"),
+		"pub 123":              suggestion(""),
+	}
+
+	for id, expected := range ids {
+		w := diagnoseAccountID(id)
+		if expected == nil && w != nil {
+			t.Errorf("[%q] Expected no warning and not [%s]", id, w.Message)
+		}
+		if expected != nil && (w == nil || w.Code != WarnAccountIDInvisibleChars || w.Suggestion != *expected) {
+			t.Errorf("[%q] Expected warning with suggestion [%s] and not %v", id, *expected, w)
+		}
+	}
+
+	// trailing whitespace of the declared field is reported, even though it is trimmed from the parsed ID
+	rec, err := ParseBody([]byte("google.com, pub-123\t, DIRECT\ngoogle.com, pub-456\u00a0, RESELLER"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Warnings) != 2 || rec.Warnings[0].Suggestion != "pub-123" || rec.Warnings[1].Suggestion != "pub-456" {
+		t.Errorf("Expected trailing whitespace of both account IDs to be reported and not %v", rec.Warnings)
+	}
+}
+
+// TestMalformedVariable test variables declared with wrong separator are reported with corrected declaration
+func TestMalformedVariable(t *testing.T) {
+	lines := map[string]string{
+		"subdomain: news.example.com":          "subdomain=news.example.com",
+		"contact = ads@example.com # comment":  "contact=ads@example.com",
+		"OWNERDOMAIN example.com":              "OWNERDOMAIN=example.com",
+		"contact :https://example.com/?id=ads": "contact=https://example.com/?id=ads",
+		"managerdomain =manager.com":           "managerdomain=manager.com",
+		"subdomain=news.example.com":           "",
+		"subdomains: news.example.com":         "",
+	}
+
+	for line, expected := range lines {
+		rec, err := ParseBody([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(expected) == 0 {
+			for _, w := range rec.Warnings {
+				if w.Code == WarnMalformedVariable {
+					t.Errorf("Expected [%s] not to be reported as malformed variable [%s]", line, w.Suggestion)
+				}
+			}
+			continue
+		}
+
+		if len(rec.Warnings) != 1 || rec.Warnings[0].Code != WarnMalformedVariable || rec.Warnings[0].Suggestion != expected {
+			t.Errorf("Expected [%s] to be reported as malformed variable with suggestion [%s] and not [%+v]", line, expected, rec.Warnings)
+			continue
+		}
+		if len(rec.Variables) != 0 || len(rec.Skipped) != 1 || rec.Skipped[0].Code != WarnMalformedVariable {
+			t.Errorf("Expected malformed variable [%s] to be skipped", line)
+		}
+	}
+}
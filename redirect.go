@@ -0,0 +1,138 @@
+package adstxt
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Redirect policy decisions
+const (
+	// RedirectFollowed redirect is within Ads.txt specification redirect policy and was followed
+	RedirectFollowed = "followed"
+	// RedirectFollowedLenient redirect to non canonical ads.txt path was followed in lenient mode
+	RedirectFollowedLenient = "followed-lenient"
+	// RedirectSchemeUpgrade trivial HTTP to HTTPS upgrade of the same URL, followed without counting against redirect policy
+	RedirectSchemeUpgrade = "scheme-upgrade"
+	// RedirectRejected redirect violates Ads.txt specification redirect policy, and crawl failed
+	RedirectRejected = "rejected"
+)
+
+// Treatment of redirects to another well-known file (e.g. ads.txt request redirected to app-ads.txt, by platforms
+// serving both files from a single endpoint)
+const (
+	// WellKnownFollow follow redirect to well-known file
+	WellKnownFollow = "follow"
+	// WellKnownWarn follow redirect to well-known file with warning (default)
+	WellKnownWarn = "warn"
+	// WellKnownReject reject redirect to well-known file
+	WellKnownReject = "reject"
+)
+
+// wellKnownFiles file names of well-known files, other than the requested one, that Ads.txt request may be
+// redirected to. Any path under /.well-known/ (RFC 8615) is a well-known file as well
+var wellKnownFiles = map[string]bool{
+	FileAdsTxt:     true,
+	FileAppAdsTxt:  true,
+	"sellers.json": true,
+	"robots.txt":   true,
+	"security.txt": true,
+	"humans.txt":   true,
+}
+
+// redirectWellKnown return file name of redirect destination when it is a well-known file other than the
+// requested one, empty string otherwise
+func redirectWellKnown(from string, to string) string {
+	f, err := url.Parse(from)
+	if err != nil {
+		return ""
+	}
+	t, err := url.Parse(to)
+	if err != nil {
+		return ""
+	}
+
+	file := strings.ToLower(path.Base(strings.TrimSuffix(t.Path, "/")))
+	if file == strings.ToLower(path.Base(f.Path)) {
+		return ""
+	}
+	if wellKnownFiles[file] || strings.HasPrefix(t.Path, "/.well-known/") {
+		return file
+	}
+	return ""
+}
+
+// RedirectEvent single HTTP redirect handled while fetching Ads.txt file, and the redirect policy decision
+type RedirectEvent struct {
+	Status      int    `json:"status"`           // Status HTTP response status code
+	From        string `json:"from"`             // From URL of the redirected request
+	To          string `json:"to,omitempty"`     // To redirect destination URL (resolved against From), empty when it can't be resolved
+	CrossDomain bool   `json:"crossDomain"`      // CrossDomain redirect destination is outside of request root domain
+	Decision    string `json:"decision"`         // Decision redirect policy decision: followed, followed-lenient, scheme-upgrade or rejected
+	Reason      string `json:"reason,omitempty"` // Reason of rejected redirect
+}
+
+// RedirectError is returned when a redirect is rejected by the redirect policy. It holds all redirects handled
+// during the crawl, the last one is the rejected redirect
+type RedirectError struct {
+	Redirects []*RedirectEvent
+	Err       error
+}
+
+func (e *RedirectError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RedirectError) Unwrap() error {
+	return e.Err
+}
+
+// newRedirectEvent create redirect event of redirect response. Decision is set by handleRedirect result
+func newRedirectEvent(req *Request, res *http.Response, redirect string, warnings []*Warning, err error) *RedirectEvent {
+	e := &RedirectEvent{Status: res.StatusCode, From: req.URL, To: redirect, Decision: RedirectFollowed}
+
+	if len(redirect) == 0 {
+		if location, lerr := resolveRedirect(req.URL, res.Header.Get("Location")); lerr == nil {
+			redirect = location
+		}
+		e.To = redirect
+	}
+	if d, err := RootDomain(redirect); err == nil {
+		e.CrossDomain = Domain(d) != req.Domain
+	}
+
+	if err != nil {
+		e.Decision = RedirectRejected
+		e.Reason = err.Error()
+		return e
+	}
+
+	if isSchemeUpgrade(req.URL, redirect) {
+		e.Decision = RedirectSchemeUpgrade
+	}
+	for _, w := range warnings {
+		if w.Code == WarnRedirectPathVariant && req.Lenient {
+			e.Decision = RedirectFollowedLenient
+		}
+	}
+	return e
+}
+
+// isSchemeUpgrade check if redirect is a trivial upgrade of HTTP URL to HTTPS: same host, default ports, path
+// and query
+func isSchemeUpgrade(from string, to string) bool {
+	f, err := url.Parse(from)
+	if err != nil {
+		return false
+	}
+	t, err := url.Parse(to)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(f.Scheme, "http") && strings.EqualFold(t.Scheme, "https") &&
+		strings.EqualFold(f.Hostname(), t.Hostname()) &&
+		(f.Port() == "" || f.Port() == "80") && (t.Port() == "" || t.Port() == "443") &&
+		f.EscapedPath() == t.EscapedPath() && f.RawQuery == t.RawQuery
+}
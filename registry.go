@@ -0,0 +1,68 @@
+package adstxt
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// defaultSellersURL sellers.json default location on ad system domain (IAB Tech Lab sellers.json specification)
+const defaultSellersURL = "https://%s/sellers.json"
+
+// knownSellersURLs sellers.json files of ad systems which are not published at the default location
+var knownSellersURLs = map[string]string{
+	"google.com": "https://storage.googleapis.com/adx-rtb-dictionaries/sellers.json",
+}
+
+// SellersRegistry map ad system domains to their sellers.json URLs. Ad systems which are not registered are
+// resolved to the default location (https://<domain>/sellers.json). Registry is safe for concurrent use
+type SellersRegistry struct {
+	lock sync.RWMutex
+	urls map[string]string
+}
+
+// DefaultSellersRegistry registry used by crawlers unless set otherwise (see WithSellersRegistry)
+var DefaultSellersRegistry = NewSellersRegistry()
+
+// NewSellersRegistry create new registry of known sellers.json URLs
+func NewSellersRegistry() *SellersRegistry {
+	r := &SellersRegistry{urls: make(map[string]string, len(knownSellersURLs))}
+	for d, u := range knownSellersURLs {
+		r.urls[d] = u
+	}
+	return r
+}
+
+// Set register sellers.json URL of ad system, overriding any existing entry
+func (r *SellersRegistry) Set(adSystem string, rawurl string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.urls[registryKey(adSystem)] = rawurl
+}
+
+// Delete remove ad system entry, so it is resolved to the default location
+func (r *SellersRegistry) Delete(adSystem string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.urls, registryKey(adSystem))
+}
+
+// URL return sellers.json URL of ad system
+func (r *SellersRegistry) URL(adSystem string) string {
+	key := registryKey(adSystem)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if u, ok := r.urls[key]; ok {
+		return u
+	}
+	return fmt.Sprintf(defaultSellersURL, key)
+}
+
+// registryKey normalize ad system domain (case insensitive)
+func registryKey(adSystem string) string {
+	return strings.ToLower(strings.TrimSpace(adSystem))
+}
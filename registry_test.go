@@ -0,0 +1,62 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestSellersRegistry test ad systems are resolved to registered or default sellers.json URLs
+func TestSellersRegistry(t *testing.T) {
+	r := NewSellersRegistry()
+	r.Set("RedSSP.com", "https://cdn.redssp.com/v2/sellers.json")
+
+	expected := map[string]string{
+		"greenadexchange.com": "https://greenadexchange.com/sellers.json",
+		"Google.com":          "https://storage.googleapis.com/adx-rtb-dictionaries/sellers.json",
+		"redssp.com":          "https://cdn.redssp.com/v2/sellers.json",
+	}
+	for adSystem, u := range expected {
+		if actual := r.URL(adSystem); actual != u {
+			t.Errorf("[%s] Expected sellers.json URL [%s] and not [%s]", adSystem, u, actual)
+		}
+	}
+
+	r.Delete("redssp.com")
+	if u := r.URL("redssp.com"); u != "https://redssp.com/sellers.json" {
+		t.Errorf("Expected deleted ad system to be resolved to default location and not [%s]", u)
+	}
+	if u := DefaultSellersRegistry.URL("redssp.com"); u != "https://redssp.com/sellers.json" {
+		t.Errorf("Expected registry override not to affect default registry and not [%s]", u)
+	}
+}
+
+// TestGetSellersRegistry test crawler fetch sellers.json file from registered URL
+func TestGetSellersRegistry(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "cdn.redssp.com" || r.URL.Path != "/v2/sellers.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, testSellers)
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, ts.Client().Transport)
+
+	if _, err := NewCrawler(route).GetSellers("redssp.com"); err == nil {
+		t.Errorf("Expected sellers.json fetch from default location to fail")
+	}
+
+	registry := NewSellersRegistry()
+	registry.Set("redssp.com", "https://cdn.redssp.com/v2/sellers.json")
+	s, err := NewCrawler(route, WithSellersRegistry(registry)).GetSellers("redssp.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.AdSystem != "redssp.com" || len(s.Sellers) != 3 {
+		t.Errorf("Expected [3] redssp.com sellers and not [%s] [%d]", s.AdSystem, len(s.Sellers))
+	}
+}
@@ -0,0 +1,218 @@
+package adstxt
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// recorded HTTP exchange file extension. Each file holds the request URL in its first line, followed by the raw HTTP
+// request and the raw HTTP response
+const exchangeFileExt = ".http"
+
+// WithRecorder record raw HTTP exchanges (request and response) of the crawler into files in dir, so they can later
+// be replayed offline using WithReplay
+func WithRecorder(dir string) Option {
+	return func(c *Crawler) {
+		c.wrappers = append(c.wrappers, func(rt http.RoundTripper) http.RoundTripper {
+			return &recorder{dir: dir, next: rt}
+		})
+	}
+}
+
+// WithReplay replay HTTP exchanges previously recorded into dir using WithRecorder instead of sending requests
+// to remote hosts. Exchanges of the same URL are replayed in the order they were recorded
+func WithReplay(dir string) Option {
+	return func(c *Crawler) {
+		c.wrappers = append(c.wrappers, func(rt http.RoundTripper) http.RoundTripper {
+			return &replayer{dir: dir}
+		})
+	}
+}
+
+// recorder HTTP transport which record HTTP exchanges into files
+type recorder struct {
+	dir  string
+	next http.RoundTripper
+
+	lock sync.Mutex
+	once sync.Once
+	seq  int
+}
+
+// RoundTrip send request using next transport and record the exchange
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	dumpReq, res, dumpRes, err := dumpExchange(r.next, req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.lock.Lock()
+	r.once.Do(r.seed)
+	r.seq++
+	name := fmt.Sprintf("%06d-%s%s", r.seq, strings.Replace(req.URL.Host, ":", "_", -1), exchangeFileExt)
+	r.lock.Unlock()
+
+	exchange := append([]byte(req.URL.String()+"\n"), dumpReq...)
+	if err := ioutil.WriteFile(filepath.Join(r.dir, name), append(exchange, dumpRes...), 0644); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// seed continue the sequence of exchanges already recorded into dir (e.g. by previous run of the crawler), so they
+// are not overwritten. Caller must hold the recorder lock
+func (r *recorder) seed() {
+	files, _ := filepath.Glob(filepath.Join(r.dir, "*"+exchangeFileExt))
+	for _, file := range files {
+		var seq int
+		if _, err := fmt.Sscanf(filepath.Base(file), "%d-", &seq); err == nil && seq > r.seq {
+			r.seq = seq
+		}
+	}
+}
+
+// redactedHeaders request headers holding credentials (see WithBasicAuth and WithCookieJar), their values are not
+// written to recorded exchanges
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// redactedValue replace value of redacted header
+const redactedValue = "REDACTED"
+
+// dumpExchange send request using transport and return raw HTTP request (credential headers are redacted) and
+// response (including body, up to MaxFileSize+1 bytes). Response body is replaced with in memory copy, so it can
+// still be read by the caller
+func dumpExchange(rt http.RoundTripper, req *http.Request) ([]byte, *http.Response, []byte, error) {
+	// crawler requests have no body
+	redacted := req.Clone(req.Context())
+	for _, h := range redactedHeaders {
+		if _, ok := redacted.Header[h]; ok {
+			redacted.Header.Set(h, redactedValue)
+		}
+	}
+	dumpReq, err := httputil.DumpRequestOut(redacted, false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	res, err := rt.RoundTrip(req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// read body within the crawler file size limit, larger body is cut and then rejected by the caller
+	body, err := ioutil.ReadAll(limitBody(res.Body))
+	res.Body.Close()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(body) > MaxFileSize {
+		res.ContentLength = int64(len(body))
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	dumpRes, err := httputil.DumpResponse(res, true)
+	if err != nil {
+		res.Body.Close()
+		return nil, nil, nil, err
+	}
+
+	return dumpReq, res, dumpRes, nil
+}
+
+// replayer HTTP transport which replay recorded HTTP exchanges
+type replayer struct {
+	dir string
+
+	once      sync.Once
+	err       error
+	lock      sync.Mutex
+	exchanges map[string][]string // recorded exchange files of each request URL
+}
+
+// RoundTrip return the next recorded response of the request URL
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.once.Do(r.load)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	url := req.URL.String()
+
+	r.lock.Lock()
+	files := r.exchanges[url]
+	if len(files) == 0 {
+		r.lock.Unlock()
+		return nil, fmt.Errorf("No recorded response for [%s] in [%s]", url, r.dir)
+	}
+	r.exchanges[url] = files[1:]
+	r.lock.Unlock()
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := bufio.NewReader(f)
+	if _, err := b.ReadString('\n'); err != nil {
+		return nil, err
+	}
+	if _, err := http.ReadRequest(b); err != nil {
+		return nil, err
+	}
+
+	res, err := http.ReadResponse(b, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// read body in memory so the exchange file can be closed
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+
+	return res, nil
+}
+
+// load index recorded exchange files by request URL
+func (r *replayer) load() {
+	files, err := filepath.Glob(filepath.Join(r.dir, "*"+exchangeFileExt))
+	if err != nil {
+		r.err = err
+		return
+	}
+	sort.Strings(files)
+
+	r.exchanges = make(map[string][]string)
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			r.err = err
+			return
+		}
+
+		url, err := bufio.NewReader(f).ReadString('\n')
+		f.Close()
+		if err != nil {
+			r.err = fmt.Errorf("Failed to read recorded exchange [%s] [%s]", name, err.Error())
+			return
+		}
+
+		url = strings.TrimSpace(url)
+		r.exchanges[url] = append(r.exchanges[url], name)
+	}
+}
@@ -0,0 +1,141 @@
+package adstxt
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestRecordAndReplay test recording HTTP exchanges and replaying them offline
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "adstxt-replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sub/ads.txt" {
+			w.Header().Set("Location", "/sub/ads.txt")
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT\nsubdomain=test.com")
+	}))
+
+	req, _ := NewRequest(ts.URL)
+	if _, err := NewCrawler(WithRecorder(dir)).Get(req); err != nil {
+		t.Fatal(err)
+	}
+
+	// replay after remote host is gone
+	ts.Close()
+
+	c := NewCrawler(WithReplay(dir))
+
+	req, _ = NewRequest(ts.URL)
+	res, err := c.Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.DataRecords) != 1 || len(res.Variables) != 1 {
+		t.Errorf("Expected replayed response to include single data record and variable")
+	}
+	if res.Request.URL != ts.URL+"/sub/ads.txt" {
+		t.Errorf("Expected replayed redirect to [%s] and not [%s]", ts.URL+"/sub/ads.txt", res.Request.URL)
+	}
+
+	// each recorded exchange is replayed once
+	req, _ = NewRequest(ts.URL)
+	if _, err := c.Get(req); err == nil {
+		t.Error("Expected error when recorded exchanges were already replayed")
+	}
+}
+
+// TestRecorderResume test exchanges recorded by new recorder into the same dir don't overwrite recorded ones
+func TestRecorderResume(t *testing.T) {
+	dir := t.TempDir()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	for i := 0; i < 2; i++ {
+		req, _ := NewRequest(ts.URL)
+		if _, err := NewCrawler(WithRecorder(dir)).Get(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+exchangeFileExt))
+	if len(files) != 2 {
+		t.Fatalf("Expected [2] recorded exchanges and not [%d]", len(files))
+	}
+
+	c := NewCrawler(WithReplay(dir))
+	for i := 0; i < 2; i++ {
+		req, _ := NewRequest(ts.URL)
+		if _, err := c.Get(req); err != nil {
+			t.Errorf("[%d] Expected exchange to be replayed [%s]", i, err.Error())
+		}
+	}
+}
+
+// TestRecorderLimits test recorded exchanges don't include credentials, and response body is cut at the crawler
+// file size limit
+func TestRecorderLimits(t *testing.T) {
+	dir := t.TempDir()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Query().Get("size") == "large" {
+			io.WriteString(w, strings.Repeat("#", MaxFileSize+10))
+			return
+		}
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	c := NewCrawler(WithRecorder(dir))
+	req, _ := NewRequest(ts.URL, WithBearerToken("secret-token"))
+	if _, err := c.Get(req); err != nil {
+		t.Fatal(err)
+	}
+	req, _ = NewRequest(ts.URL)
+	req.URL += "?size=large"
+	if _, err := c.Get(req); err == nil {
+		t.Errorf("Expected recorded response larger than [%d] bytes to fail", MaxFileSize)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+exchangeFileExt))
+	if len(files) != 2 {
+		t.Fatalf("Expected [2] recorded exchanges and not [%d]", len(files))
+	}
+	sort.Strings(files)
+
+	b, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret-token") || !strings.Contains(string(b), "Authorization: "+redactedValue) {
+		t.Errorf("Expected recorded Authorization header to be redacted:\n%s", b)
+	}
+
+	info, err := os.Stat(files[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > MaxFileSize+1024 {
+		t.Errorf("Expected recorded response body to be cut at [%d] bytes and not [%d]", MaxFileSize+1, info.Size())
+	}
+}
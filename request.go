@@ -1,42 +1,194 @@
 package adstxt
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
 // Request to fetch Ads.txt file from remote host
 type Request struct {
-	Domain string `json:"domain"` // Domain holds the root domain of the remote host
-	URL    string `json:"url"`    // URL of the Ads.txt file to fetch
+	Domain  Domain              `json:"domain"`           // Domain holds the root domain of the remote host
+	URL     string              `json:"url"`              // URL of the Ads.txt file to fetch
+	Lenient bool                `json:"-"`                // Lenient accept Ads.txt files that would otherwise be rejected (records are flagged accordingly)
+	IPs     map[string][]net.IP `json:"-"`                // IPs pre-resolved IP addresses by host name, dialed directly instead of resolving the host
+	Auth    *Credentials        `json:"-"`                // Auth credentials sent to request root domain hosts (see WithBasicAuth, WithBearerToken)
+	Tenant  string              `json:"tenant,omitempty"` // Tenant on behalf of which Ads.txt file is crawled, partitions caches and stores (see Tenants)
+
+	ctx    context.Context // request context, cancel in-flight HTTP requests when done (nil for background context)
+	local  bool            // request of local Ads.txt file (file: or data: URL), served without HTTP
+	path   bool            // request file path was set explicitly (see WithPath), crawler default path is not applied
+	accept string          // Accept header of the request, text/plain when empty (e.g. application/json for sellers.json)
+
+	baseline *Snapshot     // latest stored snapshot of request domain on differential crawl (see WithDifferential)
+	resume   *rangeRequest // resumption of interrupted download (see WithRangeResume)
 }
 
-// NewRequest create new Ads.txt file request from remote host
-func NewRequest(rawurl string) (*Request, error) {
-	u, err := url.Parse(rawurl)
+// requestConfig NewRequest settings
+type requestConfig struct {
+	scheme string
+	port   string
+	tenant string
+	auth   *Credentials
+	local  bool
+	path   string
+}
+
+// Credentials of access-controlled Ads.txt file (e.g. staged file on pre-production host). Either Basic (username
+// and password) or Bearer (token) credentials are sent
+type Credentials struct {
+	Username string // Username of Basic authentication
+	Password string // Password of Basic authentication
+	Token    string // Token of Bearer authentication (wins over Basic credentials)
+}
+
+// apply set credentials Authorization header on HTTP request
+func (c *Credentials) apply(r *http.Request) {
+	if len(c.Token) > 0 {
+		r.Header.Set("Authorization", "Bearer "+c.Token)
+		return
+	}
+	r.SetBasicAuth(c.Username, c.Password)
+}
+
+// RequestOption configure NewRequest
+type RequestOption func(*requestConfig)
+
+// WithDefaultScheme set scheme of Ads.txt URLs declared without one (http by default)
+func WithDefaultScheme(scheme string) RequestOption {
+	return func(c *requestConfig) {
+		c.scheme = strings.ToLower(scheme)
+	}
+}
+
+// WithDefaultPort set port of Ads.txt URLs declared without one (e.g. staging environments running on alternate port)
+func WithDefaultPort(port int) RequestOption {
+	return func(c *requestConfig) {
+		c.port = strconv.Itoa(port)
+	}
+}
+
+// WithPath fetch file from path instead of /ads.txt (e.g. /.well-known/ads.txt or internal mirror location). File
+// is validated as usual, and overrides crawler default path (see WithDefaultPath)
+func WithPath(path string) RequestOption {
+	return func(c *requestConfig) {
+		c.path = "/" + strings.TrimPrefix(path, "/")
+	}
+}
+
+// WithTenant tag request with tenant on behalf of which Ads.txt file is crawled
+func WithTenant(tenant string) RequestOption {
+	return func(c *requestConfig) {
+		c.tenant = tenant
+	}
+}
+
+// WithBasicAuth send Basic authentication credentials with the request
+func WithBasicAuth(username string, password string) RequestOption {
+	return func(c *requestConfig) {
+		c.auth = &Credentials{Username: username, Password: password}
+	}
+}
+
+// WithBearerToken send Bearer authentication token with the request
+func WithBearerToken(token string) RequestOption {
+	return func(c *requestConfig) {
+		c.auth = &Credentials{Token: token}
+	}
+}
+
+// NewRequest create new Ads.txt file request from remote host. Host may include non standard port
+// (publisher.example.com:8443), which is preserved through redirects
+func NewRequest(rawurl string, opts ...RequestOption) (*Request, error) {
+	cfg := newRequestConfig(opts)
+	if cfg.local && isLocalURL(rawurl) {
+		return newLocalRequest(rawurl, cfg)
+	}
+
+	adsTxtURL, err := fileURL(rawurl, FileAdsTxt, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publishers should post the "/ads.txt" file on their root domain and any subdomains as needed.
+	// Root domain is defined as the “public suffix” plus one string in the name
+	d, err := RootDomain(adsTxtURL)
 	if err != nil {
 		return nil, err
 	}
 
+	return &Request{URL: adsTxtURL, Domain: Domain(d), Tenant: cfg.tenant, Auth: cfg.auth, path: len(cfg.path) > 0}, nil
+}
+
+// AdsTxtURL build Ads.txt file URL of host exactly as NewRequest does: scheme is added when missing (see
+// WithDefaultScheme and WithDefaultPort) and "/ads.txt" is appended to the path
+func AdsTxtURL(rawurl string, opts ...RequestOption) (string, error) {
+	return fileURL(rawurl, FileAdsTxt, newRequestConfig(opts))
+}
+
+// AppAdsTxtURL build app-ads.txt file URL of host, the same way as AdsTxtURL ("/ads.txt" path is replaced)
+func AppAdsTxtURL(rawurl string, opts ...RequestOption) (string, error) {
+	return fileURL(rawurl, FileAppAdsTxt, newRequestConfig(opts))
+}
+
+// defaultPath set crawler default file path on request URL, unless request path was set explicitly
+func (r *Request) defaultPath(path string) {
+	if r.path || r.local {
+		return
+	}
+	if u, err := url.Parse(r.URL); err == nil {
+		u.Path = path
+		r.URL = u.String()
+		r.path = true
+	}
+}
+
+// newRequestConfig create request settings from options
+func newRequestConfig(opts []RequestOption) *requestConfig {
 	// add scheme to Ads.txt URL if it's missing (by default we will add http and not https since it seems more common. If the site is
 	// running using HTTPS, we will usually get an HTTP redirect response and will handle it)
-	if u.Scheme == "" {
-		u.Scheme = "http"
+	cfg := &requestConfig{scheme: "http"}
+	for _, opt := range opts {
+		opt(cfg)
 	}
+	return cfg
+}
 
-	// add "/ads.txt" to URL path
-	if !strings.HasSuffix(u.Path, "/ads.txt") {
-		u.Path = fmt.Sprintf("%s/ads.txt", strings.TrimSuffix(u.Path, "/"))
+// fileURL build URL of well-known file (ads.txt or app-ads.txt) of host
+func fileURL(rawurl string, file string, cfg *requestConfig) (string, error) {
+	// scheme is added before parsing, else host with port (example.com:8443) is parsed as scheme
+	if !strings.Contains(rawurl, "://") {
+		rawurl = cfg.scheme + "://" + strings.TrimPrefix(rawurl, "//")
 	}
 
-	// Publishers should post the "/ads.txt" file on their root domain and any subdomains as needed.
-	// Root domain is defined as the “public suffix” plus one string in the name
-	d, err := rootDomain(rawurl)
+	u, err := url.Parse(rawurl)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+
+	// internationalized host is sent in punycode form, else it is percent-encoded in URL
+	if d, err := ParseDomain(u.Hostname()); err == nil && !isASCII(u.Hostname()) {
+		if port := u.Port(); len(port) > 0 {
+			u.Host = net.JoinHostPort(d.Punycode(), port)
+		} else {
+			u.Host = d.Punycode()
+		}
 	}
 
-	adsTxtURL := fmt.Sprintf("%v", u)
-	return &Request{URL: adsTxtURL, Domain: d}, nil
+	if len(cfg.port) > 0 && len(u.Port()) == 0 {
+		u.Host = net.JoinHostPort(u.Hostname(), cfg.port)
+	}
+
+	// custom file path replace the URL path, else "/ads.txt" (or "/app-ads.txt") is added to it
+	if len(cfg.path) > 0 {
+		u.Path = cfg.path
+	} else if !strings.HasSuffix(u.Path, "/"+file) {
+		path := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"+FileAdsTxt), "/")
+		u.Path = fmt.Sprintf("%s/%s", path, file)
+	}
+	return u.String(), nil
 }
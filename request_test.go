@@ -1,6 +1,9 @@
 package adstxt
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -29,3 +32,151 @@ func TestNewRequest(t *testing.T) {
 		}
 	}
 }
+
+// TestFileURL test Ads.txt and app-ads.txt URLs are built the same way as request URL
+func TestFileURL(t *testing.T) {
+	urls := map[string][2]string{
+		"example.com":                 {"http://example.com/ads.txt", "http://example.com/app-ads.txt"},
+		"https://www.example.com/":    {"https://www.example.com/ads.txt", "https://www.example.com/app-ads.txt"},
+		"example.com/ads.txt":         {"http://example.com/ads.txt", "http://example.com/app-ads.txt"},
+		"staging.example.com/path/":   {"http://staging.example.com/path/ads.txt", "http://staging.example.com/path/app-ads.txt"},
+		"publisher.example.com:8443/": {"http://publisher.example.com:8443/ads.txt", "http://publisher.example.com:8443/app-ads.txt"},
+		"https://Bücher.de:8443":      {"https://xn--bcher-kva.de:8443/ads.txt", "https://xn--bcher-kva.de:8443/app-ads.txt"},
+	}
+
+	for k, v := range urls {
+		u, err := AdsTxtURL(k)
+		if r, _ := NewRequest(k); err != nil || u != v[0] || u != r.URL {
+			t.Errorf("Expected Ads.txt URL for [%s] to be [%s] but recieved [%s] [%v]", k, v[0], u, err)
+		}
+		if u, err := AppAdsTxtURL(k); err != nil || u != v[1] {
+			t.Errorf("Expected app-ads.txt URL for [%s] to be [%s] but recieved [%s] [%v]", k, v[1], u, err)
+		}
+	}
+
+	if u, _ := AdsTxtURL("example.com", WithDefaultScheme("https"), WithDefaultPort(8443)); u != "https://example.com:8443/ads.txt" {
+		t.Errorf("Expected request options to apply to Ads.txt URL and not [%s]", u)
+	}
+}
+
+// TestNewRequestSchemeAndPort test non standard ports and default scheme\port options
+func TestNewRequestSchemeAndPort(t *testing.T) {
+	requests := map[string]struct {
+		opts []RequestOption
+		Request
+	}{
+		"publisher.example.com:8443":         {nil, Request{URL: "http://publisher.example.com:8443/ads.txt", Domain: "example.com"}},
+		"https://publisher.example.com:8443": {nil, Request{URL: "https://publisher.example.com:8443/ads.txt", Domain: "example.com"}},
+		"example.com:8080/path/":             {nil, Request{URL: "http://example.com:8080/path/ads.txt", Domain: "example.com"}},
+		"example.com":                        {[]RequestOption{WithDefaultScheme("HTTPS")}, Request{URL: "https://example.com/ads.txt", Domain: "example.com"}},
+		"http://example.com":                 {[]RequestOption{WithDefaultScheme("https")}, Request{URL: "http://example.com/ads.txt", Domain: "example.com"}},
+		"staging.example.com":                {[]RequestOption{WithDefaultPort(8443)}, Request{URL: "http://staging.example.com:8443/ads.txt", Domain: "example.com"}},
+		"staging.example.com:9000":           {[]RequestOption{WithDefaultPort(8443)}, Request{URL: "http://staging.example.com:9000/ads.txt", Domain: "example.com"}},
+	}
+
+	for k, v := range requests {
+		r, err := NewRequest(k, v.opts...)
+		if err != nil {
+			t.Errorf("[%s] %s", k, err)
+			continue
+		}
+		if r.URL != v.URL {
+			t.Errorf("Expected Ads.txt for [%s] to be [%s] but recieved [%s]", k, v.URL, r.URL)
+		}
+		if r.Domain != v.Domain {
+			t.Errorf("Expected Domain for [%s] to be [%s] but recieved [%s]", k, v.Domain, r.Domain)
+		}
+	}
+}
+
+// TestRequestAuth test credentials are sent to request root domain hosts only
+func TestRequestAuth(t *testing.T) {
+	seen := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen[r.Host] = r.Header.Get("Authorization")
+		switch r.Host {
+		case "staging.example.com":
+			http.Redirect(w, r, "http://cdn.other.com/ads.txt", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+		}
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, nil)
+
+	requests := map[string]string{
+		"example.com":     "Bearer secret",
+		"ads.example.com": "Basic dXNlcjpwYXNz",
+	}
+	opts := map[string]RequestOption{
+		"example.com":     WithBearerToken("secret"),
+		"ads.example.com": WithBasicAuth("user", "pass"),
+	}
+	for host, expected := range requests {
+		req, _ := NewRequest(host, opts[host])
+		if _, err := NewCrawler(route).Get(req); err != nil {
+			t.Fatalf("[%s] %s", host, err)
+		}
+		if seen[host] != expected {
+			t.Errorf("[%s] Expected Authorization header [%s] and not [%s]", host, expected, seen[host])
+		}
+	}
+
+	// credentials are not sent on redirect out of root domain
+	req, _ := NewRequest("staging.example.com", WithBearerToken("secret"))
+	if _, err := NewCrawler(route).Get(req); err != nil {
+		t.Fatal(err)
+	}
+	if seen["staging.example.com"] != "Bearer secret" || len(seen["cdn.other.com"]) > 0 {
+		t.Errorf("Expected credentials to be sent to staging host only and not %v", seen)
+	}
+}
+
+// TestRequestPath test files are fetched from custom path set on request or crawler-wide, request path wins
+func TestRequestPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/ads.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+		case "/mirror/ads":
+			http.Redirect(w, r, "/.well-known/ads.txt", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, nil)
+
+	requests := map[string]struct {
+		opts    []RequestOption
+		crawler []Option
+		url     string
+	}{
+		"request":          {[]RequestOption{WithPath(".well-known/ads.txt")}, nil, "http://example.com/.well-known/ads.txt"},
+		"crawler":          {nil, []Option{WithDefaultPath("/.well-known/ads.txt")}, "http://example.com/.well-known/ads.txt"},
+		"request-wins":     {[]RequestOption{WithPath("/mirror/ads")}, []Option{WithDefaultPath("/missing.txt")}, "http://example.com/.well-known/ads.txt"},
+		"default-path-404": {nil, nil, ""},
+	}
+
+	for name, v := range requests {
+		req, err := NewRequest("example.com", v.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := NewCrawler(append(v.crawler, route)...).Get(req)
+		if len(v.url) == 0 {
+			if err == nil {
+				t.Errorf("[%s] Expected /ads.txt to be fetched and not found", name)
+			}
+			continue
+		}
+		if err != nil || len(res.DataRecords) != 1 || res.Request.URL != v.url {
+			t.Errorf("[%s] Expected file to be fetched from [%s] [%v]", name, v.url, err)
+		}
+	}
+}
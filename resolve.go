@@ -0,0 +1,107 @@
+package adstxt
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// errPreResolve host of Ads.txt request couldn't be resolved on pre-resolution stage
+const errPreResolve = "[%s] failed to resolve Ads.txt host [%s]: %s"
+
+// lookupFunc resolve host IP addresses
+type lookupFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+// lookupIP resolve host IP addresses using the default resolver
+func lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// preResolve resolve hosts of all requests concurrently (each host is resolved once), before crawling starts.
+// Resolved addresses are set on the requests, so hosts are dialed without additional DNS lookup. Requests of
+// unresolvable hosts are dropped and reported to handler with error. Requests which were not resolved before context
+// was done are returned as not attempted (and are not reported to handler)
+func (c *Crawler) preResolve(ctx context.Context, req []*Request, h Handler) ([]*Request, []*Request) {
+	hosts := make(map[string][]*Request)
+	for _, r := range req {
+		u, err := url.Parse(r.URL)
+		if err != nil || len(u.Hostname()) == 0 || net.ParseIP(u.Hostname()) != nil || len(r.IPs[u.Hostname()]) > 0 {
+			continue
+		}
+		hosts[u.Hostname()] = append(hosts[u.Hostname()], r)
+	}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	failed := make(map[*Request]error)
+	interrupted := make(map[*Request]bool)
+	guard := make(chan struct{}, c.resolveConcurrency)
+
+	for host, requests := range hosts {
+		select {
+		case guard <- struct{}{}:
+		case <-ctx.Done():
+			lock.Lock()
+			for _, r := range requests {
+				interrupted[r] = true
+			}
+			lock.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(host string, requests []*Request) {
+			defer wg.Done()
+			defer func() { <-guard }()
+
+			lookupCtx, cancel := context.WithTimeout(ctx, time.Second*requestTimeout)
+			defer cancel()
+
+			ips, err := c.lookup(lookupCtx, host)
+			if err == nil && len(ips) == 0 {
+				err = fmt.Errorf("no addresses found")
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			for _, r := range requests {
+				if err != nil && ctx.Err() != nil {
+					interrupted[r] = true
+					continue
+				}
+				if err != nil {
+					failed[r] = fmt.Errorf(errPreResolve, r.Domain, host, err.Error())
+					continue
+				}
+
+				// copy request addresses, since caller may share them across requests
+				resolved := make(map[string][]net.IP, len(r.IPs)+1)
+				for k, v := range r.IPs {
+					resolved[k] = v
+				}
+				resolved[host] = ips
+				r.IPs = resolved
+			}
+		}(host, requests)
+	}
+	wg.Wait()
+
+	resolved := make([]*Request, 0, len(req))
+	notAttempted := []*Request{}
+	for _, r := range req {
+		if interrupted[r] {
+			notAttempted = append(notAttempted, r)
+			continue
+		}
+		if err, ok := failed[r]; ok {
+			h.Handle(r, nil, err)
+			continue
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, notAttempted
+}
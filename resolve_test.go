@@ -0,0 +1,97 @@
+package adstxt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestPreResolve test unresolvable hosts are dropped before crawling and resolved hosts are dialed directly
+func TestPreResolve(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	var lock sync.Mutex
+	lookups := map[string]int{}
+
+	c := NewCrawler(WithPreResolve(2))
+	c.lookup = func(ctx context.Context, host string) ([]net.IP, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		lookups[host]++
+		if host == "dead.invalid" {
+			return nil, fmt.Errorf("no such host")
+		}
+		return []net.IP{net.ParseIP("127.0.0.1")}, nil
+	}
+
+	requests := []*Request{}
+	for _, host := range []string{"live.invalid", "live.invalid", "dead.invalid"} {
+		req, _ := NewRequest("http://" + host + ":" + port)
+		requests = append(requests, req)
+	}
+
+	results := map[string][]error{}
+	c.GetMultiple(requests, HandlerFunc(func(req *Request, res *Response, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		results[req.Domain.String()] = append(results[req.Domain.String()], err)
+	}))
+
+	if lookups["live.invalid"] != 1 || lookups["dead.invalid"] != 1 {
+		t.Errorf("Expected each host to be resolved once and not %v", lookups)
+	}
+	for _, err := range results["live.invalid"] {
+		if err != nil {
+			t.Errorf("Expected pre-resolved host to be crawled [%s]", err)
+		}
+	}
+	if errs := results["dead.invalid"]; len(errs) != 1 || errs[0] == nil {
+		t.Errorf("Expected unresolvable host to be reported with error %v", errs)
+	}
+}
+
+// TestPreResolveCancelled test requests not resolved before context is done are not attempted and not reported
+func TestPreResolveCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewCrawler(WithPreResolve(1))
+	c.lookup = func(lookupCtx context.Context, host string) ([]net.IP, error) {
+		cancel()
+		<-lookupCtx.Done()
+		return nil, lookupCtx.Err()
+	}
+
+	requests := []*Request{}
+	for _, host := range []string{"first.invalid", "second.invalid", "third.invalid"} {
+		req, _ := NewRequest("http://" + host)
+		requests = append(requests, req)
+	}
+
+	var lock sync.Mutex
+	handled := 0
+	summary := c.GetMultipleContext(ctx, requests, HandlerFunc(func(req *Request, res *Response, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		handled++
+	}))
+
+	if handled != 0 {
+		t.Errorf("Expected no request to be reported to handler and not [%d]", handled)
+	}
+	if summary.Completed != 0 || len(summary.NotAttempted) != len(requests) {
+		t.Errorf("Expected all requests to be not attempted and not %d completed, %d not attempted",
+			summary.Completed, len(summary.NotAttempted))
+	}
+}
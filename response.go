@@ -3,8 +3,8 @@ package adstxt
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
-	"time"
 )
 
 // Records holds collection of Ads.txt records parsed from an Ads.txt file, in additon to
@@ -13,7 +13,44 @@ type Records struct {
 	DataRecords []*DataRecord `json:"dataRecords"`
 	Variables   []*Variable   `json:"variables"`
 	Warnings    []*Warning    `json:"warnings"`
-	Body        []string      `json:"body"` // Original Ads.txt file content
+	Suppressed  []*Warning    `json:"suppressed,omitempty"` // Warnings matching caller supplied suppression rules
+	Body        []string      `json:"body"`                 // Original Ads.txt file content
+	Flags       []string      `json:"flags,omitempty"`      // Flags Ads.txt file content state (see File* flags)
+
+	Preprocessed []string `json:"preprocessed,omitempty"` // Preprocessed names of preprocessors which transformed the body before parsing
+	Profile      string   `json:"profile,omitempty"`      // Profile name of the validation profile the file was validated against
+
+	Skipped []*SkippedLine `json:"skipped,omitempty"` // Skipped lines which were not parsed into data or variable record
+
+	accountIDs map[sellerKey]string // first declared letter case of each (ad system, lower case account ID)
+}
+
+// Ads.txt file content flags: file was fetched successfully, but doesn't declare any data record
+const (
+	// FileEmpty file exists but is empty (or contains only whitespace)
+	FileEmpty = "empty"
+	// FileCommentsOnly file contains only comments
+	FileCommentsOnly = "comments-only"
+	// FileVariablesOnly file contains variables but no data records
+	FileVariablesOnly = "variables-only"
+)
+
+// Skipped line reasons
+const (
+	// SkipBlank line is empty or contains only whitespace
+	SkipBlank = "blank"
+	// SkipComment line contains only a comment
+	SkipComment = "comment"
+	// SkipInvalid line couldn't be parsed into a valid record, see the warning code
+	SkipInvalid = "invalid"
+)
+
+// SkippedLine single Ads.txt line which was not parsed into data or variable record, and the reason it was skipped
+type SkippedLine struct {
+	Index  int    `json:"index"`          // Index of the line in the Ads.txt file
+	Text   string `json:"txt"`            // Text of the line
+	Reason string `json:"reason"`         // Reason the line was skipped: blank, comment or invalid
+	Code   string `json:"code,omitempty"` // Code of the warning of invalid line (see Warn* codes)
 }
 
 // Response to an Ads.txt request: collection of Data\Variable records parsed from Ads.txt file and
@@ -21,24 +58,28 @@ type Records struct {
 type Response struct {
 	*Request
 	*Records
-	Expires time.Time `json:"expires"` // Ads.txt file expiration date
+	Expires   Expiration       `json:"expires"`            // Ads.txt file expiration date
+	Headers   http.Header      `json:"headers,omitempty"`  // Headers HTTP response headers captured by the crawler (see WithCapturedHeaders)
+	Redirects []*RedirectEvent `json:"redirects"`          // Redirects followed while fetching Ads.txt file
+	Security  *SecurityReport  `json:"security,omitempty"` // Security fetch-time security report (see WithSecurityReport)
+	Score     *Score           `json:"score,omitempty"`    // Score weighted validation summary (see WithScoring)
+	Timings   []*Timing        `json:"timings,omitempty"`  // Timings duration breakdown of each HTTP request sent while fetching Ads.txt file (redirects included)
+
+	Annotations map[string]string `json:"annotations,omitempty"` // Annotations set by response transformers (see WithTransformers)
+
+	ETag         string `json:"etag,omitempty"`         // ETag HTTP cache validator of Ads.txt file
+	LastModified string `json:"lastModified,omitempty"` // LastModified HTTP cache validator of Ads.txt file
+	Unchanged    bool   `json:"unchanged,omitempty"`    // Unchanged file didn't change since the stored snapshot, and was not parsed (see WithDifferential)
 }
 
-// parseRecords parse Ads.txt file content
-func parseRecords(lines []string) *Records {
-	r := &Records{
+// newRecords create new empty Ads.txt records collection
+func newRecords() *Records {
+	return &Records{
 		DataRecords: []*DataRecord{},
 		Variables:   []*Variable{},
 		Warnings:    []*Warning{},
-		Body:        lines,
+		Body:        []string{},
 	}
-
-	// loop over Ads.txt file lines and parse each line into Ads.txt record
-	for index, l := range lines {
-		r.parseRecord(index+1, l)
-	}
-
-	return r
 }
 
 // parseRecord parse a single Ads.txt line into Data\Variable record
@@ -47,6 +88,11 @@ func (r *Records) parseRecord(index int, txt string) {
 
 	// ignore comments and empty line
 	if len(line) == 0 || string(line) == commentDenote {
+		reason := SkipComment
+		if len(strings.TrimSpace(txt)) == 0 {
+			reason = SkipBlank
+		}
+		r.skip(index, txt, reason, "")
 		return
 	}
 
@@ -58,21 +104,121 @@ func (r *Records) parseRecord(index int, txt string) {
 			w.Text = txt
 			r.Warnings = append(r.Warnings, w)
 		}
+		if dr == nil {
+			r.skip(index, txt, SkipInvalid, w.Code)
+		}
 		if dr != nil {
 			r.DataRecords = append(r.DataRecords, dr)
+			for _, w := range r.checkAccountID(dr, strings.Split(line, ",")[1]) {
+				w.Index = index
+				w.Text = txt
+				r.Warnings = append(r.Warnings, w)
+			}
 		}
 	} else if strings.Index(line, "=") != -1 && strings.Count(line, "=") == 1 {
 		v, w := parseVarialbe(txt)
+		if mw := malformedVariable(line); w != nil && mw != nil {
+			w = mw
+		}
 		if w != nil {
 			w.Index = index
 			w.Text = txt
 			r.Warnings = append(r.Warnings, w)
+			r.skip(index, txt, SkipInvalid, w.Code)
 		} else {
 			r.Variables = append(r.Variables, v)
 		}
 	} else {
-		w := &Warning{Text: txt, Index: index, Level: HighSevirity, Message: "could not parse this line"}
+		w := malformedVariable(line)
+		if w == nil {
+			w = &Warning{Code: WarnUnparsableLine, Level: HighSevirity, Message: "could not parse this line"}
+		}
+		w.Index = index
+		w.Text = txt
 		r.Warnings = append(r.Warnings, w)
+		r.skip(index, txt, SkipInvalid, w.Code)
+	}
+}
+
+// skip record line which was not parsed into data or variable record
+func (r *Records) skip(index int, txt string, reason string, code string) {
+	r.Skipped = append(r.Skipped, &SkippedLine{Index: index, Text: txt, Reason: reason, Code: code})
+}
+
+// checkAccountID check data record publisher account ID field as declared for invisible characters, and for letter
+// case different from previous declarations of the same account ID on the same ad system
+func (r *Records) checkAccountID(dr *DataRecord, field string) []*Warning {
+	warnings := []*Warning{}
+	if w := diagnoseAccountID(field); w != nil {
+		warnings = append(warnings, w)
+	}
+
+	if r.accountIDs == nil {
+		r.accountIDs = make(map[sellerKey]string)
+	}
+	id := normalizeAccountID(dr.PublisherAccountID)
+	k := newSellerKey(dr.AdverterDomain.String(), strings.ToLower(id))
+	if first, ok := r.accountIDs[k]; !ok {
+		r.accountIDs[k] = id
+	} else if first != id {
+		warnings = append(warnings, &Warning{
+			Code:       WarnAccountIDCase,
+			Level:      LowSevirity,
+			Message:    fmt.Sprintf("Publisher account ID [%s] of [%s] was previously declared as [%s]", id, dr.AdverterDomain, first),
+			Suggestion: first,
+		})
+	}
+
+	return warnings
+}
+
+// trimAccountIDs remove invisible characters from all data records publisher account IDs (lenient mode), the
+// original account ID is preserved and record is flagged as normalized
+func (r *Records) trimAccountIDs() {
+	for _, dr := range r.DataRecords {
+		if id := normalizeAccountID(dr.PublisherAccountID); id != dr.PublisherAccountID {
+			dr.OriginalPublisherAccountID = dr.PublisherAccountID
+			dr.PublisherAccountID = id
+			dr.addFlag(FlagNormalized)
+		}
+	}
+}
+
+// setFileFlags flag Ads.txt file content state, once all lines are parsed
+func (r *Records) setFileFlags() {
+	if len(r.DataRecords) > 0 {
+		return
+	}
+
+	lines, comments := 0, 0
+	for _, l := range r.Body {
+		l = strings.TrimSpace(l)
+		if len(l) == 0 {
+			continue
+		}
+		lines++
+		if strings.HasPrefix(l, commentDenote) {
+			comments++
+		}
+	}
+
+	switch {
+	case lines == 0:
+		r.Flags = appendFlag(r.Flags, FileEmpty)
+	case lines == comments:
+		r.Flags = appendFlag(r.Flags, FileCommentsOnly)
+	case len(r.Variables) > 0:
+		r.Flags = appendFlag(r.Flags, FileVariablesOnly)
+	}
+}
+
+// addFlag add quality flag to all data and variable records
+func (r *Records) addFlag(flag string) {
+	for _, dr := range r.DataRecords {
+		dr.addFlag(flag)
+	}
+	for _, v := range r.Variables {
+		v.addFlag(flag)
 	}
 }
 
@@ -0,0 +1,69 @@
+package adstxt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// errRangeResume interrupted download couldn't be resumed
+const errRangeResume = "[%s] failed to resume Ads.txt download from byte [%d]: %s"
+
+// rangeRequest resumption of interrupted download from byte offset, conditional on the file validator (If-Range)
+type rangeRequest struct {
+	from      int
+	validator string
+}
+
+// apply set HTTP range request headers
+func (r *rangeRequest) apply(httpRequest *http.Request) {
+	httpRequest.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.from))
+	httpRequest.Header.Set("If-Range", r.validator)
+}
+
+// rangeValidator return validator of response usable in If-Range header: strong ETag or Last-Modified date,
+// empty string when there is none
+func rangeValidator(res *http.Response) string {
+	if etag := res.Header.Get("ETag"); len(etag) > 0 && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return res.Header.Get("Last-Modified")
+}
+
+// canResume check if interrupted download of response can be resumed using range request: origin accepts byte
+// ranges, the file has a validator and body was not transparently decompressed (ranges apply to encoded body)
+func canResume(res *http.Response, err error, received int) bool {
+	return received > 0 && !res.Uncompressed && res.Header.Get("Accept-Ranges") == "bytes" && len(rangeValidator(res)) > 0 &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// resumeBody request the rest of interrupted download of Ads.txt file from origin, and return the whole body. When
+// origin sends the full file (e.g. it changed since the interrupted download) it replaces the received body
+func (c *Crawler) resumeBody(req *Request, body []byte, validator string) ([]byte, error) {
+	r := *req
+	r.resume = &rangeRequest{from: len(body), validator: validator}
+	r.baseline = nil
+
+	res, err := c.sendRequest(&r)
+	if err != nil {
+		return body, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		if !strings.HasPrefix(res.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", len(body))) {
+			return body, fmt.Errorf(errRangeResume, req.URL, len(body), "unexpected content range "+res.Header.Get("Content-Range"))
+		}
+		rest, err := ioutil.ReadAll(io.LimitReader(res.Body, int64(MaxFileSize-len(body)+1)))
+		return append(body, rest...), err
+	case http.StatusOK:
+		return ioutil.ReadAll(limitBody(res.Body))
+	default:
+		return body, fmt.Errorf(errRangeResume, req.URL, len(body), res.Status)
+	}
+}
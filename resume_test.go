@@ -0,0 +1,56 @@
+package adstxt
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestRangeResume test interrupted download is resumed using range request
+func TestRangeResume(t *testing.T) {
+	body := strings.Repeat("greenadexchange.com,XF7342,DIRECT\n", 100)
+	cut := len(body) / 3
+	ranges := []string{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("ETag", `"v1"`)
+
+		if rng := r.Header.Get("Range"); len(rng) > 0 {
+			ranges = append(ranges, rng+" "+r.Header.Get("If-Range"))
+			from, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", from, len(body)-1, len(body)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(body[from:]))
+			return
+		}
+
+		// send the first part of the body and drop the connection
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body[:cut]))
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer ts.Close()
+
+	if _, err := NewCrawler().Get(&Request{URL: ts.URL + "/ads.txt", Domain: "127.0.0.1"}); err == nil {
+		t.Errorf("Expected interrupted download to fail without range resumption")
+	}
+
+	res, err := NewCrawler(WithRangeResume(1)).Get(&Request{URL: ts.URL + "/ads.txt", Domain: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.DataRecords) != 100 {
+		t.Errorf("Expected [100] data records of resumed download and not [%d]", len(res.DataRecords))
+	}
+	if e := fmt.Sprintf(`bytes=%d- "v1"`, cut); len(ranges) != 1 || ranges[0] != e {
+		t.Errorf("Expected single range request [%s] and not %v", e, ranges)
+	}
+}
@@ -0,0 +1,192 @@
+package adstxt
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionPolicy per domain snapshots retention. A snapshot is retained in full if it is one of the latest
+// KeepSnapshots snapshots, or if it was crawled within KeepFor. The latest snapshot is always retained
+type RetentionPolicy struct {
+	KeepSnapshots int           // KeepSnapshots number of latest snapshots retained in full
+	KeepFor       time.Duration // KeepFor snapshots crawled within this duration are retained in full
+	Compact       bool          // Compact snapshots outside of retention to diffs instead of deleting them
+	DropAfter     time.Duration // DropAfter compacted snapshots crawled before this duration are deleted (0 to keep forever)
+}
+
+// MaintenanceReport result of store maintenance
+type MaintenanceReport struct {
+	Domains   int `json:"domains"`   // Domains number of maintained domains
+	Retained  int `json:"retained"`  // Retained number of snapshots retained in full
+	Compacted int `json:"compacted"` // Compacted number of snapshots compacted to diffs on this maintenance
+	Deleted   int `json:"deleted"`   // Deleted number of deleted snapshots
+}
+
+// errSnapshotChain compacted snapshot diff can't be applied, since its base snapshot is missing
+const errSnapshotChain = "[%s] snapshot crawled at [%s] can't be restored, base snapshot is missing"
+
+// DiffOp single operation of snapshot diff: copy Count lines from base starting at From, or insert Lines
+type DiffOp struct {
+	From  int      `json:"from,omitempty"`  // From index of the first copied base line
+	Count int      `json:"count,omitempty"` // Count number of copied base lines
+	Lines []string `json:"lines,omitempty"` // Lines inserted lines
+}
+
+// SnapshotDiff Ads.txt file body of compacted snapshot, as operations applied to the next (newer) snapshot body
+type SnapshotDiff struct {
+	Ops []*DiffOp `json:"ops"`
+}
+
+// newSnapshotDiff create diff restoring body from base. Runs of lines found in base are copied, others are inserted
+func newSnapshotDiff(body []string, base []string) *SnapshotDiff {
+	positions := make(map[string][]int)
+	for i, l := range base {
+		positions[l] = append(positions[l], i)
+	}
+
+	d := &SnapshotDiff{Ops: []*DiffOp{}}
+	for i := 0; i < len(body); {
+		// longest run of base lines matching body from line i
+		from, count := 0, 0
+		for _, p := range positions[body[i]] {
+			n := 0
+			for i+n < len(body) && p+n < len(base) && body[i+n] == base[p+n] {
+				n++
+			}
+			if n > count {
+				from, count = p, n
+			}
+		}
+
+		if count == 0 {
+			if last := len(d.Ops) - 1; last >= 0 && d.Ops[last].Count == 0 {
+				d.Ops[last].Lines = append(d.Ops[last].Lines, body[i])
+			} else {
+				d.Ops = append(d.Ops, &DiffOp{Lines: []string{body[i]}})
+			}
+			i++
+			continue
+		}
+
+		d.Ops = append(d.Ops, &DiffOp{From: from, Count: count})
+		i += count
+	}
+	return d
+}
+
+// Apply diff to base body, and return the restored body
+func (d *SnapshotDiff) Apply(base []string) ([]string, error) {
+	body := []string{}
+	for _, op := range d.Ops {
+		if op.Count == 0 {
+			body = append(body, op.Lines...)
+			continue
+		}
+		if op.From < 0 || op.From+op.Count > len(base) {
+			return nil, fmt.Errorf("diff copy of [%d] lines from [%d] is out of base range [%d]", op.Count, op.From, len(base))
+		}
+		body = append(body, base[op.From:op.From+op.Count]...)
+	}
+	return body, nil
+}
+
+// Bodies restore Ads.txt file body of each domain snapshot (oldest first), resolving compacted snapshots diffs
+// from the latest snapshot
+func Bodies(snapshots []*Snapshot) ([][]string, error) {
+	bodies := make([][]string, len(snapshots))
+
+	var base []string
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		s := snapshots[i]
+		switch {
+		case s.Response != nil && s.Response.Records != nil:
+			bodies[i] = s.Response.Body
+		case s.Diff != nil && base != nil:
+			body, err := s.Diff.Apply(base)
+			if err != nil {
+				return nil, err
+			}
+			bodies[i] = body
+		default:
+			return nil, fmt.Errorf(errSnapshotChain, s.Domain, s.CrawledAt.Format(time.RFC3339))
+		}
+		base = bodies[i]
+	}
+	return bodies, nil
+}
+
+// Maintain apply retention policy to all store domains
+func Maintain(s Store, p RetentionPolicy, now time.Time) (*MaintenanceReport, error) {
+	domains, err := s.Domains()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MaintenanceReport{}
+	for _, d := range domains {
+		if err := MaintainDomain(s, d, p, now, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// MaintainDomain apply retention policy to domain snapshots, adding results to report
+func MaintainDomain(s Store, domain string, p RetentionPolicy, now time.Time, report *MaintenanceReport) error {
+	snapshots, err := s.Snapshots(domain)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	bodies, err := Bodies(snapshots)
+	if err != nil {
+		return err
+	}
+
+	report.Domains++
+	kept := []*Snapshot{}
+	changed := false
+	for i, snapshot := range snapshots {
+		latest := len(snapshots) - i
+		age := now.Sub(snapshot.CrawledAt)
+
+		// retained in full
+		if latest == 1 || latest <= p.KeepSnapshots || (p.KeepFor > 0 && age <= p.KeepFor) {
+			kept = append(kept, snapshot)
+			report.Retained++
+			continue
+		}
+
+		if !p.Compact || (p.DropAfter > 0 && age > p.DropAfter) {
+			report.Deleted++
+			changed = true
+			continue
+		}
+
+		if !snapshot.Compacted() {
+			// diff against the next snapshot, which is restored from the original (not yet compacted) chain
+			snapshot = &Snapshot{
+				Domain:    snapshot.Domain,
+				Tenant:    snapshot.Tenant,
+				CrawledAt: snapshot.CrawledAt,
+				Digest:    snapshot.Digest,
+				Diff:      newSnapshotDiff(bodies[i], bodies[i+1]),
+
+				ETag:         snapshot.ETag,
+				LastModified: snapshot.LastModified,
+				URL:          snapshot.URL,
+			}
+			report.Compacted++
+			changed = true
+		}
+		kept = append(kept, snapshot)
+	}
+
+	if !changed {
+		return nil
+	}
+	return s.Replace(domain, kept)
+}
@@ -0,0 +1,78 @@
+package adstxt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSnapshotDiff test compacted snapshot body is restored from base body
+func TestSnapshotDiff(t *testing.T) {
+	bodies := map[string]string{
+		"a\nb\nc\nd": "a\nb\nc\nd",
+		"a\nx\nc\nd": "a\nb\nc\nd",
+		"d\nc\nb\na": "a\nb\nc\nd",
+		"":           "a\nb",
+		"x\ny\na\nb": "a\nb",
+		"a\na\na\nb": "a\nb\nb",
+	}
+
+	for body, base := range bodies {
+		b := strings.Split(body, "\n")
+		d := newSnapshotDiff(b, strings.Split(base, "\n"))
+		restored, err := d.Apply(strings.Split(base, "\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(restored, "\n") != body {
+			t.Errorf("Expected restored body [%q] and not [%q]", body, strings.Join(restored, "\n"))
+		}
+	}
+}
+
+// TestMaintain test snapshots outside of retention policy are compacted or deleted
+func TestMaintain(t *testing.T) {
+	now := time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC)
+
+	s := NewMemoryStore()
+	for d := 0; d < 10; d++ {
+		rec, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT\ngreenadexchange.com,XF" + strings.Repeat("1", d) + ",RESELLER"))
+		res := &Response{Request: &Request{Domain: "example.com"}, Records: rec}
+		s.Put(NewSnapshot(res, now.AddDate(0, 0, -d)))
+	}
+	original, _ := s.Snapshots("example.com")
+	expected, _ := Bodies(original)
+
+	// latest 2 snapshots and 3 days are retained, older are compacted and snapshots older than 7 days deleted
+	p := RetentionPolicy{KeepSnapshots: 2, KeepFor: 3 * 24 * time.Hour, Compact: true, DropAfter: 7 * 24 * time.Hour}
+	report, err := Maintain(s, p, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Domains != 1 || report.Retained != 4 || report.Compacted != 4 || report.Deleted != 2 {
+		t.Errorf("Unexpected maintenance report %+v", report)
+	}
+
+	snapshots, _ := s.Snapshots("example.com")
+	bodies, err := Bodies(snapshots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range bodies {
+		if strings.Join(b, "\n") != strings.Join(expected[i+2], "\n") {
+			t.Errorf("[%d] Expected restored snapshot body [%q] and not [%q]", i, expected[i+2], b)
+		}
+	}
+
+	// second maintenance doesn't compact already compacted snapshots
+	report, _ = Maintain(s, p, now)
+	if report.Compacted != 0 || report.Deleted != 0 {
+		t.Errorf("Expected no changes on second maintenance and not %+v", report)
+	}
+
+	// without compaction, all snapshots outside of retention are deleted
+	report, _ = Maintain(s, RetentionPolicy{KeepSnapshots: 1}, now)
+	if snapshots, _ := s.Snapshots("example.com"); len(snapshots) != 1 || report.Deleted != 7 {
+		t.Errorf("Expected single snapshot to be retained and not [%d]", len(snapshots))
+	}
+}
@@ -0,0 +1,115 @@
+package adstxt
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ReverseRecord single sellers.json seller entry, checked against the Ads.txt file of the seller domain
+type ReverseRecord struct {
+	AdSystem   string `json:"adSystem"`             // AdSystem domain of the ad system publishing the sellers.json file
+	SellerID   string `json:"sellerId,omitempty"`   // SellerID identifier associated with the seller
+	SellerType string `json:"sellerType,omitempty"` // SellerType PUBLISHER, INTERMEDIARY or BOTH
+	Domain     string `json:"domain,omitempty"`     // Domain seller domain, which Ads.txt file was crawled
+	Status     string `json:"status,omitempty"`     // Status authorization of the seller account in the seller domain Ads.txt file, empty when crawl failed
+	Consistent bool   `json:"consistent"`           // Consistent account is authorized with relationship matching the seller type
+	Error      string `json:"error,omitempty"`      // Error fetching sellers.json file, or crawling the seller domain
+}
+
+// ReverseCrawl fetch ad systems sellers.json files, and crawl the Ads.txt files of seller domains (see
+// ReverseCrawlSellers). Ad systems which sellers.json file can't be fetched are reported with error
+func (c *Crawler) ReverseCrawl(ctx context.Context, adSystems ...string) []*ReverseRecord {
+	records := []*ReverseRecord{}
+	sellers := []*Sellers{}
+	for _, a := range adSystems {
+		s, err := c.GetSellersContext(ctx, a)
+		if err != nil {
+			records = append(records, &ReverseRecord{AdSystem: registryKey(a), Error: err.Error()})
+			continue
+		}
+		sellers = append(sellers, s)
+	}
+	return append(records, c.ReverseCrawlSellers(ctx, sellers...)...)
+}
+
+// ReverseCrawlSellers crawl Ads.txt files of sellers.json seller domains (each file is crawled once), and check
+// each seller account is authorized by its domain: PUBLISHER accounts are expected to be DIRECT, INTERMEDIARY
+// accounts RESELLER and BOTH either. Confidential sellers and sellers without domain are skipped. Records are
+// sorted by ad system and seller ID
+func (c *Crawler) ReverseCrawlSellers(ctx context.Context, sellers ...*Sellers) []*ReverseRecord {
+	records := []*ReverseRecord{}
+	requests := map[string]*Request{}
+	keys := map[*ReverseRecord]string{}
+	for _, s := range sellers {
+		for _, seller := range s.Sellers {
+			if seller.IsConfidential == 1 || len(strings.TrimSpace(seller.Domain)) == 0 {
+				continue
+			}
+
+			r := &ReverseRecord{AdSystem: s.AdSystem, SellerID: seller.SellerID, SellerType: seller.SellerType}
+			req, err := NewRequest(strings.ToLower(strings.TrimSpace(seller.Domain)))
+			if err != nil {
+				r.Domain, r.Error = seller.Domain, err.Error()
+			} else {
+				// seller domains may be subdomains sharing root domain, so requests are keyed by file
+				r.Domain = req.Domain.String()
+				keys[r] = FileKey(r.Domain, req.URL)
+				if _, ok := requests[keys[r]]; !ok {
+					requests[keys[r]] = req
+				}
+			}
+			records = append(records, r)
+		}
+	}
+
+	req := make([]*Request, 0, len(requests))
+	files := map[*Request]string{}
+	for key, r := range requests {
+		req = append(req, r)
+		files[r] = key
+	}
+
+	// results are keyed using caller requests, crawled copies URL may be updated on redirects
+	var lock sync.Mutex
+	responses := map[string]*Response{}
+	errs := map[string]error{}
+	summary := c.getMultiple(ctx, req, func(r *Request, _ *Request, res *Response, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		responses[files[r]], errs[files[r]] = res, err
+	})
+	for _, r := range summary.NotAttempted {
+		errs[files[r]] = summary.Err
+	}
+
+	for _, r := range records {
+		if len(r.Error) > 0 {
+			continue
+		}
+		if err := errs[keys[r]]; err != nil {
+			r.Error = err.Error()
+			continue
+		}
+
+		a := authorize(responses[keys[r]], r.AdSystem, r.SellerID)
+		r.Status = a.Status
+		switch r.SellerType {
+		case SellerTypePublisher:
+			r.Consistent = a.Status == AuthDirect
+		case SellerTypeIntermediary:
+			r.Consistent = a.Status == AuthReseller
+		default:
+			r.Consistent = a.Authorized()
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].AdSystem != records[j].AdSystem {
+			return records[i].AdSystem < records[j].AdSystem
+		}
+		return records[i].SellerID < records[j].SellerID
+	})
+	return records
+}
@@ -0,0 +1,65 @@
+package adstxt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestReverseCrawlSellers test sellers.json seller domains are crawled and checked against seller accounts
+func TestReverseCrawlSellers(t *testing.T) {
+	files := map[string]string{
+		"news.com":      "greenadexchange.com,XF1,DIRECT\ngreenadexchange.com,XF3,RESELLER",
+		"reseller.com":  "greenadexchange.com,XF2,DIRECT",
+		"apps.news.com": "greenadexchange.com,XF6,DIRECT",
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.Host]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, body)
+	}))
+	defer ts.Close()
+
+	sellers, err := ParseSellers("greenadexchange.com", []byte(`{"version": "1.0", "sellers": [
+		{"seller_id": "XF1", "domain": "news.com", "seller_type": "PUBLISHER"},
+		{"seller_id": "XF2", "domain": "reseller.com", "seller_type": "INTERMEDIARY"},
+		{"seller_id": "XF3", "domain": "News.com", "seller_type": "BOTH"},
+		{"seller_id": "XF4", "domain": "missing.com", "seller_type": "PUBLISHER"},
+		{"seller_id": "XF5", "seller_type": "PUBLISHER", "is_confidential": 1},
+		{"seller_id": "XF6", "domain": "apps.news.com", "seller_type": "PUBLISHER"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	route := routeTo(ts, nil)
+	records := NewCrawler(route).ReverseCrawlSellers(context.Background(), sellers)
+
+	type expectation struct {
+		status     string
+		consistent bool
+		err        bool
+	}
+	expected := map[string]expectation{
+		"XF1": {status: AuthDirect, consistent: true},
+		"XF2": {status: AuthDirect, consistent: false},
+		"XF3": {status: AuthReseller, consistent: true},
+		"XF4": {err: true},
+		"XF6": {status: AuthDirect, consistent: true},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected [%d] reverse crawl records and not [%d]", len(expected), len(records))
+	}
+	for _, r := range records {
+		e := expected[r.SellerID]
+		if r.Status != e.status || r.Consistent != e.consistent || (len(r.Error) > 0) != e.err {
+			t.Errorf("[%s] Unexpected reverse crawl record [%s] [%t] [%s]", r.SellerID, r.Status, r.Consistent, r.Error)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package adstxt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SupplyChain OpenRTB SupplyChain object (source.ext.schain), listing all the sellers involved in a bid request
+type SupplyChain struct {
+	Complete int                `json:"complete"` // Complete chain contains all nodes back to the inventory owner
+	Ver      string             `json:"ver"`      // Ver version of the supply chain specification
+	Nodes    []*SupplyChainNode `json:"nodes"`    // Nodes sellers in the chain, first node is the closest to the publisher
+}
+
+// SupplyChainNode OpenRTB SupplyChainNode object, a single seller in the supply chain
+type SupplyChainNode struct {
+	ASI    string `json:"asi"`              // ASI canonical domain of the ad system (Ads.txt ad system domain)
+	SID    string `json:"sid"`              // SID seller ID in the ad system (Ads.txt publisher account ID and sellers.json seller ID)
+	HP     int    `json:"hp"`               // HP node is involved in the flow of payment
+	RID    string `json:"rid,omitempty"`    // RID request ID issued by this seller
+	Name   string `json:"name,omitempty"`   // Name of the company paid for inventory sold on this account
+	Domain string `json:"domain,omitempty"` // Domain business domain name of the seller
+}
+
+// HopReport validation findings of single supply chain node
+type HopReport struct {
+	Index      int        `json:"index"`      // Index of the node in the supply chain
+	ASI        string     `json:"asi"`        // ASI node ad system domain
+	SID        string     `json:"sid"`        // SID node seller ID
+	Authorized bool       `json:"authorized"` // Authorized seller account is declared in the publisher Ads.txt file
+	Seller     *Seller    `json:"seller"`     // Seller sellers.json entry of the node, nil when not found
+	Findings   []*Warning `json:"findings"`   // Findings validation findings (see WarnSchain* codes)
+}
+
+// ValidateSupplyChain validate supply chain nodes against publisher Ads.txt response and sellers.json files (keyed by
+// ad system domain): each node seller account must be declared in Ads.txt, seller ID must be declared in ad system
+// sellers.json, and Ads.txt relationship must be consistent with sellers.json seller type
+func ValidateSupplyChain(chain *SupplyChain, res *Response, sellers map[string]*Sellers) []*HopReport {
+	hops := []*HopReport{}
+	if chain == nil {
+		return hops
+	}
+
+	// publisher Ads.txt seller accounts and their relationship
+	declared := map[sellerKey]string{}
+	if res != nil && res.Records != nil {
+		for _, dr := range res.DataRecords {
+			k := newSellerKey(canonicalAdSystemDomain(dr.AdverterDomain.String()), dr.PublisherAccountID)
+			// account declared both as DIRECT and RESELLER is consistent with any seller type
+			if t, ok := declared[k]; ok && t != dr.AccountType {
+				declared[k] = ""
+				continue
+			}
+			declared[k] = dr.AccountType
+		}
+	}
+
+	for index, n := range chain.Nodes {
+		h := &HopReport{Index: index, ASI: n.ASI, SID: n.SID, Findings: []*Warning{}}
+		hops = append(hops, h)
+
+		asi := strings.ToLower(canonicalAdSystemDomain(strings.TrimSpace(n.ASI)))
+		accountType, ok := declared[newSellerKey(asi, n.SID)]
+		h.Authorized = ok
+		if !ok {
+			h.addFinding(WarnSchainUnauthorized, HighSevirity, "seller account [%s] [%s] is not declared in publisher Ads.txt file", n.ASI, n.SID)
+		}
+
+		s, ok := sellers[asi]
+		if !ok {
+			h.addFinding(WarnSchainMissingSellers, LowSevirity, "sellers.json file of ad system [%s] is not available", n.ASI)
+			continue
+		}
+		h.Seller = s.Seller(n.SID)
+		if h.Seller == nil {
+			h.addFinding(WarnSchainUnknownSeller, HighSevirity, "seller ID [%s] is not declared in [%s] sellers.json file", n.SID, n.ASI)
+			continue
+		}
+
+		// only the inventory owner sells directly, any following node is a reseller
+		switch {
+		case accountType == accountTypeDirect && index > 0:
+			h.addFinding(WarnSchainRelationship, HighSevirity, "seller account [%s] [%s] is declared as [%s] but is not the first node of the supply chain", n.ASI, n.SID, accountType)
+		case accountType == accountTypeDirect && h.Seller.SellerType == SellerTypeIntermediary,
+			accountType == accountTypeReseller && h.Seller.SellerType == SellerTypePublisher:
+			h.addFinding(WarnSchainRelationship, HighSevirity, "seller account [%s] [%s] is declared as [%s] but seller type is [%s]", n.ASI, n.SID, accountType, h.Seller.SellerType)
+		}
+
+		if len(n.Domain) > 0 && len(h.Seller.Domain) > 0 && !sameRootDomain(n.Domain, h.Seller.Domain) {
+			h.addFinding(WarnSchainDomain, LowSevirity, "node domain [%s] is different from sellers.json seller domain [%s]", n.Domain, h.Seller.Domain)
+		}
+	}
+
+	return hops
+}
+
+// addFinding add validation finding to hop report
+func (h *HopReport) addFinding(code string, level Sevirity, format string, a ...interface{}) {
+	h.Findings = append(h.Findings, &Warning{Index: h.Index, Code: code, Level: level, Message: fmt.Sprintf(format, a...)})
+}
@@ -0,0 +1,60 @@
+package adstxt
+
+import (
+	"testing"
+)
+
+// TestValidateSupplyChain test supply chain nodes are validated against Ads.txt and sellers.json
+func TestValidateSupplyChain(t *testing.T) {
+	res := &Response{Request: &Request{Domain: "example.com"}, Records: &Records{DataRecords: []*DataRecord{
+		{AdverterDomain: "greenadexchange.com", PublisherAccountID: "1", AccountType: accountTypeDirect},
+		{AdverterDomain: "greenadexchange.com", PublisherAccountID: "2", AccountType: accountTypeReseller},
+		{AdverterDomain: "reseller.com", PublisherAccountID: "10", AccountType: accountTypeDirect},
+	}}}
+
+	sellers := map[string]*Sellers{
+		"greenadexchange.com": {AdSystem: "greenadexchange.com", Sellers: []*Seller{
+			{SellerID: "1", Domain: "example.com", SellerType: SellerTypePublisher},
+			{SellerID: "2", Domain: "reseller.com", SellerType: SellerTypePublisher},
+		}},
+		"reseller.com": {AdSystem: "reseller.com", Sellers: []*Seller{
+			{SellerID: "10", Domain: "reseller.com", SellerType: SellerTypeIntermediary},
+		}},
+	}
+
+	chain := &SupplyChain{Complete: 1, Ver: "1.0", Nodes: []*SupplyChainNode{
+		{ASI: "greenadexchange.com", SID: "1", HP: 1, Domain: "www.example.com"},
+		{ASI: "reseller.com", SID: "10", HP: 1},
+		{ASI: "WWW.greenadexchange.com", SID: "2", HP: 1, Domain: "other.com"},
+		{ASI: "greenadexchange.com", SID: "3", HP: 1},
+		{ASI: "unknown.com", SID: "4", HP: 1},
+	}}
+
+	expected := [][]string{
+		{},
+		{WarnSchainRelationship},
+		{WarnSchainRelationship, WarnSchainDomain},
+		{WarnSchainUnauthorized, WarnSchainUnknownSeller},
+		{WarnSchainUnauthorized, WarnSchainMissingSellers},
+	}
+
+	hops := ValidateSupplyChain(chain, res, sellers)
+	if len(hops) != len(expected) {
+		t.Fatalf("Expected [%d] hop reports and not [%d]", len(expected), len(hops))
+	}
+	for i, h := range hops {
+		codes := []string{}
+		for _, f := range h.Findings {
+			codes = append(codes, f.Code)
+		}
+		if len(codes) != len(expected[i]) {
+			t.Errorf("[%d] Expected findings %v and not %v", i, expected[i], codes)
+			continue
+		}
+		for j := range codes {
+			if codes[j] != expected[i][j] {
+				t.Errorf("[%d] Expected findings %v and not %v", i, expected[i], codes)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package adstxt
+
+import (
+	"sort"
+)
+
+// ScoreWeights penalty points subtracted from the maximum score (100) for each finding. Per code weights override
+// the warning severity weight
+type ScoreWeights struct {
+	Low   float64            // Low penalty of low severity warning
+	High  float64            // High penalty of high severity warning
+	Codes map[string]float64 // Codes penalty by warning code (see Warn* codes), overrides severity penalty
+	Flags map[string]float64 // Flags penalty by Ads.txt file flag (see File* and Flag* flags)
+}
+
+// DefaultScoreWeights default findings penalties
+var DefaultScoreWeights = ScoreWeights{
+	Low:  1,
+	High: 5,
+	Flags: map[string]float64{
+		FileEmpty:               100,
+		FileCommentsOnly:        100,
+		FileVariablesOnly:       50,
+		FlagLenientContentType:  10,
+		FlagLenientRedirectPath: 5,
+		FlagCrossDomainRedirect: 5,
+	},
+}
+
+// maxScore score of Ads.txt file without any finding
+const maxScore = 100
+
+// Score weighted validation summary of Ads.txt file: score between 0 and 100, and letter grade
+type Score struct {
+	Score     float64            `json:"score"`     // Score 100 minus findings penalties (not lower than 0)
+	Grade     string             `json:"grade"`     // Grade letter grade of the score: A (90+), B (80+), C (70+), D (60+) or F
+	Findings  int                `json:"findings"`  // Findings number of scored warnings and flags
+	Penalties map[string]float64 `json:"penalties"` // Penalties total penalty by warning code or flag
+}
+
+// NewScore score Ads.txt records findings (suppressed warnings are not scored)
+func NewScore(r *Records, w ScoreWeights) *Score {
+	s := &Score{Score: maxScore, Penalties: make(map[string]float64)}
+	if r == nil {
+		return s
+	}
+
+	penalty := func(key string, p float64) {
+		if p <= 0 {
+			return
+		}
+		s.Findings++
+		s.Penalties[key] += p
+		s.Score -= p
+	}
+
+	for _, warning := range r.Warnings {
+		p, ok := w.Codes[warning.Code]
+		if !ok {
+			switch warning.Level {
+			case HighSevirity:
+				p = w.High
+			case LowSevirity:
+				p = w.Low
+			}
+		}
+		penalty(warning.Code, p)
+	}
+	for _, f := range r.Flags {
+		penalty(f, w.Flags[f])
+	}
+
+	if s.Score < 0 {
+		s.Score = 0
+	}
+	s.Grade = grade(s.Score)
+	return s
+}
+
+// grade return letter grade of score
+func grade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+// DomainScore score of single publisher domain
+type DomainScore struct {
+	Domain string `json:"domain"` // Domain publisher root domain
+	*Score
+}
+
+// Scores build per domain scores report from a collection of Ads.txt responses, sorted by score (ascending, worst
+// publishers first) and then by domain. Response scores are used when set (see WithScoring)
+func Scores(responses []*Response, w ScoreWeights) []*DomainScore {
+	scores := []*DomainScore{}
+	for _, res := range responses {
+		if res == nil || res.Records == nil || res.Request == nil {
+			continue
+		}
+
+		s := res.Score
+		if s == nil {
+			s = NewScore(res.Records, w)
+		}
+		scores = append(scores, &DomainScore{Domain: res.Request.Domain.String(), Score: s})
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Score.Score != scores[j].Score.Score {
+			return scores[i].Score.Score < scores[j].Score.Score
+		}
+		return scores[i].Domain < scores[j].Domain
+	})
+	return scores
+}
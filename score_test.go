@@ -0,0 +1,63 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestScore test Ads.txt findings are weighted into score and letter grade
+func TestScore(t *testing.T) {
+	expected := map[string]struct {
+		score float64
+		grade string
+	}{
+		"greenadexchange.com,XF7342,DIRECT":                                         {100, "A"},
+		"greenadexchange.com,XF7342,DIRECT\nredssp.com,1234,RESELLER":               {99, "A"},
+		"greenadexchange.com,XF7342,DIRECT\ngreenadexchange.com,XF7343,INDIRECT\nx": {90, "A"},
+		"contact=ads@example.com":                                                   {50, "F"},
+		"":                                                                          {0, "F"},
+	}
+
+	for body, e := range expected {
+		rec, _ := ParseBody([]byte(body))
+		s := NewScore(rec, DefaultScoreWeights)
+		if s.Score != e.score || s.Grade != e.grade {
+			t.Errorf("[%q] Expected score [%v] grade [%s] and not [%v] [%s] %v", body, e.score, e.grade, s.Score, s.Grade, s.Penalties)
+		}
+	}
+
+	// per code weight overrides severity weight
+	rec, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT\nredssp.com,1234,RESELLER"))
+	w := ScoreWeights{High: 5, Codes: map[string]float64{WarnUnverifiedAdSystemDomain: 25}}
+	if s := NewScore(rec, w); s.Score != 75 || s.Grade != "C" || s.Penalties[WarnUnverifiedAdSystemDomain] != 25 {
+		t.Errorf("Expected code weight to be used and not %+v", s)
+	}
+}
+
+// TestScoring test crawler scores responses and scores report is sorted worst first
+func TestScoring(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT\nredssp.com,1234,RESELLER")
+	}))
+	defer ts.Close()
+
+	req, _ := NewRequest(ts.URL)
+	res, err := NewCrawler(WithScoring(DefaultScoreWeights)).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Score == nil || res.Score.Score != 99 {
+		t.Fatalf("Expected response to be scored [99] and not %+v", res.Score)
+	}
+
+	rec, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT"))
+	clean := &Response{Request: &Request{Domain: "clean.com"}, Records: rec}
+
+	scores := Scores([]*Response{clean, res, nil}, DefaultScoreWeights)
+	if len(scores) != 2 || scores[0].Domain != req.Domain.String() || scores[1].Domain != "clean.com" || scores[1].Grade != "A" {
+		t.Errorf("Expected scores report sorted by score ascending")
+	}
+}
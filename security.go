@@ -0,0 +1,74 @@
+package adstxt
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SecurityReport fetch-time security posture of Ads.txt file: TLS connection details, and whether the file was
+// only reachable over plaintext HTTP (see WithSecurityReport)
+type SecurityReport struct {
+	HTTPS         bool      `json:"https"`                // HTTPS Ads.txt file was served over HTTPS
+	PlaintextOnly bool      `json:"plaintextOnly"`        // PlaintextOnly Ads.txt file was served over HTTP and is not reachable over HTTPS
+	TLSVersion    string    `json:"tlsVersion,omitempty"` // TLSVersion negotiated TLS protocol version (e.g. TLS 1.3)
+	Issuer        string    `json:"issuer,omitempty"`     // Issuer leaf certificate issuer
+	Subject       string    `json:"subject,omitempty"`    // Subject leaf certificate subject
+	NotAfter      time.Time `json:"notAfter"`             // NotAfter leaf certificate expiration date
+	ChainValid    bool      `json:"chainValid"`           // ChainValid certificate chain was verified against trusted roots
+	HTTPSError    string    `json:"httpsError,omitempty"` // HTTPSError reason Ads.txt file is not reachable over HTTPS (e.g. certificate verification error)
+}
+
+// securityReport create security report of Ads.txt response. When file was served over plaintext HTTP, it is
+// fetched again over HTTPS to check if it is reachable there as well
+func (c *Crawler) securityReport(req *Request, res *http.Response) *SecurityReport {
+	if res.TLS != nil {
+		s := &SecurityReport{HTTPS: true}
+		s.setTLS(res.TLS)
+		return s
+	}
+
+	s := &SecurityReport{PlaintextOnly: true}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		s.HTTPSError = err.Error()
+		return s
+	}
+
+	// probe the default HTTPS port, plaintext alternate port is not expected to serve TLS
+	u.Scheme = "https"
+	u.Host = u.Hostname()
+
+	probe := *req
+	probe.URL = u.String()
+	pres, err := c.sendRequest(&probe)
+	if err != nil {
+		s.HTTPSError = err.Error()
+		return s
+	}
+	defer pres.Body.Close()
+
+	if pres.TLS != nil {
+		s.setTLS(pres.TLS)
+	}
+	if pres.StatusCode != http.StatusOK {
+		s.HTTPSError = pres.Status
+		return s
+	}
+
+	s.PlaintextOnly = false
+	return s
+}
+
+// setTLS set report TLS connection details
+func (s *SecurityReport) setTLS(state *tls.ConnectionState) {
+	s.TLSVersion = tls.VersionName(state.Version)
+	s.ChainValid = len(state.VerifiedChains) > 0
+	if len(state.PeerCertificates) > 0 {
+		leaf := state.PeerCertificates[0]
+		s.Issuer = leaf.Issuer.String()
+		s.Subject = leaf.Subject.String()
+		s.NotAfter = leaf.NotAfter.UTC()
+	}
+}
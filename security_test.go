@@ -0,0 +1,63 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestSecurityReport test security report of files served over HTTPS, and over HTTP only
+func TestSecurityReport(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	})
+
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+	secure := httptest.NewTLSServer(handler)
+	defer secure.Close()
+
+	crawler := func(trusted bool, opts ...Option) *Crawler {
+		route := routeSchemes(plain, secure, trusted)
+		return NewCrawler(append(opts, route)...)
+	}
+
+	// served over HTTPS
+	req, _ := NewRequest("https://example.com")
+	res, err := crawler(true, WithSecurityReport()).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.Security; s == nil || !s.HTTPS || s.PlaintextOnly || !s.ChainValid || len(s.TLSVersion) == 0 || len(s.Issuer) == 0 || s.NotAfter.IsZero() {
+		t.Errorf("Expected HTTPS security report with valid chain and not %+v", s)
+	}
+
+	// served over HTTP, reachable over HTTPS as well
+	req, _ = NewRequest("example.com")
+	res, err = crawler(true, WithSecurityReport()).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.Security; s == nil || s.HTTPS || s.PlaintextOnly || !s.ChainValid {
+		t.Errorf("Expected HTTP security report reachable over HTTPS and not %+v", s)
+	}
+
+	// served over HTTP, HTTPS certificate is not trusted
+	req, _ = NewRequest("example.com")
+	res, err = crawler(false, WithSecurityReport()).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.Security; s == nil || s.HTTPS || !s.PlaintextOnly || !strings.Contains(s.HTTPSError, "certificate") {
+		t.Errorf("Expected plaintext only security report with certificate error and not %+v", s)
+	}
+
+	// security report is disabled by default
+	req, _ = NewRequest("example.com")
+	if res, _ := crawler(true).Get(req); res == nil || res.Security != nil {
+		t.Errorf("Expected no security report by default")
+	}
+}
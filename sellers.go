@@ -0,0 +1,161 @@
+package adstxt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sellers.json seller types (IAB Tech Lab sellers.json specification)
+const (
+	// SellerTypePublisher inventory sold on this account is directly owned by the seller
+	SellerTypePublisher = "PUBLISHER"
+	// SellerTypeIntermediary inventory sold on this account is not owned by the seller
+	SellerTypeIntermediary = "INTERMEDIARY"
+	// SellerTypeBoth both types of inventory are sold on this account
+	SellerTypeBoth = "BOTH"
+)
+
+// sellers.json errors
+const (
+	errSellersHTTPError = "[%s] remote host [%s] sellers.json URL [%s]"
+	errSellersParse     = "[%s] failed to parse sellers.json file: %s"
+	errSellersTooLarge  = "[%s] sellers.json exceeds %d bytes"
+)
+
+// maxSellersSize maximum size of sellers.json file (large exchanges publish files of hundreds MB)
+const maxSellersSize = 512 << 20
+
+// Seller single sellers.json seller entry
+type Seller struct {
+	SellerID       string `json:"seller_id"`                 // SellerID identifier associated with the seller (ads.txt publisher account ID)
+	Name           string `json:"name,omitempty"`            // Name of the company paid for inventory sold on this account
+	Domain         string `json:"domain,omitempty"`          // Domain business domain name of the seller
+	SellerType     string `json:"seller_type"`               // SellerType PUBLISHER, INTERMEDIARY or BOTH
+	IsConfidential int    `json:"is_confidential,omitempty"` // IsConfidential seller identity is confidential
+	IsPassthrough  int    `json:"is_passthrough,omitempty"`  // IsPassthrough seller is a passthrough intermediary
+	Comment        string `json:"comment,omitempty"`         // Comment description of the seller
+}
+
+// Sellers hold sellers.json file of a single ad system
+type Sellers struct {
+	AdSystem       string    `json:"-"`                         // AdSystem domain of the ad system publishing the file
+	ContactEmail   string    `json:"contact_email,omitempty"`   // ContactEmail for inquiries about the file
+	ContactAddress string    `json:"contact_address,omitempty"` // ContactAddress of the ad system
+	Version        string    `json:"version"`                   // Version of the sellers.json specification
+	Sellers        []*Seller `json:"sellers"`                   // Sellers list
+
+	bySellerID map[string]*Seller
+}
+
+// UnmarshalJSON accept seller IDs declared as number (common in the wild) and normalize seller type letter case
+func (s *Seller) UnmarshalJSON(b []byte) error {
+	type seller Seller
+	v := struct {
+		*seller
+		SellerID json.RawMessage `json:"seller_id"`
+	}{seller: (*seller)(s)}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	id := string(bytes.TrimSpace(v.SellerID))
+	if strings.HasPrefix(id, `"`) {
+		if err := json.Unmarshal(v.SellerID, &id); err != nil {
+			return err
+		}
+	}
+
+	s.SellerID = strings.TrimSpace(id)
+	s.SellerType = strings.ToUpper(strings.TrimSpace(s.SellerType))
+	return nil
+}
+
+// ParseSellers parse sellers.json file of the specified ad system
+func ParseSellers(adSystem string, b []byte) (*Sellers, error) {
+	s := &Sellers{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf(errSellersParse, adSystem, err.Error())
+	}
+	s.AdSystem = strings.ToLower(adSystem)
+	s.index()
+
+	return s, nil
+}
+
+// Seller return seller by its seller ID, nil if seller is not declared
+func (s *Sellers) Seller(id string) *Seller {
+	if s.bySellerID == nil {
+		s.index()
+	}
+	return s.bySellerID[strings.TrimSpace(id)]
+}
+
+// index build seller ID index (first declaration wins)
+func (s *Sellers) index() {
+	s.bySellerID = make(map[string]*Seller, len(s.Sellers))
+	for _, seller := range s.Sellers {
+		if _, ok := s.bySellerID[seller.SellerID]; !ok {
+			s.bySellerID[seller.SellerID] = seller
+		}
+	}
+}
+
+// GetSellers fetch and parse sellers.json file of ad system, located using the default sellers.json registry
+func GetSellers(adSystem string) (*Sellers, error) {
+	return NewCrawler().GetSellers(adSystem)
+}
+
+// GetSellers fetch and parse sellers.json file of ad system using crawler, located using the crawler sellers.json
+// registry (see WithSellersRegistry)
+func (c *Crawler) GetSellers(adSystem string) (*Sellers, error) {
+	return c.GetSellersContext(context.Background(), adSystem)
+}
+
+// GetSellersContext fetch and parse sellers.json file of ad system using crawler until context is done. The file is
+// requested like Ads.txt files (host politeness, events, metrics and redirect scope checks apply)
+func (c *Crawler) GetSellersContext(ctx context.Context, adSystem string) (*Sellers, error) {
+	registry := c.sellersRegistry
+	if registry == nil {
+		registry = DefaultSellersRegistry
+	}
+	req := &Request{Domain: Domain(registryKey(adSystem)), URL: registry.URL(adSystem), ctx: ctx, accept: "application/json"}
+
+	for hops := 0; ; {
+		res, err := c.sendRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case res.StatusCode >= 300 && res.StatusCode < 400:
+			drainBody(res)
+			redirect, _, err := c.handleRedirect(req, res)
+			if err != nil {
+				return nil, err
+			}
+			if hops++; hops > maxNumRedirects {
+				return nil, fmt.Errorf(errInfiniteRedirect, req.URL, redirect)
+			}
+			req.URL = redirect
+		case res.StatusCode == http.StatusOK:
+			defer res.Body.Close()
+			b, err := io.ReadAll(io.LimitReader(res.Body, maxSellersSize+1))
+			if err != nil {
+				return nil, err
+			}
+			if len(b) > maxSellersSize {
+				return nil, fmt.Errorf(errSellersTooLarge, adSystem, maxSellersSize)
+			}
+			return ParseSellers(adSystem, b)
+		default:
+			drainBody(res)
+			return nil, fmt.Errorf(errSellersHTTPError, res.Status, req.Domain, req.URL)
+		}
+	}
+}
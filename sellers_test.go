@@ -0,0 +1,96 @@
+package adstxt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testSellers = `{
+	"contact_email": "adops@greenadexchange.com",
+	"version": "1.0",
+	"sellers": [
+		{"seller_id": "XF7342", "name": "Example", "domain": "example.com", "seller_type": "publisher"},
+		{"seller_id": 1234, "name": "Reseller", "domain": "reseller.com", "seller_type": "INTERMEDIARY"},
+		{"seller_id": "XF7342", "name": "Duplicate", "domain": "duplicate.com", "seller_type": "PUBLISHER"}
+	]
+}`
+
+// TestParseSellers test parsing sellers.json file
+func TestParseSellers(t *testing.T) {
+	s, err := ParseSellers("GreenAdExchange.com", []byte(testSellers))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"XF7342": SellerTypePublisher,
+		"1234":   SellerTypeIntermediary,
+	}
+	for id, sellerType := range expected {
+		seller := s.Seller(id)
+		if seller == nil {
+			t.Errorf("Expected seller [%s] to be declared", id)
+			continue
+		}
+		if seller.SellerType != sellerType {
+			t.Errorf("Expected seller [%s] type to be [%s] and not [%s]", id, sellerType, seller.SellerType)
+		}
+	}
+	if s.Seller("XF7342").Name != "Example" {
+		t.Errorf("Expected first declaration of duplicate seller ID to be used and not [%s]", s.Seller("XF7342").Name)
+	}
+	if s.AdSystem != "greenadexchange.com" {
+		t.Errorf("Expected ad system [greenadexchange.com] and not [%s]", s.AdSystem)
+	}
+
+	if _, err := ParseSellers("greenadexchange.com", []byte("<html>")); err == nil {
+		t.Error("Expected error when parsing invalid sellers.json file")
+	}
+}
+
+// TestGetSellers test fetching sellers.json file from ad system, following redirects
+func TestGetSellers(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sellers.json" {
+			http.Redirect(w, r, "/v1/sellers.json", http.StatusMovedPermanently)
+			return
+		}
+		if accept := r.Header.Get("Accept"); accept != "application/json" {
+			t.Errorf("Expected sellers.json to be requested as JSON and not [%s]", accept)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, testSellers)
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, ts.Client().Transport)
+
+	// sellers.json requests report crawl events like Ads.txt requests
+	started := 0
+	events := WithEventSink(EventSinkFunc(func(e *Event) {
+		if e.Type == EventRequestStarted {
+			started++
+		}
+	}))
+
+	s, err := NewCrawler(route, events).GetSellers("greenadexchange.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Sellers) != 3 {
+		t.Errorf("Expected [3] sellers and not [%d]", len(s.Sellers))
+	}
+	if started != 2 {
+		t.Errorf("Expected [2] started requests (redirect included) and not [%d]", started)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewCrawler(route).GetSellersContext(ctx, "greenadexchange.com"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context error and not [%v]", err)
+	}
+}
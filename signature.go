@@ -0,0 +1,160 @@
+package adstxt
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Signer sign and verify crawl results integrity
+type Signer interface {
+	Algorithm() string                       // Algorithm name of the signature algorithm
+	Sign(message []byte) ([]byte, error)     // Sign return signature of message
+	Verify(message []byte, sig []byte) error // Verify return error when signature does not match message
+}
+
+// NewHMACSigner create new HMAC-SHA256 signer using shared secret key
+func NewHMACSigner(key []byte) Signer {
+	return hmacSigner{key: key}
+}
+
+// NewEd25519Signer create new ed25519 signer using private key
+func NewEd25519Signer(key ed25519.PrivateKey) Signer {
+	return ed25519Signer{private: key, public: key.Public().(ed25519.PublicKey)}
+}
+
+// NewEd25519Verifier create new ed25519 signer which can only verify signatures, using public key
+func NewEd25519Verifier(key ed25519.PublicKey) Signer {
+	return ed25519Signer{public: key}
+}
+
+// hmacSigner HMAC-SHA256 signature
+type hmacSigner struct {
+	key []byte
+}
+
+func (s hmacSigner) Algorithm() string {
+	return "hmac-sha256"
+}
+
+func (s hmacSigner) Sign(message []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(message)
+	return mac.Sum(nil), nil
+}
+
+func (s hmacSigner) Verify(message []byte, sig []byte) error {
+	expected, _ := s.Sign(message)
+	if !hmac.Equal(expected, sig) {
+		return fmt.Errorf("Invalid [%s] signature", s.Algorithm())
+	}
+	return nil
+}
+
+// ed25519Signer ed25519 signature
+type ed25519Signer struct {
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+}
+
+func (s ed25519Signer) Algorithm() string {
+	return "ed25519"
+}
+
+func (s ed25519Signer) Sign(message []byte) ([]byte, error) {
+	if len(s.private) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("Missing [%s] private key, signer can only verify signatures", s.Algorithm())
+	}
+	return ed25519.Sign(s.private, message), nil
+}
+
+func (s ed25519Signer) Verify(message []byte, sig []byte) error {
+	if len(s.public) != ed25519.PublicKeySize || !ed25519.Verify(s.public, message, sig) {
+		return fmt.Errorf("Invalid [%s] signature", s.Algorithm())
+	}
+	return nil
+}
+
+// SignedResult holds encoded crawl result with integrity metadata, so downstream consumers can verify data provenance
+type SignedResult struct {
+	Payload   []byte    `json:"payload"`   // Payload encoded crawl result
+	Codec     string    `json:"codec"`     // Codec name used to encode the payload
+	Digest    string    `json:"digest"`    // Digest SHA-256 hex digest of the payload
+	CrawledAt time.Time `json:"crawledAt"` // CrawledAt crawl timestamp
+	Crawler   string    `json:"crawler"`   // Crawler identity of the crawler which produced the result
+	Algorithm string    `json:"algorithm"` // Algorithm signature algorithm
+	Signature []byte    `json:"signature"` // Signature of the payload digest and metadata
+}
+
+// Sign encode crawl result using codec and sign it with crawl timestamp and crawler identity. When crawler
+// identity is empty, the crawler User-Agent is used
+func Sign(v interface{}, c Codec, s Signer, crawler string, crawledAt time.Time) (*SignedResult, error) {
+	payload, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(crawler) == 0 {
+		crawler = userAgent
+	}
+
+	digest := sha256.Sum256(payload)
+	r := &SignedResult{
+		Payload:   payload,
+		Codec:     c.Name(),
+		Digest:    hex.EncodeToString(digest[:]),
+		CrawledAt: crawledAt.UTC(),
+		Crawler:   crawler,
+		Algorithm: s.Algorithm(),
+	}
+
+	r.Signature, err = s.Sign(r.message())
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Verify check payload digest and signature of signed result
+func (r *SignedResult) Verify(s Signer) error {
+	if r.Algorithm != s.Algorithm() {
+		return fmt.Errorf("Result is signed using [%s] and not [%s]", r.Algorithm, s.Algorithm())
+	}
+
+	digest := sha256.Sum256(r.Payload)
+	if hex.EncodeToString(digest[:]) != r.Digest {
+		return fmt.Errorf("Result payload does not match its digest [%s]", r.Digest)
+	}
+
+	return s.Verify(r.message(), r.Signature)
+}
+
+// Decode verify signed result and decode its payload into the value pointed to by v
+func (r *SignedResult) Decode(s Signer, v interface{}) error {
+	if err := r.Verify(s); err != nil {
+		return err
+	}
+
+	c, err := CodecByName(r.Codec)
+	if err != nil {
+		return err
+	}
+
+	return c.Unmarshal(r.Payload, v)
+}
+
+// message return signed message: signature metadata and payload digest, one per line
+func (r *SignedResult) message() []byte {
+	return []byte(strings.Join([]string{
+		r.Algorithm,
+		r.Codec,
+		r.Crawler,
+		r.CrawledAt.Format(time.RFC3339Nano),
+		r.Digest,
+	}, "\n"))
+}
@@ -0,0 +1,55 @@
+package adstxt
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+)
+
+// TestSignResult test signing and verifying crawl result with HMAC and ed25519 signers
+func TestSignResult(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pairs of signer and verifier
+	signers := [][2]Signer{
+		{NewHMACSigner([]byte("secret")), NewHMACSigner([]byte("secret"))},
+		{NewEd25519Signer(private), NewEd25519Verifier(public)},
+	}
+
+	res := &Response{Request: &Request{Domain: "example.com", URL: "http://example.com/ads.txt"}, Records: &Records{}}
+
+	for _, pair := range signers {
+		signer, verifier := pair[0], pair[1]
+		r, err := Sign(res, JSONCodec, signer, "", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := &Response{}
+		if err := r.Decode(verifier, decoded); err != nil {
+			t.Errorf("[%s] expected signed result to be verified [%s]", r.Algorithm, err.Error())
+		}
+		if decoded.Domain != res.Domain {
+			t.Errorf("[%s] expected decoded domain to be [%s] and not [%s]", r.Algorithm, res.Domain, decoded.Domain)
+		}
+
+		// tampering with payload or metadata invalidates the signature
+		r.Crawler = "someone else"
+		if err := r.Verify(verifier); err == nil {
+			t.Errorf("[%s] expected verification to fail after changing crawler identity", r.Algorithm)
+		}
+
+		r, _ = Sign(res, JSONCodec, signer, "", time.Now())
+		r.Payload[0] = ' '
+		if err := r.Verify(verifier); err == nil {
+			t.Errorf("[%s] expected verification to fail after changing payload", r.Algorithm)
+		}
+	}
+
+	if _, err := Sign(res, JSONCodec, NewEd25519Verifier(public), "", time.Now()); err == nil {
+		t.Error("Expected error when signing without private key")
+	}
+}
@@ -0,0 +1,106 @@
+package adstxt
+
+import (
+	"context"
+)
+
+// SourceList named set of Ads.txt requests (e.g. customer domain list)
+type SourceList struct {
+	Name     string     // Name of the source list, used to tag results
+	Requests []*Request // Requests of the source list
+}
+
+// The SourceHandler interface is used to process Ads.txt requests of source lists, tagged by source list name
+type SourceHandler interface {
+	HandleSource(source string, req *Request, res *Response, err error)
+}
+
+// A SourceHandlerFunc is a function signature that implements the SourceHandler interface
+type SourceHandlerFunc func(string, *Request, *Response, error)
+
+// HandleSource is the SourceHandler interface implementation for the SourceHandlerFunc type
+func (h SourceHandlerFunc) HandleSource(source string, req *Request, res *Response, err error) {
+	h(source, req, res, err)
+}
+
+// sourceRequest request of source list
+type sourceRequest struct {
+	source string
+	req    *Request
+}
+
+// sourceKey identity of source list requests which are crawled once: requests of the same tenant and URL, crawled
+// with the same credentials and leniency
+type sourceKey struct {
+	tenant  string
+	url     string
+	lenient bool
+	auth    Credentials
+	hasAuth bool
+}
+
+// newSourceKey return identity of source list request
+func newSourceKey(r *Request) sourceKey {
+	k := sourceKey{tenant: r.Tenant, url: r.URL, lenient: r.Lenient, hasAuth: r.Auth != nil}
+	if r.Auth != nil {
+		k.auth = *r.Auth
+	}
+	return k
+}
+
+// GetSources crawl and parse Ads.txt files of multiple source lists concurrently, within the crawler connection
+// budget. Requests shared by several lists (same tenant, URL, credentials and leniency) are crawled once, and the
+// result is reported to handler for each of the lists requests. Returned summary completed count accounts the unique
+// crawled requests, while in-flight and not attempted requests are the source lists requests
+func (c *Crawler) GetSources(ctx context.Context, lists []SourceList, h SourceHandler) *CrawlSummary {
+	unique := []*Request{}
+	shared := make(map[*Request][]sourceRequest)
+	byKey := make(map[sourceKey]*Request)
+
+	for _, l := range lists {
+		for _, r := range l.Requests {
+			key := newSourceKey(r)
+			u, ok := byKey[key]
+			if !ok {
+				// crawl using copy of the request, since crawl updates request URL on redirects
+				cp := *r
+				u = &cp
+				byKey[key] = u
+				unique = append(unique, u)
+			}
+			shared[u] = append(shared[u], sourceRequest{source: l.Name, req: r})
+		}
+	}
+
+	summary := c.getMultiple(ctx, unique, func(u *Request, crawled *Request, res *Response, err error) {
+		for _, s := range shared[u] {
+			s.req.URL = crawled.URL
+			h.HandleSource(s.source, s.req, sourceResponse(res, s.req), err)
+		}
+	})
+
+	summary.InFlight = sourceRequests(summary.InFlight, shared)
+	summary.NotAttempted = sourceRequests(summary.NotAttempted, shared)
+	return summary
+}
+
+// sourceRequests return source lists requests of crawled unique requests
+func sourceRequests(unique []*Request, shared map[*Request][]sourceRequest) []*Request {
+	req := []*Request{}
+	for _, u := range unique {
+		for _, s := range shared[u] {
+			req = append(req, s.req)
+		}
+	}
+	return req
+}
+
+// sourceResponse return copy of response pointing to source list request
+func sourceResponse(res *Response, req *Request) *Response {
+	if res == nil {
+		return nil
+	}
+	cp := *res
+	cp.Request = req
+	return &cp
+}
@@ -0,0 +1,110 @@
+package adstxt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestGetSources test requests shared by source lists are crawled once and reported to each list
+func TestGetSources(t *testing.T) {
+	var lock sync.Mutex
+	hits := map[string]int{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		hits[r.Host]++
+		lock.Unlock()
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, nil)
+
+	lists := map[string][]string{
+		"acme":   {"example.com", "shared.com"},
+		"globex": {"shared.com", "other.com"},
+	}
+	sources := []SourceList{}
+	for name, domains := range lists {
+		l := SourceList{Name: name}
+		for _, d := range domains {
+			req, _ := NewRequest(d)
+			l.Requests = append(l.Requests, req)
+		}
+		sources = append(sources, l)
+	}
+
+	handled := map[string]map[string]bool{}
+	h := func(source string, req *Request, res *Response, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if err != nil {
+			t.Errorf("[%s] %s", req.Domain, err)
+			return
+		}
+		if res.Request != req {
+			t.Errorf("[%s] Expected response to point to source list request", req.Domain)
+		}
+		if handled[source] == nil {
+			handled[source] = map[string]bool{}
+		}
+		handled[source][req.Domain.String()] = true
+	}
+
+	summary := NewCrawler(route).GetSources(context.Background(), sources, SourceHandlerFunc(h))
+	if summary.Completed != 3 {
+		t.Errorf("Expected [3] unique requests to be crawled and not [%d]", summary.Completed)
+	}
+	if hits["shared.com"] != 1 {
+		t.Errorf("Expected shared host to be crawled once and not [%d]", hits["shared.com"])
+	}
+	for name, domains := range lists {
+		for _, d := range domains {
+			if !handled[name][d] {
+				t.Errorf("[%s] Expected [%s] result to be reported", name, d)
+			}
+		}
+	}
+}
+
+// TestGetSourcesSummary test requests crawled with other credentials or leniency are not shared, and summary holds
+// source lists requests
+func TestGetSourcesSummary(t *testing.T) {
+	requests := map[*Request]bool{}
+	newRequest := func(opts ...RequestOption) *Request {
+		req, _ := NewRequest("shared.com", opts...)
+		requests[req] = true
+		return req
+	}
+	lenient := newRequest()
+	lenient.Lenient = true
+	sources := []SourceList{
+		{Name: "acme", Requests: []*Request{newRequest(), newRequest(WithBearerToken("acme"))}},
+		{Name: "globex", Requests: []*Request{newRequest(), newRequest(WithBearerToken("globex")), lenient}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary := NewCrawler().GetSources(ctx, sources, SourceHandlerFunc(func(string, *Request, *Response, error) {}))
+	if len(summary.NotAttempted)+len(summary.InFlight) != len(requests) {
+		t.Errorf("Expected [%d] not crawled source lists requests and not %v", len(requests), summary.NotAttempted)
+	}
+	for _, req := range append(summary.NotAttempted, summary.InFlight...) {
+		if !requests[req] {
+			t.Errorf("Expected summary to hold source lists requests and not [%p]", req)
+		}
+	}
+
+	unique := map[sourceKey]bool{}
+	for req := range requests {
+		unique[newSourceKey(req)] = true
+	}
+	if len(unique) != 4 {
+		t.Errorf("Expected [4] unique requests and not [%d]", len(unique))
+	}
+}
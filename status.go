@@ -0,0 +1,149 @@
+package adstxt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Crawl failure classes
+const (
+	// FailureClient remote host responded with client error (4xx), file is missing or access is denied
+	FailureClient = "client-error"
+	// FailureOrigin remote host responded with server error (5xx), origin is failing and the fetch can be retried
+	FailureOrigin = "origin-error"
+	// FailureStatus remote host responded with unexpected HTTP status (e.g. 1xx, 204)
+	FailureStatus = "unexpected-status"
+	// FailureTransport remote host couldn't be reached: DNS, connection, TLS or timeout failure
+	FailureTransport = "transport-error"
+	// FailureOther crawl failed after response was received (e.g. redirect policy, content type or parsing)
+	FailureOther = "other"
+)
+
+// maxRetryAfter upper bound of remote host Retry-After delay honored by crawler retries
+const maxRetryAfter = 5 * time.Minute
+
+// StatusError is returned when remote host responded with HTTP status other than success or redirect
+type StatusError struct {
+	StatusCode int           // StatusCode HTTP response status code
+	Status     string        // Status HTTP response status line (e.g. 503 Service Unavailable)
+	Domain     string        // Domain request root domain
+	URL        string        // URL of the failed Ads.txt request
+	RetryAfter time.Duration // RetryAfter delay requested by remote host Retry-After header (0 when not set)
+	Attempts   int           // Attempts number of fetch attempts made by crawler (see WithRetry)
+}
+
+// newStatusError create status error of HTTP response
+func newStatusError(req *Request, res *http.Response) *StatusError {
+	return &StatusError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Domain:     req.Domain.String(),
+		URL:        req.URL,
+		RetryAfter: retryAfter(res.Header, time.Now()),
+		Attempts:   1,
+	}
+}
+
+// retryAfter parse Retry-After header delay (seconds or HTTP date), 0 when not set or already passed. Delay seconds
+// are clamped to maxDeltaSeconds
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	v := h.Get("Retry-After")
+	s, err := strconv.Atoi(v)
+	if errors.Is(err, strconv.ErrRange) && s > 0 {
+		err = nil
+	}
+	if err == nil && s > 0 {
+		if s > maxDeltaSeconds {
+			s = maxDeltaSeconds
+		}
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func (e *StatusError) Error() string {
+	if e.Class() == FailureClient {
+		return fmt.Sprintf(errHTTPClientError, e.Status, e.Domain, e.URL)
+	}
+	return fmt.Sprintf(errHTTPGeneralError, e.Status, e.Domain, e.URL)
+}
+
+// Class return failure class of HTTP status: client, origin or unexpected status
+func (e *StatusError) Class() string {
+	switch {
+	case 400 <= e.StatusCode && e.StatusCode < 500:
+		return FailureClient
+	case 500 <= e.StatusCode && e.StatusCode < 600:
+		return FailureOrigin
+	default:
+		return FailureStatus
+	}
+}
+
+// Retryable check if the fetch can be retried (origin errors)
+func (e *StatusError) Retryable() bool {
+	return e.Class() == FailureOrigin
+}
+
+// FailureClass classify crawl error: client, origin or unexpected HTTP status, transport failure or other
+func FailureClass(err error) string {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Class()
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return FailureTransport
+	}
+	return FailureOther
+}
+
+// Retryable check if crawl error is retryable (remote host responded with origin error)
+func Retryable(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Retryable()
+}
+
+// retryDelay return delay before retry attempt (0 based): exponential backoff, or remote host Retry-After delay
+// when longer
+func (c *Crawler) retryDelay(err error, attempt int) time.Duration {
+	delay := c.retryBackoff << attempt
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+		delay = statusErr.RetryAfter
+		if delay > maxRetryAfter {
+			delay = maxRetryAfter
+		}
+	}
+	return delay
+}
+
+// wait retry delay, return false if request context is done before
+func wait(req *Request, delay time.Duration) bool {
+	ctx := req.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
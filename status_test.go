@@ -0,0 +1,110 @@
+package adstxt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestStatusErrorClass test HTTP status errors are classified as client, origin or unexpected status
+func TestStatusErrorClass(t *testing.T) {
+	expected := map[int]string{
+		http.StatusNotFound:            FailureClient,
+		http.StatusForbidden:           FailureClient,
+		http.StatusInternalServerError: FailureOrigin,
+		http.StatusServiceUnavailable:  FailureOrigin,
+		http.StatusNoContent:           FailureStatus,
+	}
+
+	for status, class := range expected {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		req, _ := NewRequest(ts.URL)
+		_, err := Get(req)
+		ts.Close()
+
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != status {
+			t.Errorf("[%d] Expected status error and not [%v]", status, err)
+			continue
+		}
+		if c := FailureClass(err); c != class {
+			t.Errorf("[%d] Expected failure class [%s] and not [%s]", status, class, c)
+		}
+		if Retryable(err) != (class == FailureOrigin) {
+			t.Errorf("[%d] Expected only origin errors to be retryable", status)
+		}
+	}
+
+	// unreachable host
+	req, _ := NewRequest("http://127.0.0.1:1")
+	if _, err := Get(req); FailureClass(err) != FailureTransport || Retryable(err) {
+		t.Errorf("Expected not retryable transport failure and not [%v]", err)
+	}
+}
+
+// TestGetRetry test origin errors are retried with backoff, client errors are not
+func TestGetRetry(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Query().Get("status") == "404":
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusNotFound)
+		case atomic.AddInt32(&calls, 1) < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("greenadexchange.com,XF7342,DIRECT"))
+		}
+	}))
+	defer ts.Close()
+
+	c := NewCrawler(WithRetry(3, time.Millisecond))
+
+	req, _ := NewRequest(ts.URL)
+	if _, err := c.Get(req); err != nil || atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("Expected Ads.txt file to be fetched on the third attempt and not [%d] [%v]", calls, err)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	req, _ = NewRequest(ts.URL + "/ads.txt?status=404")
+	if _, err := c.Get(req); err == nil || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected client error not to be retried and not [%d] attempts", calls)
+	}
+
+	// retries are exhausted
+	atomic.StoreInt32(&calls, -10)
+	req, _ = NewRequest(ts.URL)
+	_, err := NewCrawler(WithRetry(2, time.Millisecond)).Get(req)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Attempts != 3 {
+		t.Errorf("Expected origin error after [3] attempts and not [%v]", err)
+	}
+}
+
+// TestRetryAfter test Retry-After delay seconds and HTTP date are parsed, and huge delays don't overflow
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC)
+	expected := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		"-1":                            0,
+		"9999999999999":                 maxDeltaSeconds * time.Second,
+		"99999999999999999999":          maxDeltaSeconds * time.Second,
+		"Sat, 05 Nov 2044 08:50:37 GMT": time.Minute,
+		"Sat, 05 Nov 2033 08:49:37 GMT": 0,
+	}
+
+	for v, delay := range expected {
+		if d := retryAfter(http.Header{"Retry-After": {v}}, now); d != delay {
+			t.Errorf("[%s] Expected retry after [%s] and not [%s]", v, delay, d)
+		}
+	}
+}
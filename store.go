@@ -0,0 +1,431 @@
+package adstxt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snapshot single stored crawl result of a domain. Snapshots outside of the retention policy may be compacted, in
+// which case only the Ads.txt file body is kept, as a diff against the next (newer) snapshot
+type Snapshot struct {
+	Domain    string        `json:"domain"`             // Domain publisher root domain
+	Tenant    string        `json:"tenant,omitempty"`   // Tenant on behalf of which the domain was crawled
+	CrawledAt time.Time     `json:"crawledAt"`          // CrawledAt crawl date
+	Digest    string        `json:"digest"`             // Digest SHA-256 of the Ads.txt file body (hex)
+	Response  *Response     `json:"response,omitempty"` // Response crawl result, nil when compacted
+	Diff      *SnapshotDiff `json:"diff,omitempty"`     // Diff of the Ads.txt file body against the next snapshot, when compacted
+
+	ETag         string `json:"etag,omitempty"`         // ETag HTTP cache validator of the crawled file
+	LastModified string `json:"lastModified,omitempty"` // LastModified HTTP cache validator of the crawled file
+
+	URL string `json:"url,omitempty"` // URL the file was requested at (before redirects), snapshots are stored by file (see FileKey)
+}
+
+// NewSnapshot create new snapshot of Ads.txt response crawled at the specified date
+func NewSnapshot(res *Response, crawledAt time.Time) *Snapshot {
+	s := &Snapshot{CrawledAt: crawledAt.UTC(), Response: res, ETag: res.ETag, LastModified: res.LastModified}
+	if res.Request != nil {
+		s.Domain = res.Request.Domain.String()
+		s.Tenant = res.Request.Tenant
+		s.URL = res.requestedURL()
+	}
+	if res.Records != nil {
+		s.Digest = bodyDigest(res.Body)
+	}
+	return s
+}
+
+// key return store key of snapshot file, scoped by tenant
+func (s *Snapshot) key() string {
+	return tenantKey(s.Tenant, FileKey(s.Domain, s.URL))
+}
+
+// fileSeparator separate domain from file in keys of files other than the domain ads.txt file (domain names can't
+// include it)
+const fileSeparator = "#"
+
+// FileKey return store (and cache) key of domain file requested at rawurl: the domain itself for the domain ads.txt
+// file, domain followed by the escaped file path otherwise ("example.com#app-ads.txt", or
+// "example.com#sub.example.com%2Fads.txt" for file of subdomain). Files are located regardless of URL scheme and www.
+// host prefix, so each file of a domain is stored in its own snapshots series
+func FileKey(domain string, rawurl string) string {
+	if len(rawurl) == 0 {
+		return domain
+	}
+
+	host, path := fileLocation(rawurl)
+	file := host + path
+	if host == domain || len(host) == 0 {
+		file = strings.TrimPrefix(path, "/")
+	}
+	if file == FileAdsTxt {
+		return domain
+	}
+	return domain + fileSeparator + url.PathEscape(file)
+}
+
+// Compacted check if snapshot was compacted to diff
+func (s *Snapshot) Compacted() bool {
+	return s.Response == nil && s.Diff != nil
+}
+
+// bodyDigest return SHA-256 digest (hex) of Ads.txt file body lines
+func bodyDigest(body []string) string {
+	d := sha256.Sum256([]byte(strings.Join(body, "\n")))
+	return hex.EncodeToString(d[:])
+}
+
+// Store persist domain crawl snapshots. Snapshots are keyed by domain file (see FileKey), scoped by tenant
+// ("tenant/domain", see TenantStore). Implementations must be safe for concurrent use
+type Store interface {
+	Put(s *Snapshot) error                              // Put add snapshot to store
+	Snapshots(domain string) ([]*Snapshot, error)       // Snapshots return domain snapshots, oldest first
+	Domains() ([]string, error)                         // Domains return sorted list of stored domain files (tenant scoped, see FileKey)
+	Replace(domain string, snapshots []*Snapshot) error // Replace all domain snapshots (e.g. on maintenance), empty list deletes the domain
+}
+
+// MemoryStore in memory snapshots store
+type MemoryStore struct {
+	lock      sync.RWMutex
+	snapshots map[string][]*Snapshot
+	health    map[string]*DomainHealth
+}
+
+// NewMemoryStore create new empty in memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{snapshots: make(map[string][]*Snapshot), health: make(map[string]*DomainHealth)}
+}
+
+// Put add snapshot to store
+func (m *MemoryStore) Put(s *Snapshot) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := s.key()
+	m.snapshots[key] = sortSnapshots(append(m.snapshots[key], s))
+	return nil
+}
+
+// Snapshots return domain snapshots, oldest first
+func (m *MemoryStore) Snapshots(domain string) ([]*Snapshot, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]*Snapshot{}, m.snapshots[domain]...), nil
+}
+
+// Domains return sorted list of stored domains
+func (m *MemoryStore) Domains() ([]string, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	domains := make([]string, 0, len(m.snapshots))
+	for d := range m.snapshots {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return domains, nil
+}
+
+// Replace all domain snapshots
+func (m *MemoryStore) Replace(domain string, snapshots []*Snapshot) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if len(snapshots) == 0 {
+		delete(m.snapshots, domain)
+		return nil
+	}
+	m.snapshots[domain] = sortSnapshots(append([]*Snapshot{}, snapshots...))
+	return nil
+}
+
+// Health return copy of domain health ledger entry
+func (m *MemoryStore) Health(domain string) (*DomainHealth, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	h, ok := m.health[domain]
+	if !ok {
+		return nil, nil
+	}
+	entry := *h
+	return &entry, nil
+}
+
+// UpdateHealth atomically update domain health ledger entry
+func (m *MemoryStore) UpdateHealth(domain string, update func(h *DomainHealth)) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	h, ok := m.health[domain]
+	if !ok {
+		h = &DomainHealth{}
+		m.health[domain] = h
+	}
+	update(h)
+	return nil
+}
+
+// HealthDomains return sorted list of domains with health ledger entry
+func (m *MemoryStore) HealthDomains() ([]string, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	domains := make([]string, 0, len(m.health))
+	for d := range m.health {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return domains, nil
+}
+
+// FileStore store snapshots in directory, single file per domain encoded using codec. Domain health ledger entries
+// are stored in health subdirectory, single file per domain
+type FileStore struct {
+	Dir   string // Dir directory of domain files
+	Codec Codec  // Codec used to encode domain files
+
+	lock sync.RWMutex
+}
+
+// NewFileStore create new file store in directory (created when missing)
+func NewFileStore(dir string, codec Codec) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{Dir: dir, Codec: codec}, nil
+}
+
+// Put add snapshot to store
+func (f *FileStore) Put(s *Snapshot) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := s.key()
+	snapshots, err := f.read(key)
+	if err != nil {
+		return err
+	}
+	return f.write(key, append(snapshots, s))
+}
+
+// Snapshots return domain snapshots, oldest first
+func (f *FileStore) Snapshots(domain string) ([]*Snapshot, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.read(domain)
+}
+
+// Domains return sorted list of stored domains
+func (f *FileStore) Domains() ([]string, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.list(f.Dir)
+}
+
+// Replace all domain snapshots
+func (f *FileStore) Replace(domain string, snapshots []*Snapshot) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if len(snapshots) == 0 {
+		return f.remove(f.paths(f.Dir, domain))
+	}
+	return f.write(domain, snapshots)
+}
+
+// Health return domain health ledger entry
+func (f *FileStore) Health(domain string) (*DomainHealth, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.readHealth(domain)
+}
+
+// UpdateHealth atomically update domain health ledger entry
+func (f *FileStore) UpdateHealth(domain string, update func(h *DomainHealth)) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	h, err := f.readHealth(domain)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		h = &DomainHealth{}
+	}
+	update(h)
+
+	b, err := f.Codec.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(f.Dir, healthDir), 0755); err != nil {
+		return err
+	}
+	paths := f.paths(filepath.Join(f.Dir, healthDir), domain)
+	if err := writeFileAtomic(paths[0], b); err != nil {
+		return err
+	}
+	return f.remove(paths[1:])
+}
+
+// HealthDomains return sorted list of domains with health ledger entry
+func (f *FileStore) HealthDomains() ([]string, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.list(filepath.Join(f.Dir, healthDir))
+}
+
+// healthDir file store subdirectory of domain health ledger entries
+const healthDir = "health"
+
+// readHealth read domain health ledger entry file, nil when missing. Caller must hold the store lock
+func (f *FileStore) readHealth(domain string) (*DomainHealth, error) {
+	b, err := f.readFile(f.paths(filepath.Join(f.Dir, healthDir), domain))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	h := &DomainHealth{}
+	if err := f.Codec.Unmarshal(b, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// extensions return file name extensions of store codec: codec name, followed by wrapped codec name of compressed
+// codec, so files written before compression was enabled are still read (see GzipCodec)
+func (f *FileStore) extensions() []string {
+	extensions := []string{"." + f.Codec.Name()}
+	if gz, ok := f.Codec.(gzipCodec); ok {
+		extensions = append(extensions, "."+gz.codec.Name())
+	}
+	return extensions
+}
+
+// paths return domain file paths in dir by extension, file is written to the first one
+func (f *FileStore) paths(dir string, domain string) []string {
+	paths := []string{}
+	for _, ext := range f.extensions() {
+		paths = append(paths, filepath.Join(dir, url.PathEscape(domain)+ext))
+	}
+	return paths
+}
+
+// readFile read first existing file of paths, fs.ErrNotExist when none exists
+func (f *FileStore) readFile(paths []string) ([]byte, error) {
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return b, err
+		}
+	}
+	return nil, fs.ErrNotExist
+}
+
+// remove files of paths, missing files are ignored
+func (f *FileStore) remove(paths []string) error {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// list return sorted list of domains with file in dir, under any of the codec extensions
+func (f *FileStore) list(dir string) ([]string, error) {
+	found := map[string]bool{}
+	for _, ext := range f.extensions() {
+		files, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if d, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(file), ext)); err == nil {
+				found[d] = true
+			}
+		}
+	}
+
+	domains := make([]string, 0, len(found))
+	for d := range found {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return domains, nil
+}
+
+// read domain snapshots file, caller must hold the store lock
+func (f *FileStore) read(domain string) ([]*Snapshot, error) {
+	b, err := f.readFile(f.paths(f.Dir, domain))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []*Snapshot{}
+	if err := f.Codec.Unmarshal(b, &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// write domain snapshots file atomically (rename of temporary file), caller must hold the store lock
+func (f *FileStore) write(domain string, snapshots []*Snapshot) error {
+	b, err := f.Codec.Marshal(sortSnapshots(snapshots))
+	if err != nil {
+		return err
+	}
+	paths := f.paths(f.Dir, domain)
+	if err := writeFileAtomic(paths[0], b); err != nil {
+		return err
+	}
+	return f.remove(paths[1:])
+}
+
+// writeFileAtomic write file atomically: data is written to temporary file in the same directory, which is then
+// renamed over path
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// sortSnapshots sort snapshots by crawl date, oldest first
+func sortSnapshots(snapshots []*Snapshot) []*Snapshot {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].CrawledAt.Before(snapshots[j].CrawledAt)
+	})
+	return snapshots
+}
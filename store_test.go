@@ -0,0 +1,91 @@
+package adstxt
+
+import (
+	"testing"
+	"time"
+)
+
+// TestStores test memory and file stores keep domain snapshots sorted by crawl date
+func TestStores(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir(), MsgpackCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+	}
+
+	now := time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC)
+	for name, s := range stores {
+		for _, d := range []int{2, 0, 1} {
+			rec, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT"))
+			res := &Response{Request: &Request{Domain: "example.com", URL: "http://example.com/ads.txt"}, Records: rec}
+			if err := s.Put(NewSnapshot(res, now.AddDate(0, 0, d))); err != nil {
+				t.Fatalf("[%s] %s", name, err)
+			}
+		}
+
+		snapshots, err := s.Snapshots("example.com")
+		if err != nil {
+			t.Fatalf("[%s] %s", name, err)
+		}
+		if len(snapshots) != 3 || !snapshots[0].CrawledAt.Equal(now) || !snapshots[2].CrawledAt.Equal(now.AddDate(0, 0, 2)) {
+			t.Errorf("[%s] Expected [3] snapshots sorted by crawl date", name)
+		}
+		if snapshots[0].Response == nil || len(snapshots[0].Response.DataRecords) != 1 {
+			t.Errorf("[%s] Expected snapshot response to be stored", name)
+		}
+
+		if domains, _ := s.Domains(); len(domains) != 1 || domains[0] != "example.com" {
+			t.Errorf("[%s] Expected [example.com] to be stored and not %v", name, domains)
+		}
+
+		s.Replace("example.com", nil)
+		if domains, _ := s.Domains(); len(domains) != 0 {
+			t.Errorf("[%s] Expected no domains after replace and not %v", name, domains)
+		}
+	}
+}
+
+// TestStoreFiles test snapshots of different files of the same domain are stored in separate series
+func TestStoreFiles(t *testing.T) {
+	keys := map[string]string{
+		"http://example.com/ads.txt":         "example.com",
+		"https://www.example.com/ads.txt":    "example.com",
+		"http://example.com/app-ads.txt":     "example.com#app-ads.txt",
+		"http://sub.example.com/ads.txt":     "example.com#sub.example.com%2Fads.txt",
+		"http://example.com/custom/ads.txt/": "example.com#custom%2Fads.txt",
+		"":                                   "example.com",
+	}
+	for u, expected := range keys {
+		if k := FileKey("example.com", u); k != expected {
+			t.Errorf("Expected key of [%s] to be [%s] and not [%s]", u, expected, k)
+		}
+	}
+
+	fileStore, err := NewFileStore(t.TempDir(), JSONCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "file": NewTenantStore(fileStore, "acme")} {
+		for _, u := range []string{"http://example.com/ads.txt", "http://example.com/app-ads.txt"} {
+			rec, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT"))
+			res := &Response{Request: &Request{Domain: "example.com", URL: u}, Records: rec}
+			if err := s.Put(NewSnapshot(res, time.Now())); err != nil {
+				t.Fatalf("[%s] %s", name, err)
+			}
+		}
+
+		domains, _ := s.Domains()
+		if len(domains) != 2 || domains[0] != "example.com" || domains[1] != "example.com#app-ads.txt" {
+			t.Errorf("[%s] Expected separate series of each file and not %v", name, domains)
+		}
+		for _, d := range domains {
+			if snapshots, _ := s.Snapshots(d); len(snapshots) != 1 {
+				t.Errorf("[%s] Expected single snapshot of [%s] and not [%d]", name, d, len(snapshots))
+			}
+		}
+	}
+}
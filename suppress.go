@@ -0,0 +1,76 @@
+package adstxt
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Suppression rule to ignore known and accepted Ads.txt warnings, so they don't re-alert on every crawl. Rule
+// matches a warning when all of its set fields match (domain, line pattern and warning code), rule without pattern
+// and code matches no warning
+type Suppression struct {
+	Domain  string         // Domain root domain the rule applies to (empty for all domains)
+	Pattern *regexp.Regexp // Pattern matched against warning line text (nil to match by code only)
+	Code    string         // Code warning code (empty to match by pattern only)
+}
+
+// match check if suppression rule matches warning found in the Ads.txt file of domain
+func (s Suppression) match(domain string, w *Warning) bool {
+	if len(s.Domain) > 0 && !strings.EqualFold(s.Domain, domain) {
+		return false
+	}
+
+	if s.Pattern == nil && len(s.Code) == 0 {
+		return false
+	}
+	if s.Pattern != nil && !s.Pattern.MatchString(w.Text) {
+		return false
+	}
+	return len(s.Code) == 0 || s.Code == w.Code
+}
+
+// Suppress move warnings matching any of the suppression rules from Warnings to Suppressed collection, and
+// return the number of suppressed warnings
+func (r *Response) Suppress(rules []Suppression) int {
+	if r.Records == nil {
+		return 0
+	}
+
+	var domain string
+	if r.Request != nil {
+		domain = r.Request.Domain.String()
+	}
+
+	warnings := []*Warning{}
+	suppressed := 0
+	for _, w := range r.Warnings {
+		match := false
+		for _, s := range rules {
+			if s.match(domain, w) {
+				match = true
+				break
+			}
+		}
+
+		if match {
+			r.Suppressed = append(r.Suppressed, w)
+			suppressed++
+		} else {
+			warnings = append(warnings, w)
+		}
+	}
+	r.Warnings = warnings
+
+	return suppressed
+}
+
+// SuppressHandler wrap Handler so warnings matching any of the suppression rules are suppressed before the
+// response is handled
+func SuppressHandler(h Handler, rules []Suppression) Handler {
+	return HandlerFunc(func(req *Request, res *Response, err error) {
+		if res != nil {
+			res.Suppress(rules)
+		}
+		h.Handle(req, res, err)
+	})
+}
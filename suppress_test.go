@@ -0,0 +1,58 @@
+package adstxt
+
+import (
+	"regexp"
+	"testing"
+)
+
+// TestSuppress test warnings matching suppression rules are suppressed
+func TestSuppress(t *testing.T) {
+	body := "greenadexchange.com,XF7342,\nnot a valid line\ngreenadexchange.com,XF7342,DIRECT,not-alphanumeric"
+
+	rules := []Suppression{
+		{Domain: "example.com", Code: WarnMissingAccountType},
+		{Domain: "other.com", Code: WarnUnparsableLine},
+		{Pattern: regexp.MustCompile("not-alphanumeric$")},
+	}
+
+	rec, err := ParseBody([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := &Response{Request: &Request{Domain: "example.com"}, Records: rec}
+
+	if n := res.Suppress(rules); n != 2 {
+		t.Errorf("Expected 2 suppressed warnings and not [%d]", n)
+	}
+
+	if len(res.Warnings) != 1 || res.Warnings[0].Code != WarnUnparsableLine {
+		t.Errorf("Expected single unsuppressed [%s] warning", WarnUnparsableLine)
+	}
+
+	if len(res.Suppressed) != 2 {
+		t.Errorf("Expected suppressed warnings to be kept, found [%d]", len(res.Suppressed))
+	}
+}
+
+// TestSuppressAllFields test rule with both pattern and code suppresses only warnings matching both
+func TestSuppressAllFields(t *testing.T) {
+	body := "greenadexchange.com,XF7342,\nappnexus.com,1234,\nnot-alphanumeric line"
+
+	rules := []Suppression{
+		{Pattern: regexp.MustCompile("^greenadexchange.com,"), Code: WarnMissingAccountType},
+		{Pattern: regexp.MustCompile("not-alphanumeric"), Code: WarnMissingAccountType},
+	}
+
+	rec, err := ParseBody([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := &Response{Request: &Request{Domain: "example.com"}, Records: rec}
+
+	if n := res.Suppress(rules); n != 1 || res.Suppressed[0].Text != "greenadexchange.com,XF7342," {
+		t.Errorf("Expected only warning matching both pattern and code to be suppressed and not [%d]", n)
+	}
+	if len(res.Warnings) != 2 {
+		t.Errorf("Expected [2] unsuppressed warnings and not [%d]", len(res.Warnings))
+	}
+}
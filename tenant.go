@@ -0,0 +1,198 @@
+package adstxt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// errHealthStore store of tenant view doesn't implement HealthStore
+const errHealthStore = "store [%T] doesn't support domain health ledger"
+
+// tenantSeparator separate tenant from domain in tenant scoped keys (domain names can't include it)
+const tenantSeparator = "/"
+
+// tenantKey return tenant scoped key of domain, the domain itself for the default (empty) tenant
+func tenantKey(tenant string, domain string) string {
+	if len(tenant) == 0 {
+		return domain
+	}
+	return tenant + tenantSeparator + domain
+}
+
+// Tenants crawl Ads.txt files on behalf of multiple tenants from single process. Each tenant is crawled using its
+// own crawler (transport, connection budget, idle connections and gauges), so tenants don't compete on each other
+// limits. Requests are routed by their Tenant tag
+type Tenants struct {
+	options func(tenant string) []Option
+
+	lock     sync.Mutex
+	crawlers map[string]*Crawler
+}
+
+// NewTenants create new tenants crawler pool, tenant crawlers are created on first use with the specified options.
+// Values passed to options are shared by all tenant crawlers: politeness state (WithPoliteness), auto-tuning limit
+// (WithAutoTuning), cookie jar (WithCookieJar), dead-letter sink, health ledger and differential store are not
+// partitioned by tenant (ledger entries and snapshots are still keyed by tenant). Use NewTenantsFunc for tenant
+// crawlers of their own state
+func NewTenants(opts ...Option) *Tenants {
+	return NewTenantsFunc(func(string) []Option { return opts })
+}
+
+// NewTenantsFunc create new tenants crawler pool, crawler of each tenant is created on first use with the options
+// returned by options for the tenant. Stateful option values should be created for each tenant, e.g.
+// WithPoliteness(NewPoliteness()) so 429 responses to one tenant don't back the host off for other tenants
+func NewTenantsFunc(options func(tenant string) []Option) *Tenants {
+	return &Tenants{options: options, crawlers: make(map[string]*Crawler)}
+}
+
+// Crawler return crawler of tenant
+func (t *Tenants) Crawler(tenant string) *Crawler {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	c, ok := t.crawlers[tenant]
+	if !ok {
+		c = NewCrawler(t.options(tenant)...)
+		t.crawlers[tenant] = c
+	}
+	return c
+}
+
+// Stats return gauges of each tenant crawler, by tenant (tenants without crawler yet are not listed)
+func (t *Tenants) Stats() map[string]CrawlerStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	stats := make(map[string]CrawlerStats, len(t.crawlers))
+	for tenant, c := range t.crawlers {
+		stats[tenant] = c.Stats()
+	}
+	return stats
+}
+
+// Get crawl and parse Ads.txt file using request tenant crawler
+func (t *Tenants) Get(req *Request) (*Response, error) {
+	return t.Crawler(req.Tenant).Get(req)
+}
+
+// GetMultiple crawl and parse multiple Ads.txt files, requests of each tenant are crawled concurrently using the
+// tenant crawler
+func (t *Tenants) GetMultiple(req []*Request, h Handler) {
+	byTenant := make(map[string][]*Request)
+	for _, r := range req {
+		byTenant[r.Tenant] = append(byTenant[r.Tenant], r)
+	}
+
+	var wg sync.WaitGroup
+	for tenant, requests := range byTenant {
+		wg.Add(1)
+		go func(c *Crawler, requests []*Request) {
+			defer wg.Done()
+			c.GetMultiple(requests, h)
+		}(t.Crawler(tenant), requests)
+	}
+	wg.Wait()
+}
+
+// CloseIdleConnections close idle connections of all tenant crawlers
+func (t *Tenants) CloseIdleConnections() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, c := range t.crawlers {
+		c.CloseIdleConnections()
+	}
+}
+
+// TenantStore view of store scoped to single tenant: snapshots are put under the tenant, and only the tenant
+// domains are listed
+type TenantStore struct {
+	Store  Store  // Store underlying store shared by all tenants
+	Tenant string // Tenant scope of the view
+}
+
+// NewTenantStore create view of store scoped to tenant
+func NewTenantStore(s Store, tenant string) *TenantStore {
+	return &TenantStore{Store: s, Tenant: tenant}
+}
+
+// Put add snapshot to store under the tenant
+func (t *TenantStore) Put(s *Snapshot) error {
+	if s.Tenant != t.Tenant {
+		scoped := *s
+		scoped.Tenant = t.Tenant
+		s = &scoped
+	}
+	return t.Store.Put(s)
+}
+
+// Snapshots return tenant domain snapshots, oldest first
+func (t *TenantStore) Snapshots(domain string) ([]*Snapshot, error) {
+	return t.Store.Snapshots(tenantKey(t.Tenant, domain))
+}
+
+// Domains return sorted list of tenant domains
+func (t *TenantStore) Domains() ([]string, error) {
+	keys, err := t.Store.Domains()
+	if err != nil {
+		return nil, err
+	}
+	return t.scope(keys), nil
+}
+
+// scope return sorted list of the tenant domains of store keys
+func (t *TenantStore) scope(keys []string) []string {
+	domains := []string{}
+	for _, k := range keys {
+		i := strings.LastIndex(k, tenantSeparator)
+		if i < 0 && len(t.Tenant) == 0 {
+			domains = append(domains, k)
+		}
+		if i >= 0 && k[:i] == t.Tenant {
+			domains = append(domains, k[i+1:])
+		}
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// Replace all tenant domain snapshots
+func (t *TenantStore) Replace(domain string, snapshots []*Snapshot) error {
+	return t.Store.Replace(tenantKey(t.Tenant, domain), snapshots)
+}
+
+// Health return tenant domain health ledger entry
+func (t *TenantStore) Health(domain string) (*DomainHealth, error) {
+	s, ok := t.Store.(HealthStore)
+	if !ok {
+		return nil, fmt.Errorf(errHealthStore, t.Store)
+	}
+	return s.Health(tenantKey(t.Tenant, domain))
+}
+
+// UpdateHealth atomically update tenant domain health ledger entry
+func (t *TenantStore) UpdateHealth(domain string, update func(h *DomainHealth)) error {
+	s, ok := t.Store.(HealthStore)
+	if !ok {
+		return fmt.Errorf(errHealthStore, t.Store)
+	}
+	return s.UpdateHealth(tenantKey(t.Tenant, domain), func(h *DomainHealth) {
+		update(h)
+		h.Tenant = t.Tenant
+	})
+}
+
+// HealthDomains return sorted list of tenant domains with ledger entry
+func (t *TenantStore) HealthDomains() ([]string, error) {
+	s, ok := t.Store.(HealthStore)
+	if !ok {
+		return nil, fmt.Errorf(errHealthStore, t.Store)
+	}
+	keys, err := s.HealthDomains()
+	if err != nil {
+		return nil, err
+	}
+	return t.scope(keys), nil
+}
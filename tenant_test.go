@@ -0,0 +1,156 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestTenantStore test tenant store views don't see each other snapshots
+func TestTenantStore(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir(), JSONCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+	}
+
+	now := time.Date(2044, 11, 5, 8, 49, 37, 0, time.UTC)
+	for name, s := range stores {
+		for _, tenant := range []string{"", "acme", "globex"} {
+			rec, _ := ParseBody([]byte("greenadexchange.com,XF7342,DIRECT"))
+			res := &Response{Request: &Request{Domain: "example.com", URL: "http://example.com/ads.txt"}, Records: rec}
+			if err := NewTenantStore(s, tenant).Put(NewSnapshot(res, now)); err != nil {
+				t.Fatalf("[%s] %s", name, err)
+			}
+		}
+
+		acme := NewTenantStore(s, "acme")
+		snapshots, _ := acme.Snapshots("example.com")
+		if len(snapshots) != 1 || snapshots[0].Tenant != "acme" {
+			t.Errorf("[%s] Expected single acme snapshot and not %v", name, snapshots)
+		}
+
+		for _, tenant := range []string{"", "acme", "globex"} {
+			if domains, _ := NewTenantStore(s, tenant).Domains(); len(domains) != 1 || domains[0] != "example.com" {
+				t.Errorf("[%s] Expected tenant [%s] domains to be [example.com] and not %v", name, tenant, domains)
+			}
+		}
+
+		acme.Replace("example.com", nil)
+		if snapshots, _ := NewTenantStore(s, "globex").Snapshots("example.com"); len(snapshots) != 1 {
+			t.Errorf("[%s] Expected globex snapshot to be kept when acme domain is deleted", name)
+		}
+	}
+}
+
+// TestTenantCache test cache entries are partitioned by tenant
+func TestTenantCache(t *testing.T) {
+	now := time.Now()
+	fetched := map[string]int{}
+
+	c := NewCache(0, 0)
+	c.now = func() time.Time { return now }
+	c.fetch = func(req *Request) (*Response, error) {
+		fetched[req.Tenant]++
+		return &Response{Request: req, Expires: newExpiration(now.Add(time.Hour), ExpiresSourceHeader, now)}, nil
+	}
+
+	for _, tenant := range []string{"acme", "globex", "acme"} {
+		req, _ := NewRequest("example.com", WithTenant(tenant))
+		if res, _ := c.Get(req); res.Request.Tenant != tenant {
+			t.Errorf("Expected cached response of tenant [%s] and not [%s]", tenant, res.Request.Tenant)
+		}
+	}
+	if fetched["acme"] != 1 || fetched["globex"] != 1 {
+		t.Errorf("Expected single fetch per tenant and not %v", fetched)
+	}
+
+	c.DeleteTenant("acme", "example.com")
+	if _, ok := c.entries[tenantKey("globex", "example.com")]; !ok || len(c.entries) != 1 {
+		t.Errorf("Expected only acme entry to be deleted")
+	}
+}
+
+// TestTenants test requests are routed to tenant crawlers
+func TestTenants(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	tenants := NewTenants(WithConnectionBudget(BulkBudget))
+	defer tenants.CloseIdleConnections()
+
+	if tenants.Crawler("acme") == tenants.Crawler("globex") || tenants.Crawler("acme") != tenants.Crawler("acme") {
+		t.Errorf("Expected single crawler per tenant")
+	}
+
+	var lock sync.Mutex
+	handled := map[string]int{}
+	h := func(req *Request, res *Response, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if err != nil {
+			t.Errorf("[%s] %s", req.Tenant, err)
+			return
+		}
+		handled[req.Tenant]++
+	}
+
+	requests := []*Request{}
+	for _, tenant := range []string{"acme", "globex", "acme"} {
+		req, _ := NewRequest(ts.URL, WithTenant(tenant))
+		requests = append(requests, req)
+	}
+	tenants.GetMultiple(requests, HandlerFunc(h))
+
+	if handled["acme"] != 2 || handled["globex"] != 1 {
+		t.Errorf("Expected all tenant requests to be handled and not %v", handled)
+	}
+}
+
+// TestTenantsFunc test tenant crawlers created with options of their own, and cache fetching through them
+func TestTenantsFunc(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "acme" {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	politeness := map[string]*Politeness{}
+	tenants := NewTenantsFunc(func(tenant string) []Option {
+		politeness[tenant] = NewPoliteness()
+		return []Option{WithPoliteness(politeness[tenant]), func(c *Crawler) { c.UserAgent = tenant }}
+	})
+
+	// acme is throttled, globex is not backed off by acme throttling
+	cache := NewCache(0, 0, WithCacheTenants(tenants))
+	for _, tenant := range []string{"acme", "globex"} {
+		req, _ := NewRequest(ts.URL, WithTenant(tenant))
+		_, err := cache.Get(req)
+		if (err == nil) != (tenant == "globex") {
+			t.Errorf("[%s] Unexpected crawl result [%v]", tenant, err)
+		}
+	}
+	host := ts.Listener.Addr().String()
+	if politeness["acme"].Host(host) == nil || politeness["globex"].Host(host).Throttled != 0 {
+		t.Errorf("Expected politeness state to be partitioned by tenant")
+	}
+
+	if stats := tenants.Stats(); len(stats) != 2 {
+		t.Errorf("Expected gauges of [2] tenant crawlers and not %v", stats)
+	}
+}
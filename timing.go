@@ -0,0 +1,127 @@
+package adstxt
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing duration breakdown of single HTTP request of Ads.txt fetch (see Response.Timings). Phases which didn't
+// happen (e.g. DNS lookup and connect on reused connection, TLS handshake over http://) are 0
+type Timing struct {
+	URL      string        `json:"url"`      // URL of the request
+	DNS      time.Duration `json:"dns"`      // DNS lookup duration
+	Connect  time.Duration `json:"connect"`  // TCP connect duration
+	TLS      time.Duration `json:"tls"`      // TLS handshake duration
+	TTFB     time.Duration `json:"ttfb"`     // TTFB time from request start until the first response byte
+	Download time.Duration `json:"download"` // Download time from the first response byte until the body was read
+	Total    time.Duration `json:"total"`    // Total time from request start until the body was read
+	Reused   bool          `json:"reused"`   // Reused request was sent over kept alive connection
+}
+
+// requestTimer collect request timing using HTTP client trace. Trace hooks may be called from transport
+// goroutines, so timer state is guarded by lock
+type requestTimer struct {
+	lock      sync.Mutex
+	timing    Timing
+	start     time.Time
+	dnsStart  time.Time
+	dialStart time.Time
+	tlsStart  time.Time
+	firstByte time.Time
+	done      bool
+	result    *Timing
+}
+
+// traceTiming set client trace collecting timing of HTTP request, return the request and the timer
+func traceTiming(req *http.Request) (*http.Request, *requestTimer) {
+	t := &requestTimer{start: time.Now(), timing: Timing{URL: req.URL.String()}, result: &Timing{URL: req.URL.String()}}
+	since := func(from time.Time) time.Duration {
+		if from.IsZero() {
+			return 0
+		}
+		return time.Since(from)
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.set(func() { t.dnsStart = time.Now() }) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.set(func() { t.timing.DNS = since(t.dnsStart) }) },
+		ConnectStart: func(string, string) {
+			t.set(func() {
+				if t.dialStart.IsZero() {
+					t.dialStart = time.Now()
+				}
+			})
+		},
+		ConnectDone:       func(string, string, error) { t.set(func() { t.timing.Connect = since(t.dialStart) }) },
+		TLSHandshakeStart: func() { t.set(func() { t.tlsStart = time.Now() }) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.set(func() { t.timing.TLS = since(t.tlsStart) }) },
+		GotConn:           func(info httptrace.GotConnInfo) { t.set(func() { t.timing.Reused = info.Reused }) },
+		GotFirstResponseByte: func() {
+			t.set(func() {
+				t.firstByte = time.Now()
+				t.timing.TTFB = t.firstByte.Sub(t.start)
+			})
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+// set update timer state holding the lock
+func (t *requestTimer) set(fn func()) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	fn()
+}
+
+// finish complete request timing once the body was read (or closed), later calls are no-op
+func (t *requestTimer) finish() {
+	if t == nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.done {
+		return
+	}
+	t.done = true
+	now := time.Now()
+	if !t.firstByte.IsZero() {
+		t.timing.Download = now.Sub(t.firstByte)
+	}
+	t.timing.Total = now.Sub(t.start)
+	*t.result = t.timing
+}
+
+// timedBody response body completing request timing when it is read or closed
+type timedBody struct {
+	io.ReadCloser
+	timer *requestTimer
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.timer.finish()
+	}
+	return n, err
+}
+
+func (b *timedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.timer.finish()
+	return err
+}
+
+// responseTimer return timer of HTTP response, nil if the response was not timed (e.g. local file)
+func responseTimer(res *http.Response) *requestTimer {
+	if b, ok := res.Body.(*timedBody); ok {
+		return b.timer
+	}
+	return nil
+}
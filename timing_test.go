@@ -0,0 +1,67 @@
+package adstxt
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestTimings test timing breakdown of each HTTP request of Ads.txt fetch, redirects included
+func TestTimings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ads.txt" {
+			http.Redirect(w, r, "/ads-txt", http.StatusFound)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(5 * time.Millisecond)
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	req, _ := NewRequest(ts.URL)
+	res, err := NewCrawler().Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Timings) != 2 {
+		t.Fatalf("Expected timing of [2] requests and not [%d]", len(res.Timings))
+	}
+
+	for i, timing := range res.Timings {
+		if len(timing.URL) == 0 || timing.TTFB <= 0 || timing.Total < timing.TTFB {
+			t.Errorf("[%d] Expected request timing to be set [%+v]", i, timing)
+		}
+	}
+	if redirect := res.Timings[0]; redirect.Connect <= 0 || redirect.Reused {
+		t.Errorf("Expected first request to open new connection [%+v]", redirect)
+	}
+	if timing := res.Timings[1]; timing.TTFB < 5*time.Millisecond || timing.Download < 5*time.Millisecond {
+		t.Errorf("Expected redirected request to time slow response and download [%+v]", timing)
+	}
+}
+
+// TestTimingsTLS test TLS handshake duration is set on https:// fetch
+func TestTimingsTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	route := routeTo(ts, ts.Client().Transport)
+
+	req, _ := NewRequest("https://example.com/ads.txt")
+	res, err := NewCrawler(route).Get(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Timings) != 1 || res.Timings[0].TLS <= 0 {
+		t.Errorf("Expected TLS handshake duration to be set [%+v]", res.Timings)
+	}
+}
@@ -0,0 +1,76 @@
+package adstxt
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// errTransform transformer failed to process Ads.txt response
+const errTransform = "[%s] failed to transform Ads.txt response [%s]: %s"
+
+// The Transformer interface is used to process Ads.txt responses before they are returned to caller (and reported
+// to handlers), e.g. enrich response with GeoIP of origin, add customer IDs or redact fields. Transformer may
+// modify response in place, or return a new one
+type Transformer interface {
+	Transform(*Response) (*Response, error)
+}
+
+// A TransformerFunc is a function signature that implements the Transformer interface
+type TransformerFunc func(*Response) (*Response, error)
+
+// Transform is the Transformer interface implementation for the TransformerFunc type
+func (t TransformerFunc) Transform(res *Response) (*Response, error) {
+	return t(res)
+}
+
+// Transform apply transformers to response in order, each transformer receives the response returned by the
+// previous one. Transformation stops on the first error
+func Transform(res *Response, transformers ...Transformer) (*Response, error) {
+	for _, t := range transformers {
+		transformed, err := t.Transform(res)
+		if err != nil {
+			domain, url := "", ""
+			if res.Request != nil {
+				domain, url = res.Domain.String(), res.URL
+			}
+			return nil, fmt.Errorf(errTransform, domain, url, err.Error())
+		}
+		if transformed != nil {
+			res = transformed
+		}
+	}
+	return res, nil
+}
+
+// Annotate set response annotation (e.g. customer ID or origin country)
+func (r *Response) Annotate(key string, value string) {
+	if r.Annotations == nil {
+		r.Annotations = make(map[string]string)
+	}
+	r.Annotations[key] = value
+}
+
+// StaticAnnotations transformer annotating every response with the same annotations (e.g. customer ID of
+// crawler tenant)
+func StaticAnnotations(annotations map[string]string) Transformer {
+	return TransformerFunc(func(res *Response) (*Response, error) {
+		for k, v := range annotations {
+			res.Annotate(k, v)
+		}
+		return res, nil
+	})
+}
+
+// RedactHeaders transformer removing captured headers from response (see WithCapturedHeaders), e.g. headers
+// carrying origin infrastructure details which shouldn't be stored
+func RedactHeaders(names ...string) Transformer {
+	return TransformerFunc(func(res *Response) (*Response, error) {
+		for _, name := range names {
+			res.Headers.Del(http.CanonicalHeaderKey(name))
+		}
+		if len(res.Headers) == 0 {
+			res.Headers = nil
+		}
+		return res, nil
+	})
+}
@@ -0,0 +1,72 @@
+package adstxt
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestTransformers test crawler transformers are applied in order to responses reported to handlers
+func TestTransformers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Origin", "backend-1")
+		w.Header().Set("Server", "test")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	order := []string{}
+	step := func(name string) Transformer {
+		return TransformerFunc(func(res *Response) (*Response, error) {
+			order = append(order, name)
+			res.Annotate(name, res.Domain.String())
+			return res, nil
+		})
+	}
+
+	c := NewCrawler(
+		WithCapturedHeaders("X-Origin", "Server"),
+		WithTransformers(StaticAnnotations(map[string]string{"customer": "42"}), step("first")),
+		WithTransformers(step("second"), RedactHeaders("x-origin")),
+	)
+
+	var res *Response
+	c.GetMultiple([]*Request{{URL: ts.URL + "/ads.txt", Domain: "127.0.0.1"}}, HandlerFunc(func(req *Request, r *Response, err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = r
+	}))
+
+	if strings.Join(order, ",") != "first,second" {
+		t.Errorf("Expected transformers to be applied in order and not %v", order)
+	}
+	if res.Annotations["customer"] != "42" || res.Annotations["first"] != "127.0.0.1" || res.Annotations["second"] != "127.0.0.1" {
+		t.Errorf("Unexpected response annotations %v", res.Annotations)
+	}
+	if len(res.Headers.Get("X-Origin")) > 0 || res.Headers.Get("Server") != "test" {
+		t.Errorf("Expected only [X-Origin] header to be redacted and not %v", res.Headers)
+	}
+}
+
+// TestTransformError test transformer error fails the request
+func TestTransformError(t *testing.T) {
+	fail := TransformerFunc(func(res *Response) (*Response, error) {
+		return nil, errors.New("geoip lookup failed")
+	})
+	replace := TransformerFunc(func(res *Response) (*Response, error) {
+		return &Response{Request: res.Request, Records: newRecords()}, nil
+	})
+
+	res := &Response{Request: &Request{URL: "https://example.com/ads.txt", Domain: "example.com"}, Records: newRecords()}
+	if r, err := Transform(res, replace); err != nil || r == res {
+		t.Errorf("Expected transformed response to replace the original one [%v]", err)
+	}
+	if _, err := Transform(res, replace, fail); err == nil || !strings.Contains(err.Error(), "geoip lookup failed") {
+		t.Errorf("Expected transformer error and not [%v]", err)
+	}
+}
@@ -0,0 +1,70 @@
+package adstxt
+
+import (
+	"bytes"
+	"sync/atomic"
+	"text/template"
+)
+
+// UserAgentProvider provide the User-Agent string sent with each HTTP request of Ads.txt request (including redirects)
+type UserAgentProvider interface {
+	UserAgent(req *Request) string
+}
+
+// A UserAgentFunc is a function signature that implements the UserAgentProvider interface
+type UserAgentFunc func(*Request) string
+
+// UserAgent is the UserAgentProvider interface implementation for the UserAgentFunc type
+func (f UserAgentFunc) UserAgent(req *Request) string {
+	return f(req)
+}
+
+// WithUserAgentProvider set crawler User-Agent provider (by default crawler UserAgent string is used)
+func WithUserAgentProvider(p UserAgentProvider) Option {
+	return func(c *Crawler) {
+		c.userAgents = p
+	}
+}
+
+// StaticUserAgent return provider which always use the same User-Agent string
+func StaticUserAgent(ua string) UserAgentProvider {
+	return UserAgentFunc(func(*Request) string {
+		return ua
+	})
+}
+
+// RotatingUserAgent return provider which rotate over the list of User-Agent strings (round robin)
+func RotatingUserAgent(agents []string) UserAgentProvider {
+	var next uint64
+	return UserAgentFunc(func(*Request) string {
+		if len(agents) == 0 {
+			return userAgent
+		}
+		n := atomic.AddUint64(&next, 1) - 1
+		return agents[n%uint64(len(agents))]
+	})
+}
+
+// userAgentData holds the values available to User-Agent template
+type userAgentData struct {
+	Version string // Version of the crawler, as specified by the caller
+	Default string // Default crawler User-Agent string
+	Domain  string // Domain root domain of the request
+}
+
+// TemplateUserAgent return provider which execute text/template User-Agent with the crawler version, default
+// User-Agent and request domain, e.g. "MyCrawler/{{.Version}} ({{.Default}})"
+func TemplateUserAgent(tmpl string, version string) (UserAgentProvider, error) {
+	t, err := template.New("user-agent").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	return UserAgentFunc(func(req *Request) string {
+		var b bytes.Buffer
+		if err := t.Execute(&b, userAgentData{Version: version, Default: userAgent, Domain: req.Domain.String()}); err != nil {
+			return userAgent
+		}
+		return b.String()
+	}), nil
+}
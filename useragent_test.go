@@ -0,0 +1,56 @@
+package adstxt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestUserAgentProviders test static, rotating and templated User-Agent providers
+func TestUserAgentProviders(t *testing.T) {
+	req := &Request{Domain: "example.com"}
+
+	if ua := StaticUserAgent("crawler/1.0").UserAgent(req); ua != "crawler/1.0" {
+		t.Errorf("Expected static User-Agent to be [crawler/1.0] and not [%s]", ua)
+	}
+
+	agents := []string{"a", "b", "c"}
+	p := RotatingUserAgent(agents)
+	for i := 0; i < 6; i++ {
+		if ua := p.UserAgent(req); ua != agents[i%3] {
+			t.Errorf("Expected rotating User-Agent #%d to be [%s] and not [%s]", i, agents[i%3], ua)
+		}
+	}
+
+	p, err := TemplateUserAgent("crawler/{{.Version}} (+{{.Domain}})", "2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ua := p.UserAgent(req); ua != "crawler/2.1 (+example.com)" {
+		t.Errorf("Expected templated User-Agent to be [crawler/2.1 (+example.com)] and not [%s]", ua)
+	}
+
+	if _, err := TemplateUserAgent("{{.Version", "2.1"); err == nil {
+		t.Error("Expected error for invalid User-Agent template")
+	}
+}
+
+// TestCrawlerUserAgentProvider test crawler send User-Agent from provider
+func TestCrawlerUserAgentProvider(t *testing.T) {
+	var received string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.UserAgent()
+	}))
+	defer ts.Close()
+
+	req, _ := NewRequest(ts.URL)
+	res, err := NewCrawler(WithUserAgentProvider(StaticUserAgent("crawler/1.0"))).sendRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if received != "crawler/1.0" {
+		t.Errorf("Expected User-Agent header to be [crawler/1.0] and not [%s]", received)
+	}
+}
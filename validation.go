@@ -0,0 +1,92 @@
+package adstxt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationProfile spec version Ads.txt files are validated against: the variables defined by the spec version
+// and its additional rules. Findings of the profile are labeled with the profile name
+type ValidationProfile struct {
+	Name        string   // Name of the profile (spec and version)
+	Variables   []string // Variables variable types defined by the spec version
+	OwnerDomain bool     // OwnerDomain OWNERDOMAIN declaration is expected
+}
+
+// Validation profiles of supported spec versions
+var (
+	// AdsTxt102 ads.txt specification version 1.0.2
+	AdsTxt102 = ValidationProfile{Name: "ads.txt-1.0.2", Variables: []string{varTypeContact, varTypeSubdomain}}
+	// AdsTxt11 ads.txt specification version 1.1, adds INVENTORYPARTNERDOMAIN (1.0.3), OWNERDOMAIN and MANAGERDOMAIN
+	AdsTxt11 = ValidationProfile{
+		Name:        "ads.txt-1.1",
+		Variables:   []string{varTypeContact, varTypeSubdomain, varTypeInventoryPartnerDomain, varTypeOwnerDomain, varTypeManagerDomain},
+		OwnerDomain: true,
+	}
+	// AppAdsTxt10 app-ads.txt specification version 1.0, SUBDOMAIN is not applicable to apps inventory
+	AppAdsTxt10 = ValidationProfile{Name: "app-ads.txt-1.0", Variables: []string{varTypeContact}}
+)
+
+// validation profile warnings
+const (
+	warnVariableNotInProfile = "[%s] variable is not defined by [%s]"
+	warnMissingOwnerDomain   = "OWNERDOMAIN is not declared, it is expected by [%s]"
+)
+
+// Validate Ads.txt records against profile, and return profile findings
+func (p ValidationProfile) Validate(r *Records) []*Warning {
+	warnings := []*Warning{}
+	defined := map[string]bool{}
+	for _, v := range p.Variables {
+		defined[v] = true
+	}
+
+	// variables don't keep their line, find them in file body
+	owner := false
+	for i, l := range r.Body {
+		line := removeComment(l)
+		if strings.Count(line, "=") != 1 || strings.Count(line, ",") >= 2 {
+			continue
+		}
+		t := strings.ToLower(line[:strings.Index(line, "=")])
+		if t == varTypeOwnerDomain {
+			owner = true
+		}
+		if !isVariableType(t) || defined[t] {
+			continue
+		}
+		warnings = append(warnings, &Warning{
+			Index:   i + 1,
+			Text:    l,
+			Code:    WarnVariableNotInProfile,
+			Level:   LowSevirity,
+			Message: fmt.Sprintf(warnVariableNotInProfile, strings.ToUpper(t), p.Name),
+			Profile: p.Name,
+		})
+	}
+
+	if p.OwnerDomain && !owner && len(r.DataRecords) > 0 {
+		warnings = append(warnings, &Warning{
+			Code:    WarnMissingOwnerDomain,
+			Level:   LowSevirity,
+			Message: fmt.Sprintf(warnMissingOwnerDomain, p.Name),
+			Profile: p.Name,
+		})
+	}
+	return warnings
+}
+
+// validate records against profile, adding profile findings to records warnings
+func (r *Records) validate(p ValidationProfile) {
+	r.Warnings = append(r.Warnings, p.Validate(r)...)
+	r.Profile = p.Name
+}
+
+// isVariableType check if t is a supported variable type
+func isVariableType(t string) bool {
+	switch t {
+	case varTypeSubdomain, varTypeContact, varTypeInventoryPartnerDomain, varTypeOwnerDomain, varTypeManagerDomain:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package adstxt
+
+import (
+	"testing"
+)
+
+// TestValidationProfiles test spec version profiles toggle defined variables and rules
+func TestValidationProfiles(t *testing.T) {
+	body := "greenadexchange.com,XF7342,DIRECT\nsubdomain=news.example.com\ninventorypartnerdomain=partner.com # ctv\ncontact=ads@example.com"
+
+	// expected profile findings codes, by line index (0 for file level findings)
+	expected := map[string]map[int]string{
+		AdsTxt102.Name:   {3: WarnVariableNotInProfile},
+		AdsTxt11.Name:    {0: WarnMissingOwnerDomain},
+		AppAdsTxt10.Name: {2: WarnVariableNotInProfile, 3: WarnVariableNotInProfile},
+	}
+
+	for _, p := range []ValidationProfile{AdsTxt102, AdsTxt11, AppAdsTxt10} {
+		records, err := ParseBody([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		records.validate(p)
+
+		findings := expected[p.Name]
+		if records.Profile != p.Name || len(records.Warnings) != len(findings) {
+			t.Errorf("[%s] Expected [%d] profile findings and not [%d]", p.Name, len(findings), len(records.Warnings))
+			continue
+		}
+		for _, w := range records.Warnings {
+			if findings[w.Index] != w.Code || w.Profile != p.Name {
+				t.Errorf("[%s] Unexpected finding [%s] on line [%d] of profile [%s]", p.Name, w.Code, w.Index, w.Profile)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package adstxt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WARC record types written by WARCWriter (ISO 28500, WARC/1.1)
+const (
+	warcVersion      = "WARC/1.1"
+	warcTypeInfo     = "warcinfo"
+	warcTypeRequest  = "request"
+	warcTypeResponse = "response"
+	warcTypeMetadata = "metadata"
+)
+
+// WARCWriter write fetched HTTP exchanges as standard WARC records (request, response and metadata), so crawls can
+// be archived and consumed by existing web archive tooling. WARCWriter is safe for concurrent use
+type WARCWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+	gzip bool // write each record as separate gzip member (see NewGzipWARCWriter)
+}
+
+// NewWARCWriter create new WARC writer writing records to w
+func NewWARCWriter(w io.Writer) *WARCWriter {
+	return &WARCWriter{w: w}
+}
+
+// WithWARC write all HTTP exchanges of the crawler as WARC records using WARC writer. Records are reconstructed from
+// the parsed exchange, not the raw bytes sent and received: headers are re-serialized in canonical form, response
+// body is decoded (e.g. chunked transfer and gzip content encoding) and cut at MaxFileSize+1 bytes, and credential
+// headers of the request (Authorization, Cookie) are redacted
+func WithWARC(w *WARCWriter) Option {
+	return func(c *Crawler) {
+		c.wrappers = append(c.wrappers, func(rt http.RoundTripper) http.RoundTripper {
+			return &warcRecorder{w: w, next: rt, software: c.UserAgent}
+		})
+	}
+}
+
+// WriteInfo write warcinfo record describing the crawl (e.g. software, operator), fields are written in order
+func (w *WARCWriter) WriteInfo(fields [][2]string) error {
+	var block bytes.Buffer
+	for _, f := range fields {
+		fmt.Fprintf(&block, "%s: %s\r\n", f[0], f[1])
+	}
+
+	return w.writeRecord(warcTypeInfo, "", time.Now(), "application/warc-fields", nil, block.Bytes())
+}
+
+// WriteExchange write single HTTP exchange as request, response and metadata WARC records
+func (w *WARCWriter) WriteExchange(targetURI string, date time.Time, request []byte, response []byte, metadata [][2]string) error {
+	responseID := warcRecordID()
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err := w.write(warcTypeResponse, responseID, targetURI, date, "application/http; msgtype=response", nil, response); err != nil {
+		return err
+	}
+
+	concurrent := [][2]string{{"WARC-Concurrent-To", responseID}}
+	if err := w.write(warcTypeRequest, warcRecordID(), targetURI, date, "application/http; msgtype=request", concurrent, request); err != nil {
+		return err
+	}
+
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	var block bytes.Buffer
+	for _, f := range metadata {
+		fmt.Fprintf(&block, "%s: %s\r\n", f[0], f[1])
+	}
+	refers := [][2]string{{"WARC-Concurrent-To", responseID}}
+	return w.write(warcTypeMetadata, warcRecordID(), targetURI, date, "application/warc-fields", refers, block.Bytes())
+}
+
+// writeRecord write single WARC record holding the writer lock
+func (w *WARCWriter) writeRecord(recordType string, targetURI string, date time.Time, contentType string, headers [][2]string, block []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.write(recordType, warcRecordID(), targetURI, date, contentType, headers, block)
+}
+
+// write single WARC record, caller must hold the writer lock
+func (w *WARCWriter) write(recordType string, id string, targetURI string, date time.Time, contentType string, headers [][2]string, block []byte) error {
+	digest := sha1.Sum(block)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\r\n", warcVersion)
+	fmt.Fprintf(&b, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(&b, "WARC-Record-ID: %s\r\n", id)
+	fmt.Fprintf(&b, "WARC-Date: %s\r\n", date.UTC().Format(time.RFC3339))
+	if len(targetURI) > 0 {
+		fmt.Fprintf(&b, "WARC-Target-URI: %s\r\n", targetURI)
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&b, "%s: %s\r\n", h[0], h[1])
+	}
+	fmt.Fprintf(&b, "WARC-Block-Digest: sha1:%s\r\n", base32.StdEncoding.EncodeToString(digest[:]))
+	fmt.Fprintf(&b, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&b, "Content-Length: %d\r\n\r\n", len(block))
+	b.Write(block)
+	b.WriteString("\r\n\r\n")
+
+	record := b.Bytes()
+	if w.gzip {
+		compressed, err := Compress(record)
+		if err != nil {
+			return err
+		}
+		record = compressed
+	}
+	_, err := w.w.Write(record)
+	return err
+}
+
+// warcRecordID return new unique WARC record ID (random UUID URN)
+func warcRecordID() string {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// warcRecorder HTTP transport which write HTTP exchanges as WARC records
+type warcRecorder struct {
+	w        *WARCWriter
+	next     http.RoundTripper
+	software string
+}
+
+// RoundTrip send request using next transport and write the exchange as WARC records (see WithWARC)
+func (r *warcRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	dumpReq, res, dumpRes, err := dumpExchange(r.next, req)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := [][2]string{
+		{"software", r.software},
+		{"fetchTimeMs", fmt.Sprintf("%d", time.Since(start)/time.Millisecond)},
+	}
+
+	if err := r.w.WriteExchange(req.URL.String(), start, dumpReq, dumpRes, metadata); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	return res, nil
+}
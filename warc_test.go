@@ -0,0 +1,51 @@
+package adstxt
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestWARC test crawler HTTP exchanges are written as WARC records
+func TestWARC(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "greenadexchange.com,XF7342,DIRECT")
+	}))
+	defer ts.Close()
+
+	var b bytes.Buffer
+	w := NewWARCWriter(&b)
+	w.WriteInfo([][2]string{{"software", userAgent}})
+
+	req, _ := NewRequest(ts.URL, WithBasicAuth("user", "secret"))
+	if _, err := NewCrawler(WithWARC(w)).Get(req); err != nil {
+		t.Fatal(err)
+	}
+
+	warc := b.String()
+	for _, recordType := range []string{"warcinfo", "response", "request", "metadata"} {
+		if !strings.Contains(warc, "WARC-Type: "+recordType+"\r\n") {
+			t.Errorf("Expected WARC output to include [%s] record", recordType)
+		}
+	}
+
+	if n := strings.Count(warc, "WARC/1.1\r\n"); n != 4 {
+		t.Errorf("Expected 4 WARC records and not [%d]", n)
+	}
+
+	if !strings.Contains(warc, "WARC-Target-URI: "+req.URL+"\r\n") {
+		t.Errorf("Expected WARC records target URI to be [%s]", req.URL)
+	}
+
+	if !strings.Contains(warc, "greenadexchange.com,XF7342,DIRECT") {
+		t.Error("Expected WARC response record to include response body")
+	}
+
+	if !strings.Contains(warc, "Authorization: "+redactedValue+"\r\n") {
+		t.Error("Expected WARC request record Authorization header to be redacted")
+	}
+}
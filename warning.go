@@ -2,12 +2,73 @@ package adstxt
 
 // Warning represent failure to parse Ads.txt line according to official ads.txt spec
 type Warning struct {
-	Index   int      `json:"index"` // Index of the line in the Ads.txt file in which warning was found
+	Index   int      `json:"index"` // Index of the line in the Ads.txt file in which warning was found (0 for HTTP response warnings)
 	Text    string   `json:"txt"`   // Text of the line in the Ads.txt file in which warning was found
 	Message string   `json:"msg"`   // Warning reason
 	Level   Sevirity `json:"level"` // Sevirity level of parse warning
+	Code    string   `json:"code"`  // Code stable identifier of the warning reason (see Warn* codes)
+
+	Suggestion string `json:"suggestion,omitempty"` // Suggestion normalized or corrected value, when one can be offered
+	Profile    string `json:"profile,omitempty"`    // Profile name of the validation profile of the finding (see ValidationProfile)
 }
 
+// Warning codes: stable identifiers of warning reasons, which unlike warning messages can be safely matched on
+const (
+	// WarnInvalidFieldsCount data record has wrong number of fields
+	WarnInvalidFieldsCount = "invalid-fields-count"
+	// WarnMissingAdSystemDomain data record is missing the advertising system domain
+	WarnMissingAdSystemDomain = "missing-adsystem-domain"
+	// WarnInvalidAdSystemDomain data record advertising system domain is not a valid domain name
+	WarnInvalidAdSystemDomain = "invalid-adsystem-domain"
+	// WarnUnverifiedAdSystemDomain data record advertising system is not known or not in its canonical form
+	WarnUnverifiedAdSystemDomain = "unverified-adsystem-domain"
+	// WarnMissingAccountID data record is missing the publisher account ID
+	WarnMissingAccountID = "missing-account-id"
+	// WarnMissingAccountType data record is missing the account type
+	WarnMissingAccountType = "missing-account-type"
+	// WarnInvalidAccountType data record account type is not DIRECT or RESELLER
+	WarnInvalidAccountType = "invalid-account-type"
+	// WarnInvalidCertAuthorityID data record certification authority ID is not alphanumeric
+	WarnInvalidCertAuthorityID = "invalid-cert-authority-id"
+	// WarnInvalidVariableType variable record type is not supported
+	WarnInvalidVariableType = "invalid-variable-type"
+	// WarnMalformedVariable variable is declared with wrong separator (e.g. colon instead of "="), suggestion holds
+	// the corrected declaration
+	WarnMalformedVariable = "malformed-variable"
+	// WarnUnparsableLine line is neither a data record nor a variable record
+	WarnUnparsableLine = "unparsable-line"
+	// WarnDuplicateHeader HTTP response include multiple values of single value header
+	WarnDuplicateHeader = "duplicate-header"
+	// WarnLenientContentType Ads.txt file with wrong content type was accepted in lenient mode
+	WarnLenientContentType = "lenient-content-type"
+	// WarnRedirectPathVariant redirect to non canonical ads.txt path (letter case, trailing slash, query) was followed
+	WarnRedirectPathVariant = "redirect-path-variant"
+	// WarnRedirectWellKnown redirect to another well-known file (e.g. app-ads.txt) was followed
+	WarnRedirectWellKnown = "redirect-well-known"
+	// WarnSchainUnauthorized supply chain node seller account is not declared in the publisher Ads.txt file
+	WarnSchainUnauthorized = "schain-unauthorized"
+	// WarnSchainMissingSellers supply chain node ad system sellers.json file is not available
+	WarnSchainMissingSellers = "schain-missing-sellers"
+	// WarnSchainUnknownSeller supply chain node seller ID is not declared in the ad system sellers.json file
+	WarnSchainUnknownSeller = "schain-unknown-seller"
+	// WarnSchainRelationship supply chain node relationship (DIRECT or RESELLER) is inconsistent with seller type
+	WarnSchainRelationship = "schain-relationship"
+	// WarnSchainDomain supply chain node domain is different from the sellers.json seller domain
+	WarnSchainDomain = "schain-domain"
+	// WarnAccountIDInvisibleChars publisher account ID contains invisible (zero-width, format or whitespace) characters
+	WarnAccountIDInvisibleChars = "account-id-invisible-characters"
+	// WarnAccountIDCase publisher account ID is declared for the same ad system in different letter case
+	WarnAccountIDCase = "account-id-case"
+	// WarnLineTooLong line is longer than MaxLineLength, it was truncated and not parsed
+	WarnLineTooLong = "line-too-long"
+	// WarnFieldTooLong record field is longer than its hard limit (see Max* limits)
+	WarnFieldTooLong = "field-too-long"
+	// WarnVariableNotInProfile variable is not defined by the spec version of the validation profile
+	WarnVariableNotInProfile = "variable-not-in-profile"
+	// WarnMissingOwnerDomain OWNERDOMAIN variable is expected by the validation profile, but is not declared
+	WarnMissingOwnerDomain = "missing-owner-domain"
+)
+
 // Sevirity of parse warning (low for moderate warning, high indicates potential erro)
 type Sevirity int
 